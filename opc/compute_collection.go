@@ -0,0 +1,203 @@
+package opc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/client"
+	"github.com/hashicorp/go-oracle-terraform/opc"
+)
+
+const computeCollectionContentType = "application/oracle-compute-v3+json"
+
+// computeCollectionClient lists the objects of a Compute Classic container.
+// compute.ResourceClient only addresses single, named objects, so the
+// collection GETs used by the plural data sources are made with this client.
+// It's built on the HTTP client of the compute.Client, and shares its
+// session: requests are sent without a cookie, and sessionTransport sends
+// them with the session the compute.Client started.
+type computeCollectionClient struct {
+	client *client.Client
+}
+
+func newComputeCollectionClient(c *opc.Config) (*computeCollectionClient, error) {
+	apiClient, err := client.NewClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return &computeCollectionClient{client: apiClient}, nil
+}
+
+// userName returns the fully qualified name of the authenticated user,
+// e.g. /Compute-acme/jdoe@example.com
+func (c *computeCollectionClient) userName() string {
+	return fmt.Sprintf("/Compute-%s/%s", *c.client.IdentityDomain, *c.client.UserName)
+}
+
+// unqualify strips the user's container from an object name, matching the
+// names returned by the compute.*Client Get calls.
+func (c *computeCollectionClient) unqualify(name string) string {
+	return strings.TrimPrefix(name, c.userName()+"/")
+}
+
+// qualify prefixes an object name with the user's container, as the
+// compute.*Client Create calls do. Names which are already qualified, and
+// the /oracle/public objects, are returned as is.
+func (c *computeCollectionClient) qualify(name string) string {
+	if name == "" || strings.HasPrefix(name, "/oracle") || strings.HasPrefix(name, "/Compute-") {
+		return name
+	}
+	return fmt.Sprintf("%s/%s", c.userName(), name)
+}
+
+// qualifyListName qualifies the name of a security rule source or
+// destination, e.g. seclist:web becomes seclist:/Compute-acme/jdoe/web
+func (c *computeCollectionClient) qualifyListName(name string) string {
+	parts := strings.SplitN(name, ":", 2)
+	if len(parts) != 2 {
+		return name
+	}
+	return fmt.Sprintf("%s:%s", parts[0], c.qualify(parts[1]))
+}
+
+// unqualifyListName reverses qualifyListName.
+func (c *computeCollectionClient) unqualifyListName(name string) string {
+	parts := strings.SplitN(name, ":", 2)
+	if len(parts) != 2 {
+		return name
+	}
+	return fmt.Sprintf("%s:%s", parts[0], c.unqualify(parts[1]))
+}
+
+// list decodes the `result` array of the user's container under root, e.g.
+// /storage/volume, into results, which must be a pointer to a slice of the
+// matching compute info type.
+func (c *computeCollectionClient) list(root string, results interface{}) error {
+	path := fmt.Sprintf("%s%s/", root, c.userName())
+	collection := struct {
+		Result interface{} `json:"result"`
+	}{results}
+	return c.do("GET", path, nil, &collection)
+}
+
+// do sends body to path as is and decodes the response into result, for the
+// requests whose bodies the compute.*Client types would rewrite.
+func (c *computeCollectionClient) do(method, path string, body, result interface{}) error {
+	resp, err := c.executeRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("Error decoding %s: %s", path, err)
+	}
+	return nil
+}
+
+func (c *computeCollectionClient) executeRequest(method, path string, body interface{}) (*http.Response, error) {
+	reqBody, err := c.client.MarshallRequestBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.client.BuildRequestBody(method, path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", computeCollectionContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", computeCollectionContentType)
+	}
+
+	c.client.DebugLogString(fmt.Sprintf("HTTP %s Req (%s)", method, path))
+	return c.client.ExecuteRequest(req)
+}
+
+// The compute clients authenticate every request with the session cookie,
+// sessionTransport sends the requests of computeCollectionClient, which have
+// none, with the session of the compute.Client.
+
+func (c *computeCollectionClient) credential(req *http.Request) (string, bool) {
+	return req.Header.Get("Cookie"), !strings.HasSuffix(req.URL.Path, "/authenticate/")
+}
+
+func (c *computeCollectionClient) setCredential(req *http.Request, credential string) {
+	req.Header.Set("Cookie", credential)
+}
+
+func (c *computeCollectionClient) startedCredential(resp *http.Response) string {
+	if resp.Request == nil || !strings.HasSuffix(resp.Request.URL.Path, "/authenticate/") || resp.StatusCode >= 300 || len(resp.Cookies()) == 0 {
+		return ""
+	}
+	cookie := resp.Cookies()[0]
+	return fmt.Sprintf("%s=%s", cookie.Name, cookie.Value)
+}
+
+// authenticate starts a new session, when the session of the compute.Client
+// has expired.
+func (c *computeCollectionClient) authenticate() (string, error) {
+	input := map[string]string{
+		"user":     c.userName(),
+		"password": *c.client.Password,
+	}
+	resp, err := c.executeRequest("POST", "/authenticate/", input)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	credential := c.startedCredential(resp)
+	if credential == "" {
+		return "", fmt.Errorf("No authentication cookie found in response %#v", resp)
+	}
+	return credential, nil
+}
+
+// collectionFilter holds the name_prefix, tags and state arguments shared by
+// the plural compute data sources.
+type collectionFilter struct {
+	namePrefix string
+	tags       []string
+	state      string
+}
+
+// matches returns whether an object satisfies every filter which is set. An
+// object matches the tags filter when it has all of the given tags.
+func (f collectionFilter) matches(name string, tags []string, state string) bool {
+	if !strings.HasPrefix(name, f.namePrefix) {
+		return false
+	}
+	if f.state != "" && !strings.EqualFold(f.state, state) {
+		return false
+	}
+	for _, want := range f.tags {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sizeInGigaBytes converts the size in bytes returned by the storage volume
+// API to the size in GB used by the provider.
+func sizeInGigaBytes(size string) (int, error) {
+	sizeInBytes, err := strconv.Atoi(size)
+	if err != nil {
+		return 0, err
+	}
+	return sizeInBytes / 1024 / 1024 / 1024, nil
+}
@@ -1,13 +1,13 @@
 package opc
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"strings"
 
-	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/go-oracle-terraform/lbaas"
 	"github.com/hashicorp/go-oracle-terraform/opc"
@@ -27,13 +27,41 @@ type Config struct {
 	StorageEndpoint  string
 	StorageServiceID string
 	LBaaSEndpoint    string
+	Retry            RetryPolicy
+	// RequestsPerSecond and MaxConcurrentRequests limit the requests to
+	// each API, zero is unlimited
+	RequestsPerSecond     float64
+	MaxConcurrentRequests int
+	// CACertificate or CACertificateFile hold PEM encoded CA certificates to
+	// trust, ClientCertificate and ClientKey a PEM encoded key pair
+	CACertificate     string
+	CACertificateFile string
+	ClientCertificate string
+	ClientKey         string
+	// ProxyURL replaces the proxy from the environment, NoProxy is a comma
+	// separated list of hosts which bypass either
+	ProxyURL string
+	NoProxy  string
+	// StopContext is cancelled when Terraform is stopped, which cancels any
+	// requests in flight and waits in progress
+	StopContext context.Context
+	// DefaultTags are added to the tags of every resource, and the tags with
+	// one of the IgnoreTagsPrefixes aren't managed by Terraform
+	DefaultTags        []string
+	IgnoreTagsPrefixes []string
 }
 
 // Client holder for the OPC (OCI Classic) API Clients
 type Client struct {
-	computeClient *compute.Client
-	storageClient *storage.Client
-	lbaasClient   *lbaas.Client
+	computeClient           *compute.Client
+	computeCollectionClient *computeCollectionClient
+	storageClient           *storage.Client
+	storageObjectClient     *storageObjectClient
+	lbaasClient             *lbaas.Client
+	lbaasRequestClient      *lbaasRequestClient
+	stopContext             context.Context
+	defaultTags             []string
+	ignoreTagsPrefixes      []string
 }
 
 // Client gets the OPC (OCI Classic) API Clients
@@ -41,11 +69,15 @@ func (c *Config) Client() (*Client, error) {
 
 	userAgentString := fmt.Sprintf("HashiCorp-Terraform-v%s", terraform.VersionString())
 
+	// Requests are retried by retryTransport, which follows the provider's
+	// retry policy, so the clients make a single attempt
+	attempts := 1
+
 	config := opc.Config{
 		IdentityDomain: &c.IdentityDomain,
 		Username:       &c.User,
 		Password:       &c.Password,
-		MaxRetries:     &c.MaxRetries,
+		MaxRetries:     &attempts,
 		UserAgent:      &userAgentString,
 	}
 
@@ -54,26 +86,40 @@ func (c *Config) Client() (*Client, error) {
 		config.Logger = opcLogger{}
 	}
 
-	// Setup HTTP Client based on insecure
-	httpClient := cleanhttp.DefaultClient()
-	if c.Insecure {
-		transport := cleanhttp.DefaultTransport()
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
-		httpClient.Transport = transport
+	transport, err := c.newHTTPTransport()
+	if err != nil {
+		return nil, err
 	}
 
-	config.HTTPClient = httpClient
-
-	client := &Client{}
+	stopContext := c.StopContext
+	if stopContext == nil {
+		stopContext = context.Background()
+	}
+	client := &Client{
+		stopContext:        stopContext,
+		defaultTags:        c.DefaultTags,
+		ignoreTagsPrefixes: c.IgnoreTagsPrefixes,
+	}
 
+	// Each API has its own HTTP client, whose sessionTransport shares a
+	// single session between the clients of the API, and authenticates
+	// requests which fail with 401 Unauthorized again. The provider's own
+	// client for the API starts new sessions for the transport, and is
+	// created first so that the library client's session is adopted.
 	if c.Endpoint != "" {
 		computeEndpoint, err := url.ParseRequestURI(c.Endpoint)
 		if err != nil {
 			return nil, fmt.Errorf("Invalid Compute Endpoint URI: %s", err)
 		}
 		config.APIEndpoint = computeEndpoint
+		httpClient := &http.Client{Transport: transport}
+		config.HTTPClient = httpClient
+		collectionClient, err := newComputeCollectionClient(&config)
+		if err != nil {
+			return nil, err
+		}
+		client.computeCollectionClient = collectionClient
+		httpClient.Transport = c.newTransport(transport, collectionClient)
 		computeClient, err := compute.NewComputeClient(&config)
 		if err != nil {
 			return nil, err
@@ -92,6 +138,14 @@ func (c *Config) Client() (*Client, error) {
 		if (c.StorageServiceID) != "" {
 			config.IdentityDomain = &c.StorageServiceID
 		}
+		httpClient := &http.Client{Transport: transport}
+		config.HTTPClient = httpClient
+		objectClient, err := newStorageObjectClient(&config)
+		if err != nil {
+			return nil, err
+		}
+		client.storageObjectClient = objectClient
+		httpClient.Transport = c.newTransport(transport, objectClient)
 		storageClient, err := storage.NewStorageClient(&config)
 		if err != nil {
 			return nil, err
@@ -107,6 +161,14 @@ func (c *Config) Client() (*Client, error) {
 			return nil, fmt.Errorf("Invalid LBaaS Endpoint URI: %+v", err)
 		}
 		config.APIEndpoint = lbaasEndpoint
+		config.HTTPClient = &http.Client{
+			Transport: c.newTransport(transport, nil),
+		}
+		requestClient, err := newLBaaSRequestClient(&config)
+		if err != nil {
+			return nil, err
+		}
+		client.lbaasRequestClient = requestClient
 		lbaasClient, err := lbaas.NewClient(&config)
 		if err != nil {
 			return nil, err
@@ -118,6 +180,27 @@ func (c *Config) Client() (*Client, error) {
 	return client, nil
 }
 
+// newTransport returns the transport of an API's HTTP client, which retries
+// failed requests and authenticates them again with the API's session, if it
+// has one. Every attempt is rate limited, including retries and
+// authentication requests, and the limits apply to each API separately.
+// Requests are cancelled when Terraform is stopped, which also ends the waits
+// of the client libraries at their next poll.
+func (c *Config) newTransport(transport http.RoundTripper, session apiSession) http.RoundTripper {
+	limited := newRateLimitTransport(transport, c.RequestsPerSecond, c.MaxConcurrentRequests)
+	authenticated := http.RoundTripper(limited)
+	if session != nil {
+		authenticated = newSessionTransport(limited, session)
+	}
+	retried := newRetryTransport(authenticated, c.MaxRetries, c.Retry)
+	if c.StopContext == nil {
+		return retried
+	}
+	return &stopTransport{transport: retried, stopContext: c.StopContext}
+}
+
+// opcLogger logs the debug messages of the client libraries, which include
+// request bodies and headers, with any secrets redacted
 type opcLogger struct{}
 
 func (l opcLogger) Log(args ...interface{}) {
@@ -128,7 +211,7 @@ func (l opcLogger) Log(args ...interface{}) {
 		}
 	}
 	log.SetFlags(0)
-	log.Print(fmt.Sprintf("go-oracle-terraform: %s", strings.Join(tokens, " ")))
+	log.Print(fmt.Sprintf("go-oracle-terraform: %s", redactLogString(strings.Join(tokens, " "))))
 }
 
 func (c *Client) getComputeClient() (*compute.Client, error) {
@@ -138,6 +221,13 @@ func (c *Client) getComputeClient() (*compute.Client, error) {
 	return c.computeClient, nil
 }
 
+func (c *Client) getComputeCollectionClient() (*computeCollectionClient, error) {
+	if c.computeCollectionClient == nil {
+		return nil, fmt.Errorf("Compute API client has not been initialized. Ensure the `endpoint` for the Compute Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.computeCollectionClient, nil
+}
+
 func (c *Client) getStorageClient() (*storage.Client, error) {
 	if c.storageClient == nil {
 		return nil, fmt.Errorf("Storage API client has not been initialized. Ensure the `storage_endpoint` for the Object Storage Classic REST API Endpoint has been declared in the provider configuration.")
@@ -145,9 +235,23 @@ func (c *Client) getStorageClient() (*storage.Client, error) {
 	return c.storageClient, nil
 }
 
+func (c *Client) getStorageObjectClient() (*storageObjectClient, error) {
+	if c.storageObjectClient == nil {
+		return nil, fmt.Errorf("Storage API client has not been initialized. Ensure the `storage_endpoint` for the Object Storage Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.storageObjectClient, nil
+}
+
 func (c *Client) getLBaaSClient() (*lbaas.Client, error) {
 	if c.lbaasClient == nil {
 		return nil, fmt.Errorf("Load Balancer API client has not been initialized. Ensure the `lbaas_endpoint` for the Load Balancer Classic REST API Endpoint has been declared in the provider configuration.")
 	}
 	return c.lbaasClient, nil
 }
+
+func (c *Client) getLBaaSRequestClient() (*lbaasRequestClient, error) {
+	if c.lbaasRequestClient == nil {
+		return nil, fmt.Errorf("Load Balancer API client has not been initialized. Ensure the `lbaas_endpoint` for the Load Balancer Classic REST API Endpoint has been declared in the provider configuration.")
+	}
+	return c.lbaasRequestClient, nil
+}
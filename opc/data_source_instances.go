@@ -0,0 +1,139 @@
+package opc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceInstances() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceInstancesRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"tags": tagsOptionalSchema(),
+
+			"state": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"instances": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"hostname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"fqdn": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"label": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"shape": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"image_list": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"ip_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"availability_domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"tags": tagsComputedSchema(),
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceInstancesRead(d *schema.ResourceData, meta interface{}) error {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	var instances []compute.InstanceInfo
+	if err := collectionClient.list("/instance", &instances); err != nil {
+		return fmt.Errorf("Error listing instances: %s", newAPIError(err))
+	}
+
+	filter := collectionFilter{
+		namePrefix: d.Get("name_prefix").(string),
+		tags:       getStringList(d, "tags"),
+		state:      d.Get("state").(string),
+	}
+
+	ids := make([]string, 0, len(instances))
+	result := make([]map[string]interface{}, 0, len(instances))
+	for _, instance := range instances {
+		// The returned 'name' attribute is the fully qualified instance name + "/" + ID
+		nameAndID := strings.Split(collectionClient.unqualify(instance.FQDN), "/")
+		name := strings.Join(nameAndID[0:len(nameAndID)-1], "/")
+		id := nameAndID[len(nameAndID)-1]
+
+		if !filter.matches(name, instance.Tags, string(instance.State)) {
+			continue
+		}
+
+		ids = append(ids, id)
+		result = append(result, map[string]interface{}{
+			"name":                name,
+			"id":                  id,
+			"hostname":            strings.Split(instance.Hostname, ".")[0],
+			"fqdn":                instance.Hostname,
+			"label":               instance.Label,
+			"shape":               instance.Shape,
+			"image_list":          instance.ImageList,
+			"ip_address":          instance.IPAddress,
+			"availability_domain": instance.AvailabilityDomain,
+			"state":               string(instance.State),
+			"tags":                instance.Tags,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(ids, ","))))
+	return d.Set("instances", result)
+}
@@ -0,0 +1,56 @@
+package opc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceInstances_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	dataName := "data.opc_compute_instances.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceInstancesResources(rInt),
+			},
+			{
+				Config: testAccDataSourceInstancesBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataName, "instances.#", "1"),
+					resource.TestCheckResourceAttr(dataName, "instances.0.name", fmt.Sprintf("acc-test-instances-%d", rInt)),
+					resource.TestCheckResourceAttrPair(dataName, "instances.0.id", "opc_compute_instance.test", "id"),
+					resource.TestCheckResourceAttr(dataName, "instances.0.state", "running"),
+					resource.TestCheckResourceAttr(dataName, "instances.0.shape", "oc3"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceInstancesResources(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-instances-%d"
+  label      = "TestAccOPCDataSourceInstances_basic"
+  shape      = "oc3"
+  image_list = "%s"
+  tags       = ["tag1", "tag2"]
+}
+`, rInt, TestImageList)
+}
+
+func testAccDataSourceInstancesBasic(rInt int) string {
+	return fmt.Sprintf(`%s
+data "opc_compute_instances" "test" {
+  name_prefix = "acc-test-instances-%d"
+  tags        = ["tag1"]
+  state       = "running"
+}
+`, testAccDataSourceInstancesResources(rInt), rInt)
+}
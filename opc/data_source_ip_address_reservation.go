@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -53,11 +52,11 @@ func dataSourceIPAddressReservationRead(d *schema.ResourceData, meta interface{}
 
 	result, err := resClient.GetIPAddressReservation(&input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ip address reservation %s: %s", name, err)
+		return fmt.Errorf("Error reading ip address reservation %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
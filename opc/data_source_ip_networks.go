@@ -0,0 +1,105 @@
+package opc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceIPNetworks() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceIPNetworksRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"tags": tagsOptionalSchema(),
+
+			"ip_networks": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"ip_address_prefix": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"ip_network_exchange": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"public_napt_enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"tags": tagsComputedSchema(),
+
+						"uri": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceIPNetworksRead(d *schema.ResourceData, meta interface{}) error {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	var networks []compute.IPNetworkInfo
+	if err := collectionClient.list("/network/v1/ipnetwork", &networks); err != nil {
+		return fmt.Errorf("Error listing IP networks: %s", newAPIError(err))
+	}
+
+	filter := collectionFilter{
+		namePrefix: d.Get("name_prefix").(string),
+		tags:       getStringList(d, "tags"),
+	}
+
+	names := make([]string, 0, len(networks))
+	result := make([]map[string]interface{}, 0, len(networks))
+	for _, network := range networks {
+		name := collectionClient.unqualify(network.FQDN)
+		if !filter.matches(name, network.Tags, "") {
+			continue
+		}
+
+		names = append(names, name)
+		result = append(result, map[string]interface{}{
+			"name":                name,
+			"ip_address_prefix":   network.IPAddressPrefix,
+			"ip_network_exchange": collectionClient.unqualify(network.IPNetworkExchange),
+			"description":         network.Description,
+			"public_napt_enabled": network.PublicNaptEnabled,
+			"tags":                network.Tags,
+			"uri":                 network.URI,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(names, ","))))
+	return d.Set("ip_networks", result)
+}
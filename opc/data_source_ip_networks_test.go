@@ -0,0 +1,58 @@
+package opc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceIPNetworks_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	dataName := "data.opc_compute_ip_networks.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceIPNetworksResources(rInt),
+			},
+			{
+				Config: testAccDataSourceIPNetworksBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataName, "ip_networks.#", "1"),
+					resource.TestCheckResourceAttr(dataName, "ip_networks.0.name", fmt.Sprintf("testing-ip-networks-%d-a", rInt)),
+					resource.TestCheckResourceAttr(dataName, "ip_networks.0.ip_address_prefix", "10.0.12.0/24"),
+					resource.TestCheckResourceAttr(dataName, "ip_networks.0.tags.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceIPNetworksResources(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_ip_network" "a" {
+  name              = "testing-ip-networks-%d-a"
+  ip_address_prefix = "10.0.12.0/24"
+  tags              = ["group-a", "web"]
+}
+
+resource "opc_compute_ip_network" "b" {
+  name              = "testing-ip-networks-%d-b"
+  ip_address_prefix = "10.0.13.0/24"
+  tags              = ["group-b", "web"]
+}
+`, rInt, rInt)
+}
+
+func testAccDataSourceIPNetworksBasic(rInt int) string {
+	return fmt.Sprintf(`%s
+data "opc_compute_ip_networks" "test" {
+  name_prefix = "testing-ip-networks-%d"
+  tags        = ["group-a"]
+}
+`, testAccDataSourceIPNetworksResources(rInt), rInt)
+}
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -53,11 +52,11 @@ func dataSourceIPReservationRead(d *schema.ResourceData, meta interface{}) error
 	result, err := resClient.GetIPReservation(&input)
 	if err != nil {
 		// IP Reservation does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ip reservation %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading ip reservation %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
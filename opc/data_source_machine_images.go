@@ -0,0 +1,123 @@
+package opc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceMachineImages() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceMachineImagesRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"state": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"machine_images": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"account": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"file": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"image_format": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"platform": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"error_reason": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"uri": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceMachineImagesRead(d *schema.ResourceData, meta interface{}) error {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	var images []compute.MachineImage
+	if err := collectionClient.list("/machineimage", &images); err != nil {
+		return fmt.Errorf("Error listing machine images: %s", newAPIError(err))
+	}
+
+	filter := collectionFilter{
+		namePrefix: d.Get("name_prefix").(string),
+		state:      d.Get("state").(string),
+	}
+
+	names := make([]string, 0, len(images))
+	result := make([]map[string]interface{}, 0, len(images))
+	for _, image := range images {
+		name := collectionClient.unqualify(image.FQDN)
+		if !filter.matches(name, nil, image.State) {
+			continue
+		}
+
+		names = append(names, name)
+		result = append(result, map[string]interface{}{
+			"name":         name,
+			"account":      image.Account,
+			"description":  image.Description,
+			"file":         image.File,
+			"image_format": image.ImageFormat,
+			"platform":     image.Platform,
+			"state":        image.State,
+			"error_reason": image.ErrorReason,
+			"uri":          image.URI,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(names, ","))))
+	return d.Set("machine_images", result)
+}
@@ -0,0 +1,58 @@
+package opc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceMachineImages_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	dataName := "data.opc_compute_machine_images.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceMachineImagesResources(rInt),
+			},
+			{
+				Config: testAccDataSourceMachineImagesBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataName, "machine_images.#", "1"),
+					resource.TestCheckResourceAttr(dataName, "machine_images.0.name", fmt.Sprintf("acc-test-machine-images-%d", rInt)),
+					resource.TestCheckResourceAttr(dataName, "machine_images.0.file", "acc-test-machine-images.tar.gz"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceMachineImagesResources(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_storage_object" "test" {
+  name         = "acc-test-machine-images.tar.gz"
+  container    = "compute_images"
+  file         = "test-fixtures/dummy.tar.gz"
+  content_type = "application/tar+gzip;charset=UTF-8"
+}
+
+resource "opc_compute_machine_image" "test" {
+  account = "/Compute-%s/cloud_storage"
+  name    = "acc-test-machine-images-%d"
+  file    = "${opc_storage_object.test.name}"
+}
+`, os.Getenv("OPC_IDENTITY_DOMAIN"), rInt)
+}
+
+func testAccDataSourceMachineImagesBasic(rInt int) string {
+	return fmt.Sprintf(`%s
+data "opc_compute_machine_images" "test" {
+  name_prefix = "acc-test-machine-images-%d"
+}
+`, testAccDataSourceMachineImagesResources(rInt), rInt)
+}
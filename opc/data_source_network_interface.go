@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -124,11 +123,11 @@ func dataSourceNetworkInterfaceRead(d *schema.ResourceData, meta interface{}) er
 
 	instance, err := resClient.GetInstance(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading instance %q: %v", instance_name, err)
+		return fmt.Errorf("Error reading instance %q: %v", instance_name, newAPIError(err))
 	}
 
 	result := compute.NetworkingInfo{}
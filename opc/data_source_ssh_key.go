@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -45,11 +44,11 @@ func dataSourceSSHKeyRead(d *schema.ResourceData, meta interface{}) error {
 
 	result, err := resClient.GetSSHKey(&input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ssh key %s: %s", name, err)
+		return fmt.Errorf("Error reading ssh key %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
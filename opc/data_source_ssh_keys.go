@@ -0,0 +1,96 @@
+package opc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceSSHKeys() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceSSHKeysRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			"ssh_keys": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"key": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"enabled": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"uri": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSSHKeysRead(d *schema.ResourceData, meta interface{}) error {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	var keys []compute.SSHKey
+	if err := collectionClient.list("/sshkey", &keys); err != nil {
+		return fmt.Errorf("Error listing ssh keys: %s", newAPIError(err))
+	}
+
+	filter := collectionFilter{
+		namePrefix: d.Get("name_prefix").(string),
+	}
+	enabled, filterEnabled := d.GetOkExists("enabled")
+
+	names := make([]string, 0, len(keys))
+	result := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		name := collectionClient.unqualify(key.FQDN)
+		if !filter.matches(name, nil, "") {
+			continue
+		}
+		if filterEnabled && key.Enabled != enabled.(bool) {
+			continue
+		}
+
+		names = append(names, name)
+		result = append(result, map[string]interface{}{
+			"name":    name,
+			"key":     key.Key,
+			"enabled": key.Enabled,
+			"uri":     key.URI,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(names, ","))))
+	return d.Set("ssh_keys", result)
+}
@@ -0,0 +1,58 @@
+package opc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceSSHKeys_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	dataName := "data.opc_compute_ssh_keys.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceSSHKeysResources(rInt),
+			},
+			{
+				Config: testAccDataSourceSSHKeysBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataName, "ssh_keys.#", "1"),
+					resource.TestCheckResourceAttr(dataName, "ssh_keys.0.name", fmt.Sprintf("acc-test-ssh-keys-%d-enabled", rInt)),
+					resource.TestCheckResourceAttr(dataName, "ssh_keys.0.key", test_ssh_key),
+					resource.TestCheckResourceAttr(dataName, "ssh_keys.0.enabled", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceSSHKeysResources(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_ssh_key" "enabled" {
+  name    = "acc-test-ssh-keys-%d-enabled"
+  key     = "%s"
+  enabled = true
+}
+
+resource "opc_compute_ssh_key" "disabled" {
+  name    = "acc-test-ssh-keys-%d-disabled"
+  key     = "%s"
+  enabled = false
+}
+`, rInt, test_ssh_key, rInt, test_ssh_key)
+}
+
+func testAccDataSourceSSHKeysBasic(rInt int) string {
+	return fmt.Sprintf(`%s
+data "opc_compute_ssh_keys" "test" {
+  name_prefix = "acc-test-ssh-keys-%d"
+  enabled     = true
+}
+`, testAccDataSourceSSHKeysResources(rInt), rInt)
+}
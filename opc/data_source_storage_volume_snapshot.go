@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -118,11 +117,11 @@ func dataSourceStorageVolumeSnapshotRead(d *schema.ResourceData, meta interface{
 
 	result, err := resClient.GetStorageVolumeSnapshot(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading storage volume snapshot '%s': %v", name, err)
+		return fmt.Errorf("Error reading storage volume snapshot '%s': %v", name, newAPIError(err))
 	}
 
 	if result == nil {
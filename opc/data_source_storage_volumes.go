@@ -0,0 +1,132 @@
+package opc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceStorageVolumes() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceStorageVolumesRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"tags": tagsOptionalSchema(),
+
+			"status": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"storage_volumes": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+
+						"storage_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"bootable": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+
+						"image_list": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+
+						"tags": tagsComputedSchema(),
+
+						"uri": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceStorageVolumesRead(d *schema.ResourceData, meta interface{}) error {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	var volumes []compute.StorageVolumeInfo
+	if err := collectionClient.list("/storage/volume", &volumes); err != nil {
+		return fmt.Errorf("Error listing storage volumes: %s", newAPIError(err))
+	}
+
+	filter := collectionFilter{
+		namePrefix: d.Get("name_prefix").(string),
+		tags:       getStringList(d, "tags"),
+		state:      d.Get("status").(string),
+	}
+
+	names := make([]string, 0, len(volumes))
+	result := make([]map[string]interface{}, 0, len(volumes))
+	for _, volume := range volumes {
+		name := collectionClient.unqualify(volume.FQDN)
+		if !filter.matches(name, volume.Tags, volume.Status) {
+			continue
+		}
+
+		size, err := sizeInGigaBytes(volume.Size)
+		if err != nil {
+			return fmt.Errorf("Error reading size of storage volume %s: %s", name, newAPIError(err))
+		}
+		storageType := ""
+		if len(volume.Properties) > 0 {
+			storageType = volume.Properties[0]
+		}
+
+		names = append(names, name)
+		result = append(result, map[string]interface{}{
+			"name":         name,
+			"description":  volume.Description,
+			"size":         size,
+			"storage_type": storageType,
+			"bootable":     volume.Bootable,
+			"image_list":   collectionClient.unqualify(volume.ImageList),
+			"status":       volume.Status,
+			"tags":         volume.Tags,
+			"uri":          volume.URI,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%d", hashcode.String(strings.Join(names, ","))))
+	return d.Set("storage_volumes", result)
+}
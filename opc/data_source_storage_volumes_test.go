@@ -0,0 +1,59 @@
+package opc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccOPCDataSourceStorageVolumes_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	dataName := "data.opc_compute_storage_volumes.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataSourceStorageVolumesResources(rInt),
+			},
+			{
+				Config: testAccDataSourceStorageVolumesBasic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(dataName, "storage_volumes.#", "1"),
+					resource.TestCheckResourceAttr(dataName, "storage_volumes.0.name", fmt.Sprintf("test-acc-storage-volumes-%d-small", rInt)),
+					resource.TestCheckResourceAttr(dataName, "storage_volumes.0.size", "1"),
+					resource.TestCheckResourceAttr(dataName, "storage_volumes.0.status", "Online"),
+				),
+			},
+		},
+	})
+}
+
+func testAccDataSourceStorageVolumesResources(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_storage_volume" "small" {
+  name = "test-acc-storage-volumes-%d-small"
+  size = 1
+  tags = ["small"]
+}
+
+resource "opc_compute_storage_volume" "large" {
+  name = "test-acc-storage-volumes-%d-large"
+  size = 2
+  tags = ["large"]
+}
+`, rInt, rInt)
+}
+
+func testAccDataSourceStorageVolumesBasic(rInt int) string {
+	return fmt.Sprintf(`%s
+data "opc_compute_storage_volumes" "test" {
+  name_prefix = "test-acc-storage-volumes-%d"
+  tags        = ["small"]
+  status      = "Online"
+}
+`, testAccDataSourceStorageVolumesResources(rInt), rInt)
+}
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -58,11 +57,11 @@ func dataSourceVNICRead(d *schema.ResourceData, meta interface{}) error {
 
 	vnic, err := resClient.GetVirtualNIC(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading vnic %s: %s", name, err)
+		return fmt.Errorf("Error reading vnic %s: %s", name, newAPIError(err))
 	}
 
 	if vnic == nil {
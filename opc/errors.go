@@ -0,0 +1,204 @@
+package opc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-oracle-terraform/opc"
+)
+
+// apiErrorKind classifies the error responses of the Oracle APIs
+type apiErrorKind int
+
+const (
+	apiErrorOther apiErrorKind = iota
+	apiErrorNotFound
+	apiErrorConflict
+	apiErrorQuotaExceeded
+	apiErrorUnauthorized
+	apiErrorValidation
+)
+
+func (k apiErrorKind) String() string {
+	switch k {
+	case apiErrorNotFound:
+		return "not found"
+	case apiErrorConflict:
+		return "conflict"
+	case apiErrorQuotaExceeded:
+		return "quota exceeded"
+	case apiErrorUnauthorized:
+		return "not authorized"
+	case apiErrorValidation:
+		return "invalid request"
+	}
+	return "error"
+}
+
+// apiError is an opc.OracleError with its body parsed. The client libraries
+// return the raw body of the Compute, LBaaS and Storage error responses as the
+// message, which is JSON, an HTML page or plain text depending on the API.
+type apiError struct {
+	Kind       apiErrorKind
+	StatusCode int
+	// Message is the reason for the error given by the API
+	Message string
+	// Field is the request field which failed validation, if the API names it
+	Field string
+
+	err error
+}
+
+func (e *apiError) Error() string {
+	message := e.Message
+	if e.Field != "" {
+		message = fmt.Sprintf("%s: %s", e.Field, message)
+	}
+	message = fmt.Sprintf("%s (HTTP %d, %s)", message, e.StatusCode, e.Kind)
+
+	switch e.Kind {
+	case apiErrorUnauthorized:
+		message += ". Check the user, password and identity_domain of the provider, and that the user has a role which allows the request"
+	case apiErrorQuotaExceeded:
+		message += ". Delete any unused resources, or ask for the quota of the account to be increased"
+	}
+	return message
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+var (
+	htmlTagRegexp    = regexp.MustCompile(`<[^>]*>`)
+	whitespaceRegexp = regexp.MustCompile(`\s+`)
+	quotaRegexp      = regexp.MustCompile(`(?i)quota|limit exceeded|exceeds? the (maximum|limit)`)
+)
+
+// newAPIError parses the error responses of the Oracle APIs into an apiError.
+// Any other error is returned as is, so that the errors of client calls can
+// always be passed through it.
+func newAPIError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if apiErr, ok := err.(*apiError); ok {
+		return apiErr
+	}
+
+	var statusCode int
+	var body string
+	var oracleErr *opc.OracleError
+	var oracleErrValue opc.OracleError
+	switch {
+	case errors.As(err, &oracleErr):
+		statusCode, body = oracleErr.StatusCode, oracleErr.Message
+	case errors.As(err, &oracleErrValue):
+		statusCode, body = oracleErrValue.StatusCode, oracleErrValue.Message
+	default:
+		return err
+	}
+
+	apiErr := &apiError{
+		StatusCode: statusCode,
+		err:        err,
+	}
+	apiErr.Message, apiErr.Field = parseAPIErrorBody(body)
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	apiErr.Kind = classifyAPIError(statusCode, apiErr.Message)
+	return apiErr
+}
+
+// parseAPIErrorBody returns the message and the invalid field, if any, of an
+// error response body:
+//
+//	Compute: {"message": "..."}
+//	LBaaS:   {"title": "...", "detail": "...", "o:errorPath": "..."}
+//	Storage: <html><h1>Not Found</h1><p>The resource could not be found.</p></html>
+func parseAPIErrorBody(body string) (string, string) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &fields); err == nil {
+		message, field := "", ""
+		for _, key := range []string{"message", "detail", "title", "error"} {
+			if v, ok := fields[key].(string); ok && v != "" {
+				message = v
+				break
+			}
+		}
+		if v, ok := fields["o:errorPath"].(string); ok {
+			field = v
+		}
+		// The first error detail is the most specific
+		if details, ok := fields["o:errorDetails"].([]interface{}); ok && len(details) > 0 {
+			if detail, ok := details[0].(map[string]interface{}); ok {
+				if v, ok := detail["detail"].(string); ok && v != "" {
+					message = v
+				}
+				if v, ok := detail["o:errorPath"].(string); ok && v != "" {
+					field = v
+				}
+			}
+		}
+		if message != "" {
+			return message, field
+		}
+	}
+
+	text := htmlTagRegexp.ReplaceAllString(body, " ")
+	return strings.TrimSpace(whitespaceRegexp.ReplaceAllString(text, " ")), ""
+}
+
+// classifyAPIError classifies an error response from its status code. The
+// only messages matched are those of the responses whose status code doesn't
+// tell their kind:
+//
+//	LBaaS:   getting a load balancer, listener, origin server pool or
+//	         certificate which has been deleted returns a 500 with the
+//	         misspelled detail "No such service exits" rather than a 404
+//	Compute: creating an instance or storage volume over the quota of the
+//	         account returns a 409 with a "Quota exceeded" message
+func classifyAPIError(statusCode int, message string) apiErrorKind {
+	switch {
+	case statusCode == http.StatusInternalServerError && message == "No such service exits":
+		return apiErrorNotFound
+	case statusCode == http.StatusConflict && quotaRegexp.MatchString(message):
+		return apiErrorQuotaExceeded
+	}
+
+	switch statusCode {
+	case http.StatusNotFound:
+		return apiErrorNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return apiErrorUnauthorized
+	case http.StatusRequestEntityTooLarge:
+		return apiErrorQuotaExceeded
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return apiErrorConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return apiErrorValidation
+	}
+	return apiErrorOther
+}
+
+func isAPIError(err error, kind apiErrorKind) bool {
+	apiErr, ok := newAPIError(err).(*apiError)
+	return ok && apiErr.Kind == kind
+}
+
+// wasNotFoundError reports whether err is an API error for a resource which
+// doesn't exist.
+func wasNotFoundError(err error) bool {
+	return isAPIError(err, apiErrorNotFound)
+}
+
+// wasConflictError reports whether err is an API error for a request which
+// conflicts with the current state of a resource, e.g. one that is in use.
+func wasConflictError(err error) bool {
+	return isAPIError(err, apiErrorConflict)
+}
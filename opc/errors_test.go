@@ -0,0 +1,76 @@
+package opc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-oracle-terraform/opc"
+)
+
+func TestNewAPIError(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		body       string
+		kind       apiErrorKind
+		message    string
+		field      string
+	}{
+		// Compute
+		{http.StatusNotFound, `{"message": "Instance /Compute-acme/jdoe/web does not exist"}`, apiErrorNotFound, "Instance /Compute-acme/jdoe/web does not exist", ""},
+		{http.StatusConflict, `{"message": "Conflict: storage volume /Compute-acme/jdoe/data is in use"}`, apiErrorConflict, "Conflict: storage volume /Compute-acme/jdoe/data is in use", ""},
+		{http.StatusConflict, `{"message": "Quota exceeded for storage: 5000 GB"}`, apiErrorQuotaExceeded, "Quota exceeded for storage: 5000 GB", ""},
+		{http.StatusUnauthorized, `{"message": "Incorrect username or password"}`, apiErrorUnauthorized, "Incorrect username or password", ""},
+		{http.StatusBadRequest, `{"message": "Shape oc99 is not available"}`, apiErrorValidation, "Shape oc99 is not available", ""},
+		// LBaaS
+		{http.StatusBadRequest, `{"type": "http://www.w3.org/Protocols/rfc2616/rfc2616-sec10.html#sec10.4.1", "title": "Bad Request", "detail": "Invalid request",
+			"o:errorDetails": [{"detail": "port must be between 1 and 65535", "o:errorPath": "port"}]}`, apiErrorValidation, "port must be between 1 and 65535", "port"},
+		{http.StatusInternalServerError, `{"title": "Internal Server Error", "detail": "No such service exits"}`, apiErrorNotFound, "No such service exits", ""},
+		{http.StatusInternalServerError, `{"title": "Internal Server Error", "detail": "No such service exits: listener is being updated"}`, apiErrorOther, "No such service exits: listener is being updated", ""},
+		{http.StatusBadRequest, `{"title": "Bad Request", "detail": "connection_limit exceeds the maximum of 1000"}`, apiErrorValidation, "connection_limit exceeds the maximum of 1000", ""},
+		// Storage
+		{http.StatusNotFound, `<html><h1>Not Found</h1><p>The resource could not be found.</p></html>`, apiErrorNotFound, "Not Found The resource could not be found.", ""},
+		{http.StatusConflict, "There was a conflict when trying to complete your request.", apiErrorConflict, "There was a conflict when trying to complete your request.", ""},
+		{http.StatusRequestEntityTooLarge, "", apiErrorQuotaExceeded, "Request Entity Too Large", ""},
+		{http.StatusServiceUnavailable, "", apiErrorOther, "Service Unavailable", ""},
+	}
+
+	for _, c := range cases {
+		for _, err := range []error{
+			&opc.OracleError{StatusCode: c.statusCode, Message: c.body},
+			opc.OracleError{StatusCode: c.statusCode, Message: c.body},
+		} {
+			apiErr, ok := newAPIError(err).(*apiError)
+			if !ok {
+				t.Fatalf("Expected an apiError for %#v", err)
+			}
+			if apiErr.Kind != c.kind || apiErr.Message != c.message || apiErr.Field != c.field || apiErr.StatusCode != c.statusCode {
+				t.Fatalf("Expected %s error %q for field %q, got %s error %q for field %q", c.kind, c.message, c.field, apiErr.Kind, apiErr.Message, apiErr.Field)
+			}
+			if strings.Contains(apiErr.Error(), "{") || strings.Contains(apiErr.Error(), "<") {
+				t.Fatalf("Expected the error not to include the raw body, got %q", apiErr.Error())
+			}
+		}
+	}
+
+	err := fmt.Errorf("Error parsing attributes")
+	if newAPIError(err) != err {
+		t.Fatal("Expected other errors to be returned as is")
+	}
+	if newAPIError(nil) != nil {
+		t.Fatal("Expected no error for nil")
+	}
+}
+
+func TestWasNotFoundError(t *testing.T) {
+	if !wasNotFoundError(&opc.OracleError{StatusCode: http.StatusNotFound}) {
+		t.Fatal("Expected a 404 to be a not found error")
+	}
+	if !wasNotFoundError(newAPIError(&opc.OracleError{StatusCode: http.StatusNotFound})) {
+		t.Fatal("Expected a parsed 404 to be a not found error")
+	}
+	if wasNotFoundError(&opc.OracleError{StatusCode: http.StatusConflict}) || wasNotFoundError(fmt.Errorf("404")) {
+		t.Fatal("Expected other errors not to be not found errors")
+	}
+}
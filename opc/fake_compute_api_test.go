@@ -0,0 +1,1049 @@
+package opc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+const (
+	fakeComputeContentType = "application/oracle-compute-v3+json"
+	fakeComputeCookieName  = "nimbula"
+	// fakeObjectDeleted is a pseudo attribute used in a pending transition to
+	// remove the object the next time it is requested.
+	fakeObjectDeleted = "__deleted"
+)
+
+// fakeComputeAPI is an in-process stand-in for the Compute Classic REST API.
+// It understands the /authenticate/ cookie handshake and the generic CRUD
+// paths used by compute.ResourceClient, and models the asynchronous state
+// transitions of instances, storage volumes, storage attachments and
+// orchestrations closely enough for the provider's waiters to complete.
+//
+// Objects are stored as raw JSON maps keyed by their object path
+// (ResourceRootPath + three-part name). Any state change which the real
+// service performs asynchronously is queued as a pending transition and
+// applied on the next GET of that object.
+type fakeComputeAPI struct {
+	*httptest.Server
+
+	IdentityDomain string
+	User           string
+	Password       string
+
+	// ObjectExists reports whether a storage object exists, so machine
+	// images can be registered from the files in the compute_images
+	// container. When nil, every file exists.
+	ObjectExists func(container, name string) bool
+
+	mu       sync.Mutex
+	objects  map[string]map[string]interface{}
+	pending  map[string][]map[string]interface{}
+	sessions map[string]bool
+	requests map[string]int
+	lastID   int
+}
+
+func newFakeComputeAPI(identityDomain, user, password string) *fakeComputeAPI {
+	f := &fakeComputeAPI{
+		IdentityDomain: identityDomain,
+		User:           user,
+		Password:       password,
+		objects:        make(map[string]map[string]interface{}),
+		pending:        make(map[string][]map[string]interface{}),
+		sessions:       make(map[string]bool),
+		requests:       make(map[string]int),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// ExpireSessions invalidates every authentication cookie issued so far, as the
+// service does when a session is revoked before its nominal lifetime.
+func (f *fakeComputeAPI) ExpireSessions() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions = make(map[string]bool)
+}
+
+// Requests returns the number of requests received for the given method and path.
+func (f *fakeComputeAPI) Requests(method, path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[method+" "+path]
+}
+
+// Object returns a copy of the stored object at the given object path.
+func (f *fakeComputeAPI) Object(path string) (map[string]interface{}, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[path]
+	if !ok {
+		return nil, false
+	}
+	return copyFakeObject(obj), true
+}
+
+// Patch merges the given attributes into a stored object, simulating an out of
+// band change such as an edit made through the console.
+func (f *fakeComputeAPI) Patch(path string, attrs map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if obj, ok := f.objects[path]; ok {
+		for k, v := range attrs {
+			obj[k] = v
+		}
+	}
+}
+
+// newID returns a new unique identifier in the format used by the service.
+func (f *fakeComputeAPI) newID() string {
+	f.lastID++
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", f.lastID, f.lastID)
+}
+
+// newIPAddress returns a new unique address within the given /16 prefix.
+func (f *fakeComputeAPI) newIPAddress(prefix string) string {
+	f.lastID++
+	return fmt.Sprintf("%s.%d.%d", prefix, f.lastID/250, f.lastID%250+2)
+}
+
+func (f *fakeComputeAPI) userPrefix() string {
+	return fmt.Sprintf("/Compute-%s/%s", f.IdentityDomain, f.User)
+}
+
+func (f *fakeComputeAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[r.Method+" "+r.URL.Path]++
+
+	var body map[string]interface{}
+	if r.Body != nil {
+		raw, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			f.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &body); err != nil {
+				f.writeError(w, http.StatusBadRequest, fmt.Sprintf("Unable to parse request body: %s", err))
+				return
+			}
+		}
+	}
+
+	if r.URL.Path == "/authenticate/" {
+		f.authenticate(w, r, body)
+		return
+	}
+
+	cookie, err := r.Cookie(fakeComputeCookieName)
+	if err != nil || !f.sessions[cookie.Value] {
+		f.writeError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		f.create(w, r.URL.Path, body)
+	case http.MethodGet:
+		if strings.HasSuffix(r.URL.Path, "/") {
+			f.list(w, r.URL.Path)
+			return
+		}
+		f.get(w, r.URL.Path)
+	case http.MethodPut:
+		f.update(w, r.URL.Path, body)
+	case http.MethodDelete:
+		f.delete(w, r.URL.Path)
+	default:
+		f.writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("Method %s not allowed", r.Method))
+	}
+}
+
+func (f *fakeComputeAPI) authenticate(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	if r.Method != http.MethodPost {
+		f.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if body["user"] != f.userPrefix() || body["password"] != f.Password {
+		f.writeError(w, http.StatusUnauthorized, "Incorrect username or password")
+		return
+	}
+
+	token := f.newID()
+	f.sessions[token] = true
+	http.SetCookie(w, &http.Cookie{
+		Name:   fakeComputeCookieName,
+		Value:  token,
+		Path:   "/",
+		MaxAge: 1800,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeComputeAPI) create(w http.ResponseWriter, containerPath string, body map[string]interface{}) {
+	if !strings.HasSuffix(containerPath, "/") {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("No such container %s", containerPath))
+		return
+	}
+
+	if containerPath == "/launchplan/" {
+		f.launch(w, body)
+		return
+	}
+
+	root := strings.TrimSuffix(containerPath, "/")
+	if objects, ok := body["objects"].([]interface{}); ok && root == "/platform/v1/orchestration" && len(objects) > 100 {
+		f.writeError(w, http.StatusBadRequest, "An orchestration can contain up to 100 objects")
+		return
+	}
+
+	name, _ := body["name"].(string)
+	if name == "" {
+		if version, ok := body["version"]; ok && strings.HasSuffix(root, "/entry") {
+			// Image list entries are addressed by their version
+			name = fmt.Sprintf("/%v", version)
+		} else {
+			// Associations and attachments are named by the server
+			name = fmt.Sprintf("%s/%s", f.userPrefix(), f.newID())
+			body["name"] = name
+		}
+	}
+
+	path := root + name
+	if _, ok := f.objects[path]; ok {
+		f.writeError(w, http.StatusConflict, fmt.Sprintf("Conflict: object %s already exists", name))
+		return
+	}
+
+	obj := copyFakeObject(body)
+	obj["uri"] = f.URL + path
+	f.applyCreateDefaults(root, path, obj)
+	f.normalize(path, obj)
+	f.objects[path] = obj
+
+	f.writeJSON(w, http.StatusCreated, obj)
+}
+
+// applyCreateDefaults fills in the computed attributes the service would add to
+// a newly created object, and queues its asynchronous transitions.
+func (f *fakeComputeAPI) applyCreateDefaults(root, path string, obj map[string]interface{}) {
+	switch root {
+	case "/ip/reservation":
+		if _, ok := obj["ip"]; !ok {
+			obj["ip"] = f.newIPAddress("129.150")
+		}
+	case "/network/v1/ipreservation":
+		if _, ok := obj["ipAddress"]; !ok {
+			obj["ipAddress"] = f.newIPAddress("129.150")
+		}
+
+	case "/storage/volume":
+		// Volumes restored from a snapshot ID report the name of the snapshot
+		if id, ok := obj["snapshot_id"].(string); ok && id != "" && obj["snapshot"] == nil {
+			for p, snapshot := range f.objects {
+				if strings.HasPrefix(p, "/storage/snapshot/") && snapshot["snapshot_id"] == id {
+					obj["snapshot"] = snapshot["name"]
+				}
+			}
+		}
+		obj["status"] = "Initializing"
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"status": "Online"})
+	case "/storage/attachment":
+		obj["state"] = "attaching"
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"state": "attached"})
+	case "/machineimage":
+		obj["state"] = "pending"
+		file, _ := obj["file"].(string)
+		if f.ObjectExists != nil && !f.ObjectExists("compute_images", file) {
+			f.pending[path] = append(f.pending[path], map[string]interface{}{
+				"state":        "error",
+				"error_reason": fmt.Sprintf("File %s not found in container compute_images", file),
+			})
+		} else {
+			f.pending[path] = append(f.pending[path], map[string]interface{}{"state": "available"})
+		}
+	case "/snapshot":
+		// Snapshots register a machine image of the instance once complete
+		image, _ := obj["machineimage"].(string)
+		if image == "" {
+			image = obj["name"].(string)
+			obj["machineimage"] = image
+		}
+		obj["state"] = "queued"
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"state": "complete"})
+		f.objects["/machineimage"+image] = map[string]interface{}{
+			"name":      image,
+			"account":   obj["account"],
+			"file":      fmt.Sprintf("%s.tar.gz", f.newID()),
+			"state":     "available",
+			"uri":       f.URL + "/machineimage" + image,
+			"no_upload": true,
+		}
+	case "/storage/snapshot":
+		// Snapshots report the size of their volume in bytes
+		volume, _ := obj["volume"].(string)
+		if v, ok := f.objects["/storage/volume"+volume]; ok {
+			obj["size"] = v["size"]
+			if _, ok := obj["parent_volume_bootable"]; !ok {
+				obj["parent_volume_bootable"] = fmt.Sprintf("%v", v["bootable"] == true)
+			}
+		}
+		if _, ok := obj["property"]; !ok {
+			obj["property"] = "/oracle/private/storage/snapshot/default"
+		}
+		obj["snapshot_id"] = f.newID()
+		obj["snapshot_timestamp"] = "2017-01-01T00:00:00Z"
+		obj["start_timestamp"] = "2017-01-01T00:00:00Z"
+		obj["status"] = "creating"
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"status": "completed"})
+	case "/vpnendpoint/v2":
+		obj["lifecycleState"] = "provisioning"
+		obj["tunnelStatus"] = "PENDING"
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"lifecycleState": "ready", "tunnelStatus": "DOWN"})
+	case "/platform/v1/orchestration":
+		obj["id"] = f.newID()
+		obj["version"] = 1
+		f.transitionOrchestration(path, obj)
+	}
+}
+
+// normalize rewrites attributes the way the service does when storing them.
+func (f *fakeComputeAPI) normalize(path string, obj map[string]interface{}) {
+	if strings.HasPrefix(path, "/seclist/") {
+		// Policies are reported in upper case regardless of the input
+		for _, k := range []string{"policy", "outbound_cidr_policy"} {
+			if v, ok := obj[k].(string); ok {
+				obj[k] = strings.ToUpper(v)
+			}
+		}
+	}
+}
+
+// launch handles a launch plan, creating every instance it describes.
+func (f *fakeComputeAPI) launch(w http.ResponseWriter, plan map[string]interface{}) {
+	specs, _ := plan["instances"].([]interface{})
+	if len(specs) == 0 {
+		f.writeError(w, http.StatusBadRequest, "Launch plan must contain at least one instance")
+		return
+	}
+
+	launched := make([]interface{}, 0, len(specs))
+	for _, s := range specs {
+		spec, ok := s.(map[string]interface{})
+		if !ok {
+			f.writeError(w, http.StatusBadRequest, "Invalid instance specification")
+			return
+		}
+		if msg := f.invalidInstanceNetworking(spec); msg != "" {
+			f.writeError(w, http.StatusBadRequest, msg)
+			return
+		}
+		launched = append(launched, f.launchInstance(spec))
+	}
+
+	f.writeJSON(w, http.StatusCreated, map[string]interface{}{"instances": launched})
+}
+
+// invalidInstanceNetworking returns why the service would refuse the
+// networking of an instance, or "" if it's valid. An interface's static IP
+// address has to be within the prefix of its IP network.
+func (f *fakeComputeAPI) invalidInstanceNetworking(spec map[string]interface{}) string {
+	networking, _ := spec["networking"].(map[string]interface{})
+	for iface, n := range networking {
+		info, _ := n.(map[string]interface{})
+		ipNetwork, _ := info["ipnetwork"].(string)
+		address, _ := info["ip"].(string)
+		if ipNetwork == "" || address == "" {
+			continue
+		}
+		network, ok := f.objects["/network/v1/ipnetwork"+ipNetwork]
+		if !ok {
+			return fmt.Sprintf("Interface %s refers to unknown IP network %s", iface, ipNetwork)
+		}
+		prefix, _ := network["ipAddressPrefix"].(string)
+		if _, cidr, err := net.ParseCIDR(prefix); err == nil && !cidr.Contains(net.ParseIP(address)) {
+			return fmt.Sprintf("IP address %s of interface %s is not within %s of IP network %s", address, iface, prefix, ipNetwork)
+		}
+	}
+	return ""
+}
+
+func (f *fakeComputeAPI) launchInstance(spec map[string]interface{}) map[string]interface{} {
+	id := f.newID()
+	name, _ := spec["name"].(string)
+	if name == "" {
+		name = fmt.Sprintf("%s/%s", f.userPrefix(), id)
+	}
+	fqdn := fmt.Sprintf("%s/%s", name, id)
+	path := "/instance" + fqdn
+
+	obj := copyFakeObject(spec)
+	obj["name"] = fqdn
+	obj["id"] = id
+	obj["uri"] = f.URL + path
+	obj["state"] = "queued"
+	obj["desired_state"] = "running"
+	if v, ok := spec["desired_state"].(string); ok && v != "" {
+		obj["desired_state"] = v
+	}
+
+	hostname, _ := spec["hostname"].(string)
+	if hostname == "" {
+		hostname = id[:8]
+	}
+	domain := fmt.Sprintf("compute-%s.oraclecloud.internal.", f.IdentityDomain)
+	obj["hostname"] = fmt.Sprintf("%s.%s", hostname, domain)
+	obj["domain"] = domain
+	obj["availability_domain"] = "/uscom-central-1a"
+	obj["ip"] = f.newIPAddress("10.196")
+	obj["vcable_id"] = fmt.Sprintf("%s/%s", f.userPrefix(), id)
+	obj["platform"] = "linux"
+	obj["priority"] = "/oracle/public/default"
+	obj["placement_requirements"] = []interface{}{
+		"/system/compute/placement/default",
+		"/system/compute/allow_instances",
+	}
+	obj["virtio"] = false
+	obj["site"] = ""
+	obj["start_time"] = "2017-01-01T00:00:00Z"
+	if _, ok := obj["label"]; !ok || obj["label"] == "" {
+		obj["label"] = id
+	}
+	if _, ok := obj["attributes"].(map[string]interface{}); !ok {
+		obj["attributes"] = map[string]interface{}{}
+	}
+	if _, ok := obj["reverse_dns"]; !ok {
+		obj["reverse_dns"] = true
+	}
+	obj["networking"] = f.defaultNetworking(id, obj["networking"])
+
+	// Volumes attached at launch are modelled as storage attachments so they
+	// show up both on the instance and through the attachments API.
+	if storage, ok := obj["storage_attachments"].([]interface{}); ok {
+		for _, s := range storage {
+			sa, _ := s.(map[string]interface{})
+			attachmentName := fmt.Sprintf("%s/%s", fqdn, f.newID())
+			f.objects["/storage/attachment"+attachmentName] = map[string]interface{}{
+				"name":                attachmentName,
+				"index":               sa["index"],
+				"instance_name":       fqdn,
+				"storage_volume_name": sa["volume"],
+				"state":               "attached",
+				"uri":                 f.URL + "/storage/attachment" + attachmentName,
+			}
+		}
+	}
+	delete(obj, "storage_attachments")
+
+	// IP network interfaces are backed by a virtual NIC, named by the vnic of
+	// the interface
+	networking, _ := obj["networking"].(map[string]interface{})
+	for _, v := range networking {
+		iface, _ := v.(map[string]interface{})
+		vnic, _ := iface["vnic"].(string)
+		if vnic == "" {
+			continue
+		}
+		f.objects["/network/v1/vnic"+vnic] = map[string]interface{}{
+			"name":          vnic,
+			"description":   "",
+			"macAddress":    iface["address"],
+			"transitFlag":   false,
+			"tags":          []interface{}{},
+			"uri":           f.URL + "/network/v1/vnic" + vnic,
+			"instance_name": fqdn,
+		}
+		// The virtual NIC joins the VNIC sets of its interface
+		vnicSets, _ := iface["vnicsets"].([]interface{})
+		for _, v := range vnicSets {
+			if vnicSet, ok := f.objects[fmt.Sprintf("/network/v1/vnicset%v", v)]; ok {
+				vnics, _ := vnicSet["vnics"].([]interface{})
+				vnicSet["vnics"] = append(vnics, vnic)
+			}
+		}
+	}
+
+	f.objects[path] = obj
+	f.pending[path] = append(f.pending[path], map[string]interface{}{"state": obj["desired_state"]})
+
+	return f.renderInstance(obj)
+}
+
+func (f *fakeComputeAPI) defaultNetworking(id string, networking interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	ifaces, _ := networking.(map[string]interface{})
+	for device, v := range ifaces {
+		iface := copyFakeObject(v.(map[string]interface{}))
+		if _, ok := iface["model"]; ok {
+			// Shared network interface
+			if _, ok := iface["seclists"]; !ok {
+				iface["seclists"] = []interface{}{fmt.Sprintf("/Compute-%s/default/default", f.IdentityDomain)}
+			}
+			if _, ok := iface["dns"]; !ok {
+				iface["dns"] = []interface{}{id[:8]}
+			}
+		} else if _, ok := iface["address"]; !ok {
+			iface["address"] = "c6:b0:6d:1a:58:70"
+		}
+		result[device] = iface
+	}
+	return result
+}
+
+// renderInstance returns the representation of an instance, including the
+// storage attachments currently associated with it.
+func (f *fakeComputeAPI) renderInstance(obj map[string]interface{}) map[string]interface{} {
+	result := copyFakeObject(obj)
+	attachments := make([]interface{}, 0)
+	for _, path := range f.sortedPaths("/storage/attachment/") {
+		sa := f.objects[path]
+		if sa["instance_name"] != obj["name"] {
+			continue
+		}
+		attachments = append(attachments, map[string]interface{}{
+			"index":               sa["index"],
+			"name":                sa["name"],
+			"storage_volume_name": sa["storage_volume_name"],
+		})
+	}
+	result["storage_attachments"] = attachments
+	return result
+}
+
+func (f *fakeComputeAPI) get(w http.ResponseWriter, path string) {
+	obj, ok := f.advance(path)
+	if !ok {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("%s does not exist", path))
+		return
+	}
+	f.writeJSON(w, http.StatusOK, f.render(path, obj))
+}
+
+func (f *fakeComputeAPI) list(w http.ResponseWriter, prefix string) {
+	result := make([]interface{}, 0)
+	for _, path := range f.sortedPaths(prefix) {
+		if obj, ok := f.advance(path); ok {
+			result = append(result, f.render(path, obj))
+		}
+	}
+	f.writeJSON(w, http.StatusOK, map[string]interface{}{"result": result})
+}
+
+func (f *fakeComputeAPI) render(path string, obj map[string]interface{}) map[string]interface{} {
+	if strings.HasPrefix(path, "/instance/") {
+		return f.renderInstance(obj)
+	}
+	return copyFakeObject(obj)
+}
+
+// advance applies the next pending transition for the object at path, if any,
+// and returns the resulting object.
+func (f *fakeComputeAPI) advance(path string) (map[string]interface{}, bool) {
+	obj, ok := f.objects[path]
+	if !ok {
+		return nil, false
+	}
+
+	if queue := f.pending[path]; len(queue) > 0 {
+		next := queue[0]
+		f.pending[path] = queue[1:]
+		if _, deleted := next[fakeObjectDeleted]; deleted {
+			f.remove(path)
+			return nil, false
+		}
+		for k, v := range next {
+			obj[k] = v
+		}
+	}
+	return obj, true
+}
+
+func (f *fakeComputeAPI) update(w http.ResponseWriter, path string, body map[string]interface{}) {
+	obj, ok := f.objects[path]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("%s does not exist", path))
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/instance/"):
+		if desired, ok := body["desired_state"].(string); ok && desired != "" && desired != obj["desired_state"] {
+			obj["desired_state"] = desired
+			if desired == "shutdown" {
+				obj["state"] = "stopping"
+			} else {
+				obj["state"] = "starting"
+			}
+			f.pending[path] = append(f.pending[path], map[string]interface{}{"state": desired})
+		}
+		if tags, ok := body["tags"]; ok {
+			obj["tags"] = tags
+		}
+	case strings.HasPrefix(path, "/platform/v1/orchestration/"):
+		if version, ok := body["version"]; ok && fmt.Sprintf("%v", version) != fmt.Sprintf("%v", obj["version"]) {
+			f.writeError(w, http.StatusConflict, fmt.Sprintf("Conflict: version %v does not match current version %v", version, obj["version"]))
+			return
+		}
+		if objects, ok := body["objects"].([]interface{}); ok {
+			f.updateOrchestrationInstances(obj, objects)
+		}
+		for k, v := range body {
+			obj[k] = v
+		}
+		obj["version"] = fakeInt(obj["version"]) + 1
+		f.transitionOrchestration(path, obj)
+	case strings.HasPrefix(path, "/storage/volume/"):
+		for k, v := range body {
+			obj[k] = v
+		}
+		obj["status"] = "Updating"
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"status": "Online"})
+	case strings.HasPrefix(path, "/vpnendpoint/v2/"):
+		for k, v := range body {
+			obj[k] = v
+		}
+		obj["lifecycleState"] = "updating"
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"lifecycleState": "ready"})
+	default:
+		for k, v := range body {
+			obj[k] = v
+		}
+		f.normalize(path, obj)
+	}
+
+	f.writeJSON(w, http.StatusOK, f.render(path, obj))
+}
+
+func (f *fakeComputeAPI) delete(w http.ResponseWriter, path string) {
+	obj, ok := f.objects[path]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("%s does not exist", path))
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/instance/"):
+		obj["state"] = "stopping"
+		f.pending[path] = []map[string]interface{}{{fakeObjectDeleted: true}}
+	case strings.HasPrefix(path, "/storage/attachment/"):
+		obj["state"] = "detaching"
+		f.pending[path] = []map[string]interface{}{{fakeObjectDeleted: true}}
+	case strings.HasPrefix(path, "/storage/volume/"):
+		for _, p := range f.sortedPaths("/storage/attachment/") {
+			if f.objects[p]["storage_volume_name"] == obj["name"] {
+				f.writeError(w, http.StatusConflict, fmt.Sprintf("Conflict: storage volume %s is in use by %s", obj["name"], f.objects[p]["instance_name"]))
+				return
+			}
+		}
+		f.remove(path)
+	case strings.HasPrefix(path, "/platform/v1/orchestration/"):
+		obj["status"] = "stopping"
+		f.pending[path] = []map[string]interface{}{{fakeObjectDeleted: true}}
+	default:
+		f.remove(path)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// remove deletes an object along with anything the service would tear down
+// with it.
+func (f *fakeComputeAPI) remove(path string) {
+	obj := f.objects[path]
+	delete(f.objects, path)
+	delete(f.pending, path)
+
+	switch {
+	case strings.HasPrefix(path, "/instance/"):
+		for _, root := range []string{"/storage/attachment/", "/network/v1/vnic/"} {
+			for _, p := range f.sortedPaths(root) {
+				if f.objects[p]["instance_name"] == obj["name"] {
+					if root == "/network/v1/vnic/" {
+						f.removeFromVNICSets(f.objects[p]["name"])
+					}
+					delete(f.objects, p)
+					delete(f.pending, p)
+				}
+			}
+		}
+	case strings.HasPrefix(path, "/platform/v1/orchestration/"):
+		f.removeOrchestrationInstances(obj, false)
+	}
+}
+
+// removeFromVNICSets removes a deleted virtual NIC from the VNIC sets it was
+// part of.
+func (f *fakeComputeAPI) removeFromVNICSets(vnic interface{}) {
+	for _, p := range f.sortedPaths("/network/v1/vnicset/") {
+		vnics, _ := f.objects[p]["vnics"].([]interface{})
+		kept := make([]interface{}, 0, len(vnics))
+		for _, v := range vnics {
+			if v != vnic {
+				kept = append(kept, v)
+			}
+		}
+		f.objects[p]["vnics"] = kept
+	}
+}
+
+// transitionOrchestration reconciles the objects of an orchestration with its
+// desired state, and queues the status change the service would report.
+func (f *fakeComputeAPI) transitionOrchestration(path string, obj map[string]interface{}) {
+	desired, _ := obj["desired_state"].(string)
+	switch desired {
+	case "active":
+		obj["status"] = "activating"
+		status := "active"
+		objects, _ := obj["objects"].([]interface{})
+		for _, o := range objects {
+			object, _ := o.(map[string]interface{})
+			if object["type"] != "Instance" {
+				continue
+			}
+			template, _ := object["template"].(map[string]interface{})
+			name, _ := template["name"].(string)
+			if f.instanceNamed(name) != "" {
+				continue
+			}
+			// The orchestration fails if any of its instances can't be launched
+			if msg := f.invalidInstanceNetworking(template); msg != "" {
+				object["health"] = map[string]interface{}{"status": "terminal_error", "error": msg}
+				status = "terminal_error"
+				continue
+			}
+			spec := copyFakeObject(template)
+			spec["desired_state"] = "running"
+			f.launchInstance(spec)
+			object["health"] = map[string]interface{}{"status": "active"}
+		}
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"status": status})
+	case "suspend":
+		obj["status"] = "suspending"
+		f.removeOrchestrationInstances(obj, true)
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"status": "suspended"})
+	case "inactive":
+		obj["status"] = "deactivating"
+		f.removeOrchestrationInstances(obj, false)
+		f.pending[path] = append(f.pending[path], map[string]interface{}{"status": "inactive"})
+	}
+}
+
+// updateOrchestrationInstances removes the instances of the objects which an
+// update drops or whose template it changes, as the service does before it
+// launches their replacements.
+func (f *fakeComputeAPI) updateOrchestrationInstances(obj map[string]interface{}, objects []interface{}) {
+	templates := make(map[string]interface{})
+	for _, o := range objects {
+		if object, _ := o.(map[string]interface{}); object["type"] == "Instance" {
+			templates[fmt.Sprintf("%v", object["label"])] = object["template"]
+		}
+	}
+
+	current, _ := obj["objects"].([]interface{})
+	for _, o := range current {
+		object, _ := o.(map[string]interface{})
+		if object["type"] != "Instance" {
+			continue
+		}
+		if template, ok := templates[fmt.Sprintf("%v", object["label"])]; ok && reflect.DeepEqual(template, object["template"]) {
+			continue
+		}
+		template, _ := object["template"].(map[string]interface{})
+		name, _ := template["name"].(string)
+		if p := f.instanceNamed(name); p != "" {
+			f.remove(p)
+		}
+	}
+}
+
+func (f *fakeComputeAPI) removeOrchestrationInstances(obj map[string]interface{}, keepPersistent bool) {
+	objects, _ := obj["objects"].([]interface{})
+	for _, o := range objects {
+		object, _ := o.(map[string]interface{})
+		if object["type"] != "Instance" {
+			continue
+		}
+		if persistent, _ := object["persistent"].(bool); persistent && keepPersistent {
+			continue
+		}
+		template, _ := object["template"].(map[string]interface{})
+		name, _ := template["name"].(string)
+		if p := f.instanceNamed(name); p != "" {
+			f.remove(p)
+		}
+	}
+}
+
+// instanceNamed returns the object path of the instance with the given
+// three-part name, or an empty string if there is none.
+func (f *fakeComputeAPI) instanceNamed(name string) string {
+	if name == "" {
+		return ""
+	}
+	if paths := f.sortedPaths("/instance" + name + "/"); len(paths) > 0 {
+		return paths[0]
+	}
+	return ""
+}
+
+func (f *fakeComputeAPI) sortedPaths(prefix string) []string {
+	paths := make([]string, 0)
+	for p := range f.objects {
+		if strings.HasPrefix(p, prefix) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (f *fakeComputeAPI) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", fakeComputeContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (f *fakeComputeAPI) writeError(w http.ResponseWriter, status int, message string) {
+	f.writeJSON(w, status, map[string]interface{}{"message": message})
+}
+
+// copyFakeObject returns a deep copy of a decoded JSON object.
+func copyFakeObject(obj map[string]interface{}) map[string]interface{} {
+	var result map[string]interface{}
+	b, _ := json.Marshal(obj)
+	_ = json.Unmarshal(b, &result)
+	return result
+}
+
+func fakeInt(v interface{}) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+func TestFakeComputeAPI_resources(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	testAccFakeAPIUnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			opcResourceCheck("opc_compute_ip_network.test", testAccOPCCheckIPNetworkDestroyed),
+			testAccCheckSecurityListDestroy,
+			testAccOPCCheckSSHKeyDestroy,
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFakeComputeAPIResources(rInt, "first"),
+				Check: resource.ComposeTestCheckFunc(
+					opcResourceCheck("opc_compute_ip_network.test", testAccOPCCheckIPNetworkExists),
+					testAccCheckSecurityListExists,
+					testAccOPCCheckSSHKeyExists,
+					resource.TestCheckResourceAttr("opc_compute_ip_network.test", "description", "first"),
+					resource.TestCheckResourceAttr("opc_compute_security_list.test", "policy", "DENY"),
+				),
+			},
+			{
+				Config: testAccFakeComputeAPIResources(rInt, "second"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("opc_compute_ip_network.test", "description", "second"),
+				),
+			},
+		},
+	})
+}
+
+func testAccFakeComputeAPIResources(rInt int, description string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_ip_network" "test" {
+  name              = "fake-ip-network-%d"
+  description       = "%s"
+  ip_address_prefix = "10.0.12.0/24"
+}
+
+resource "opc_compute_security_list" "test" {
+  name                 = "fake-sec-list-%d"
+  policy               = "deny"
+  outbound_cidr_policy = "permit"
+}
+
+resource "opc_compute_ssh_key" "test" {
+  name = "fake-ssh-key-%d"
+  key  = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC7Wa2OClh4LDCpR4A1x251PfzeUHvA3uo3Z4joYKIlQXP6242588bq6eh79ihm+HZAuxNoIkkS4OMIelUtiHcYSMYK7niXpato3cUdQHXjwchZjc3wwcXC/hAWK2QJkO7yLgCuYMTqyz2saZ/9zW12QS24rJH1DKFDbq4V40+HF7PQoq6G40Dp0X+slZri223pHJiqHKlyhUZuvMar7QnLZlZ7jenPyqVSpY7IC5KPj6geQSD2tSnVKjRo4TWVkIexSo6iHEu5vzcjVYGBw9RVGhmOd8pCcbB85M01MJFdbqLMjUHREE7/t767hmem3YdSPhMvnbBNPb7VSB+8ZQKn"
+}
+`, rInt, description, rInt, rInt)
+}
+
+func TestFakeComputeAPI_machineImageError(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	testAccFakeAPIUnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckMachineImageDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The file was never uploaded to compute_images
+				Config:      testAccFakeComputeAPIMachineImage(rInt),
+				ExpectError: regexp.MustCompile(fmt.Sprintf("error state: File missing-%d.tar.gz not found", rInt)),
+			},
+		},
+	})
+}
+
+func testAccFakeComputeAPIMachineImage(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_machine_image" "test" {
+  account = "/Compute-fakedomain/cloud_storage"
+  name    = "acc-test-machine-image-%d"
+  file    = "missing-%d.tar.gz"
+}`, rInt, rInt)
+}
+
+func TestFakeComputeAPI_authentication(t *testing.T) {
+	f := newFakeComputeAPI("fakedomain", "user", "password")
+	defer f.Close()
+
+	config := Config{
+		User:           "user",
+		Password:       "wrong",
+		IdentityDomain: "fakedomain",
+		Endpoint:       f.URL,
+		MaxRetries:     1,
+	}
+	if _, err := config.Client(); err == nil {
+		t.Fatal("Expected authentication with an invalid password to fail")
+	}
+
+	config.Password = "password"
+	opcClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("Error authenticating: %s", err)
+	}
+
+	if _, err := opcClient.computeClient.SSHKeys().GetSSHKey(&compute.GetSSHKeyInput{Name: "missing"}); !wasNotFoundError(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestFakeComputeAPI_sessionExpired(t *testing.T) {
+	f := newFakeComputeAPI("fakedomain", "user", "password")
+	defer f.Close()
+
+	config := Config{
+		User:           "user",
+		Password:       "password",
+		IdentityDomain: "fakedomain",
+		Endpoint:       f.URL,
+		MaxRetries:     1,
+	}
+	opcClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("Error authenticating: %s", err)
+	}
+
+	// The collection client shares the session of the compute client
+	var keys []compute.SSHKey
+	if err := opcClient.computeCollectionClient.list("/sshkey", &keys); err != nil {
+		t.Fatalf("Error listing SSH keys: %s", err)
+	}
+	if n := f.Requests("POST", "/authenticate/"); n != 1 {
+		t.Fatalf("Expected 1 authentication for both compute clients, got %d", n)
+	}
+
+	f.ExpireSessions()
+	authentications := f.Requests("POST", "/authenticate/")
+
+	// Concurrent requests which fail with the expired session start a single
+	// new session between them
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := opcClient.computeClient.SSHKeys().GetSSHKey(&compute.GetSSHKeyInput{Name: "missing"})
+			if !wasNotFoundError(err) {
+				errs <- fmt.Errorf("Expected a not found error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if err := opcClient.computeCollectionClient.list("/sshkey", &keys); err != nil {
+		t.Fatalf("Error listing SSH keys: %s", err)
+	}
+
+	if n := f.Requests("POST", "/authenticate/") - authentications; n != 1 {
+		t.Fatalf("Expected 1 authentication after the session expired, got %d", n)
+	}
+}
+
+func TestFakeComputeAPI_storageVolumeInUse(t *testing.T) {
+	f := newFakeComputeAPI("fakedomain", "user", "password")
+	defer f.Close()
+
+	config := Config{
+		User:           "user",
+		Password:       "password",
+		IdentityDomain: "fakedomain",
+		Endpoint:       f.URL,
+		MaxRetries:     1,
+	}
+	opcClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("Error authenticating: %s", err)
+	}
+
+	collectionClient := opcClient.computeCollectionClient
+	for path, body := range map[string]map[string]interface{}{
+		"/storage/volume/": {
+			"name": "/Compute-fakedomain/user/volume",
+			"size": "10G",
+		},
+		"/storage/attachment/": {
+			"storage_volume_name": "/Compute-fakedomain/user/volume",
+			"instance_name":       "/Compute-fakedomain/user/web/1234",
+			"index":               1,
+		},
+	} {
+		if err := collectionClient.do("POST", path, body, nil); err != nil {
+			t.Fatalf("Error creating %s: %s", path, err)
+		}
+	}
+
+	d := resourceOPCStorageVolume().TestResourceData()
+	d.SetId("volume")
+	err = resourceOPCStorageVolumeDelete(d, opcClient)
+	if err == nil {
+		t.Fatal("Expected deleting an attached storage volume to fail")
+	}
+	if !strings.Contains(err.Error(), "still attached to instance web/1234") {
+		t.Fatalf("Expected the error to name the instance the storage volume is attached to, got %s", err)
+	}
+	if !strings.Contains(err.Error(), "(HTTP 409, conflict)") {
+		t.Fatalf("Expected a conflict error, got %s", err)
+	}
+}
@@ -0,0 +1,565 @@
+package opc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/lbaas"
+	"github.com/hashicorp/go-oracle-terraform/opc"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+const fakeLBaaSContentTypePrefix = "application/vnd.com.oracle.oracloud.lbaas."
+
+// fakeLBaaSAPI is an in-process stand-in for the Load Balancer Classic REST
+// API. It serves load balancers, their listeners, origin server pools and
+// policies, and SSL certificates, enforcing the per resource
+// vnd.com.oracle.oracloud.lbaas.* content types.
+//
+// Creates, updates and deletes respond with the matching *_IN_PROGRESS state
+// and settle on a later GET, as the lbaas.*Client waiters expect. Certificates
+// are created synchronously, as the real service does. Fail injects one of the
+// *_FAILED (or any other) states into the next operation on a resource.
+type fakeLBaaSAPI struct {
+	*httptest.Server
+
+	User     string
+	Password string
+
+	// InProgressPolls is the number of GETs which still observe a resource
+	// in its in-progress state before it settles.
+	InProgressPolls int
+
+	mu       sync.Mutex
+	objects  map[string]map[string]interface{}
+	pending  map[string]*fakeLBaaSTransition
+	failures map[string]lbaas.LBaaSState
+	requests map[string]int
+	lastID   int
+}
+
+// fakeLBaaSTransition is the outcome of an in-progress operation, applied
+// once polls reaches zero. Resources settling in the DELETED state are
+// removed.
+type fakeLBaaSTransition struct {
+	polls int
+	state lbaas.LBaaSState
+}
+
+func newFakeLBaaSAPI(user, password string) *fakeLBaaSAPI {
+	f := &fakeLBaaSAPI{
+		User:     user,
+		Password: password,
+		objects:  make(map[string]map[string]interface{}),
+		pending:  make(map[string]*fakeLBaaSTransition),
+		failures: make(map[string]lbaas.LBaaSState),
+		requests: make(map[string]int),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// Fail makes the next operation on the named resource settle in the given
+// state, e.g. lbaas.LBaaSStateCreationFailed, instead of completing.
+func (f *fakeLBaaSAPI) Fail(name string, state lbaas.LBaaSState) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[name] = state
+}
+
+// Requests returns the number of requests received for the method and path.
+func (f *fakeLBaaSAPI) Requests(method, path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[method+" "+path]
+}
+
+// Object returns a copy of the stored object at path, without advancing any
+// pending transition.
+func (f *fakeLBaaSAPI) Object(path string) (map[string]interface{}, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[path]
+	if !ok {
+		return nil, false
+	}
+	return copyFakeObject(obj), true
+}
+
+func (f *fakeLBaaSAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	method := r.Method
+	if override := r.Header.Get("X-HTTP-Method-Override"); method == http.MethodPost && override != "" {
+		method = override
+	}
+	f.requests[method+" "+path]++
+
+	if user, password, ok := r.BasicAuth(); !ok || user != f.User || password != f.Password {
+		f.writeError(w, http.StatusUnauthorized, "Authentication failed")
+		return
+	}
+
+	var body map[string]interface{}
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.writeError(w, http.StatusBadRequest, fmt.Sprintf("Malformed request body: %s", err))
+			return
+		}
+	}
+
+	kind, container, ok := f.parsePath(path)
+	if !ok {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("No such resource %s", path))
+		return
+	}
+
+	// Child resources can only be addressed through an existing load balancer
+	if kind != "VLBR" && kind != "Certificate" {
+		parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 4)
+		if _, ok := f.objects["/"+strings.Join(parts[:3], "/")]; !ok {
+			f.writeError(w, http.StatusNotFound, fmt.Sprintf("Load balancer %s/%s not found", parts[1], parts[2]))
+			return
+		}
+	}
+
+	switch {
+	case container && method == http.MethodPost:
+		f.create(w, r, kind, path, body)
+	case container:
+		f.writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s not allowed on %s", method, path))
+	case method == http.MethodGet:
+		f.get(w, r, path)
+	case method == http.MethodPut, method == "PATCH":
+		f.update(w, r, path, body)
+	case method == http.MethodDelete:
+		f.delete(w, r, path)
+	default:
+		f.writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s not allowed on %s", method, path))
+	}
+}
+
+// parsePath returns the kind of resource addressed by path and whether the
+// path is the resource's collection.
+func (f *fakeLBaaSAPI) parsePath(path string) (string, bool, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	switch {
+	case parts[0] == "certs" && len(parts) <= 2:
+		return "Certificate", len(parts) == 1, true
+	case parts[0] != "vlbrs":
+		return "", false, false
+	case len(parts) == 1:
+		return "VLBR", true, true
+	case len(parts) == 3:
+		return "VLBR", false, true
+	case len(parts) < 3 || len(parts) > 5:
+		return "", false, false
+	}
+
+	kinds := map[string]string{
+		"listeners":         "Listener",
+		"originserverpools": "OriginServerPool",
+		"policies":          "Policy",
+	}
+	kind, ok := kinds[parts[3]]
+	return kind, len(parts) == 4, ok
+}
+
+// contentType returns the media type for a resource of the given kind.
+func (f *fakeLBaaSAPI) contentType(kind string, obj map[string]interface{}) string {
+	switch kind {
+	case "Certificate":
+		if trusted, _ := obj["trusted"].(bool); trusted {
+			return lbaas.ContentTypeTrustedCertificateJSON
+		}
+		return lbaas.ContentTypeServerCertificateJSON
+	case "Policy":
+		policyType, _ := obj["type"].(string)
+		return fmt.Sprintf("%s%s+json", fakeLBaaSContentTypePrefix, policyType)
+	}
+	return fmt.Sprintf("%s%s+json", fakeLBaaSContentTypePrefix, kind)
+}
+
+// negotiate checks the request's Content-Type and Accept headers against the
+// media type of the resource, writing the error response if they don't match.
+func (f *fakeLBaaSAPI) negotiate(w http.ResponseWriter, r *http.Request, contentType string, hasBody bool) bool {
+	if hasBody && r.Header.Get("Content-Type") != contentType {
+		f.writeError(w, http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported Content-Type %q, expected %q", r.Header.Get("Content-Type"), contentType))
+		return false
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if strings.TrimSpace(accept) == contentType {
+			return true
+		}
+	}
+	f.writeError(w, http.StatusNotAcceptable, fmt.Sprintf("Response type %q is not acceptable", contentType))
+	return false
+}
+
+func (f *fakeLBaaSAPI) create(w http.ResponseWriter, r *http.Request, kind, containerPath string, body map[string]interface{}) {
+	name, _ := body["name"].(string)
+	if name == "" {
+		f.writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	contentType := f.contentType(kind, body)
+	if !f.negotiate(w, r, contentType, true) {
+		return
+	}
+
+	path := fmt.Sprintf("%s/%s", containerPath, name)
+	if kind == "VLBR" {
+		region, _ := body["region"].(string)
+		path = fmt.Sprintf("%s/%s/%s", containerPath, region, name)
+	}
+	if _, ok := f.objects[path]; ok {
+		f.writeError(w, http.StatusConflict, fmt.Sprintf("%s %s already exists", kind, name))
+		return
+	}
+
+	obj := copyFakeObject(body)
+	delete(obj, "private_key")
+	obj["uri"] = f.URL + path
+	f.applyCreateDefaults(kind, path, obj)
+	f.objects[path] = obj
+
+	if kind == "Certificate" {
+		obj["state"] = f.settledState(name, lbaas.LBaaSStateCreated)
+	} else {
+		obj["state"] = lbaas.LBaaSStateCreationInProgress
+		f.pending[path] = &fakeLBaaSTransition{
+			polls: f.InProgressPolls,
+			state: f.settledState(name, lbaas.LBaaSStateHealthy),
+		}
+	}
+
+	f.writeJSON(w, contentType, http.StatusCreated, obj)
+}
+
+// settledState returns the state an operation on the named resource
+// completes in, consuming any injected failure.
+func (f *fakeLBaaSAPI) settledState(name string, state lbaas.LBaaSState) lbaas.LBaaSState {
+	if failure, ok := f.failures[name]; ok {
+		delete(f.failures, name)
+		return failure
+	}
+	return state
+}
+
+func (f *fakeLBaaSAPI) applyCreateDefaults(kind, path string, obj map[string]interface{}) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	name, _ := obj["name"].(string)
+
+	switch kind {
+	case "VLBR":
+		f.lastID++
+		region, _ := obj["region"].(string)
+		obj["canonical_host_name"] = fmt.Sprintf("%s-%s.balancer.oraclecloud.example.com", name, region)
+		obj["balancer_vips"] = []string{fmt.Sprintf("203.0.113.%d", f.lastID%250+1)}
+		obj["cloudgate_capable"] = "FALSE"
+		obj["compute_site"] = region
+		obj["created_on"] = now
+		obj["modified_on"] = now
+		obj["owner"] = f.User
+		obj["is_disabled_effectively"] = "FALSE"
+		if _, ok := obj["disabled"]; !ok || obj["disabled"] == "" {
+			obj["disabled"] = lbaas.LBaaSDisabledFalse
+		}
+	case "Listener":
+		if _, ok := obj["disabled"]; !ok {
+			obj["disabled"] = lbaas.LBaaSDisabledFalse
+		}
+		obj["effective_state"] = obj["disabled"]
+		obj["operation_details"] = ""
+	case "OriginServerPool":
+		if _, ok := obj["status"]; !ok {
+			obj["status"] = lbaas.LBaaSStatusEnabled
+		}
+		obj["consumers"] = ""
+		obj["operation_details"] = ""
+	}
+}
+
+func (f *fakeLBaaSAPI) get(w http.ResponseWriter, r *http.Request, path string) {
+	obj, ok := f.advance(path)
+	if !ok {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("No such resource %s", path))
+		return
+	}
+
+	kind, _, _ := f.parsePath(path)
+	contentType := f.contentType(kind, obj)
+	if !f.negotiate(w, r, contentType, false) {
+		return
+	}
+	f.writeJSON(w, contentType, http.StatusOK, obj)
+}
+
+// advance counts down the pending transition of the object at path, applying
+// it once the in-progress polls are exhausted.
+func (f *fakeLBaaSAPI) advance(path string) (map[string]interface{}, bool) {
+	obj, ok := f.objects[path]
+	if !ok {
+		return nil, false
+	}
+
+	if t, ok := f.pending[path]; ok {
+		if t.polls > 0 {
+			t.polls--
+			return obj, true
+		}
+		delete(f.pending, path)
+		if t.state == lbaas.LBaaSStateDeleted {
+			f.remove(path)
+			return nil, false
+		}
+		obj["state"] = t.state
+	}
+	return obj, true
+}
+
+func (f *fakeLBaaSAPI) update(w http.ResponseWriter, r *http.Request, path string, body map[string]interface{}) {
+	obj, ok := f.objects[path]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("No such resource %s", path))
+		return
+	}
+	if _, ok := f.pending[path]; ok {
+		f.writeError(w, http.StatusConflict, fmt.Sprintf("%s is in state %s", path, obj["state"]))
+		return
+	}
+
+	kind, _, _ := f.parsePath(path)
+	contentType := f.contentType(kind, obj)
+	if !f.negotiate(w, r, contentType, true) {
+		return
+	}
+
+	for k, v := range body {
+		if k == "private_key" || k == "type" || k == "region" {
+			continue
+		}
+		obj[k] = v
+	}
+	if kind == "VLBR" {
+		obj["modified_on"] = time.Now().UTC().Format(time.RFC3339)
+	}
+	if kind == "Listener" {
+		obj["effective_state"] = obj["disabled"]
+	}
+
+	name, _ := obj["name"].(string)
+	obj["state"] = lbaas.LBaaSStateModificationInProgress
+	f.pending[path] = &fakeLBaaSTransition{
+		polls: f.InProgressPolls,
+		state: f.settledState(name, lbaas.LBaaSStateHealthy),
+	}
+
+	f.writeJSON(w, contentType, http.StatusOK, obj)
+}
+
+func (f *fakeLBaaSAPI) delete(w http.ResponseWriter, r *http.Request, path string) {
+	obj, ok := f.objects[path]
+	if !ok {
+		f.writeError(w, http.StatusNotFound, fmt.Sprintf("No such resource %s", path))
+		return
+	}
+
+	kind, _, _ := f.parsePath(path)
+	contentType := f.contentType(kind, obj)
+	if !f.negotiate(w, r, contentType, false) {
+		return
+	}
+
+	name, _ := obj["name"].(string)
+	obj["state"] = lbaas.LBaaSStateDeletionInProgress
+	f.pending[path] = &fakeLBaaSTransition{
+		polls: f.InProgressPolls,
+		state: f.settledState(name, lbaas.LBaaSStateDeleted),
+	}
+
+	f.writeJSON(w, contentType, http.StatusOK, obj)
+}
+
+// remove deletes the object at path along with any child resources.
+func (f *fakeLBaaSAPI) remove(path string) {
+	for p := range f.objects {
+		if p == path || strings.HasPrefix(p, path+"/") {
+			delete(f.objects, p)
+			delete(f.pending, p)
+		}
+	}
+}
+
+func (f *fakeLBaaSAPI) writeJSON(w http.ResponseWriter, contentType string, status int, body interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (f *fakeLBaaSAPI) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"detail": message})
+}
+
+func testAccFakeLBaaSClient(t *testing.T, f *fakeLBaaSAPI) *lbaas.Client {
+	config := Config{
+		User:          f.User,
+		Password:      f.Password,
+		LBaaSEndpoint: f.URL,
+		MaxRetries:    1,
+	}
+	opcClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("Error creating the LBaaS client: %s", err)
+	}
+	opcClient.lbaasClient.PollInterval = 10 * time.Millisecond
+	opcClient.lbaasClient.Timeout = time.Second
+	return opcClient.lbaasClient
+}
+
+func TestFakeLBaaSAPI_stateTransitions(t *testing.T) {
+	f := newFakeLBaaSAPI("user", "password")
+	defer f.Close()
+	f.InProgressPolls = 2
+	lbaasClient := testAccFakeLBaaSClient(t, f)
+
+	lb := lbaas.LoadBalancerContext{Region: "uscom-central-1", Name: "fake-lb"}
+	lbClient := lbaasClient.LoadBalancerClient()
+	if _, err := lbClient.CreateLoadBalancer(&lbaas.CreateLoadBalancerInput{
+		Name:     lb.Name,
+		Region:   lb.Region,
+		Scheme:   lbaas.LoadBalancerSchemeInternetFacing,
+		Disabled: lbaas.LBaaSDisabledFalse,
+	}); err != nil {
+		t.Fatalf("Error creating load balancer: %s", err)
+	}
+	if n := f.Requests(http.MethodGet, "/vlbrs/uscom-central-1/fake-lb"); n != 3 {
+		t.Fatalf("Expected 3 polls of the load balancer, got %d", n)
+	}
+	info, err := lbClient.GetLoadBalancer(lb)
+	if err != nil {
+		t.Fatalf("Error reading load balancer: %s", err)
+	}
+	if info.State != lbaas.LBaaSStateHealthy {
+		t.Fatalf("Expected load balancer to be %s, got %s", lbaas.LBaaSStateHealthy, info.State)
+	}
+
+	policyClient := lbaasClient.PolicyClient()
+	if _, err := policyClient.CreatePolicy(lb, &lbaas.CreatePolicyInput{
+		Name: "fake-policy",
+		Type: "LoadBalancingMechanismPolicy",
+		LoadBalancingMechanismPolicyInfo: lbaas.LoadBalancingMechanismPolicyInfo{
+			LoadBalancingMechanism: "round_robin",
+		},
+	}); err != nil {
+		t.Fatalf("Error creating policy: %s", err)
+	}
+
+	listenerClient := lbaasClient.ListenerClient()
+	f.Fail("fake-listener", lbaas.LBaaSStateCreationFailed)
+	if _, err := listenerClient.CreateListener(lb, &lbaas.CreateListenerInput{
+		Name:                 "fake-listener",
+		BalancerProtocol:     lbaas.ProtocolHTTP,
+		OriginServerProtocol: lbaas.ProtocolHTTP,
+		Port:                 8080,
+	}); err == nil || !strings.Contains(err.Error(), string(lbaas.LBaaSStateCreationFailed)) {
+		t.Fatalf("Expected listener creation to fail with %s, got %v", lbaas.LBaaSStateCreationFailed, err)
+	}
+
+	f.Fail("fake-policy", lbaas.LBaaSStateModificaitonFailed)
+	if _, err := policyClient.UpdatePolicy(lb, "fake-policy", "LoadBalancingMechanismPolicy", &lbaas.UpdatePolicyInput{
+		Name: "fake-policy",
+		Type: "LoadBalancingMechanismPolicy",
+	}); err == nil || !strings.Contains(err.Error(), string(lbaas.LBaaSStateModificaitonFailed)) {
+		t.Fatalf("Expected policy update to fail with %s, got %v", lbaas.LBaaSStateModificaitonFailed, err)
+	}
+
+	if _, err := lbClient.DeleteLoadBalancer(lb); err != nil {
+		t.Fatalf("Error deleting load balancer: %s", err)
+	}
+	if _, err := policyClient.GetPolicy(lb, "fake-policy"); !wasNotFoundError(err) {
+		t.Fatalf("Expected the policy to be deleted with its load balancer, got %v", err)
+	}
+}
+
+func TestFakeLBaaSAPI_contentTypes(t *testing.T) {
+	f := newFakeLBaaSAPI("user", "password")
+	defer f.Close()
+	lbaasClient := testAccFakeLBaaSClient(t, f)
+
+	lb := lbaas.LoadBalancerContext{Region: "uscom-central-1", Name: "fake-lb"}
+	if _, err := lbaasClient.LoadBalancerClient().CreateLoadBalancer(&lbaas.CreateLoadBalancerInput{
+		Name:   lb.Name,
+		Region: lb.Region,
+		Scheme: lbaas.LoadBalancerSchemeInternal,
+	}); err != nil {
+		t.Fatalf("Error creating load balancer: %s", err)
+	}
+
+	// Policies must be sent with the content type of their policy type
+	req, err := http.NewRequest(http.MethodPost, f.URL+"/vlbrs/uscom-central-1/fake-lb/policies", strings.NewReader(`{"name":"redirect","type":"RedirectPolicy"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.SetBasicAuth(f.User, f.Password)
+	req.Header.Set("Content-Type", lbaas.ContentTypeSetRequestHeaderPolicyJSON)
+	req.Header.Set("Accept", lbaas.ContentTypeRedirectPolicyJSON)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("Expected a %d response, got %d", http.StatusUnsupportedMediaType, resp.StatusCode)
+	}
+
+	certClient := lbaasClient.SSLCertificateClient()
+	certClient.Accept = lbaas.ContentTypeServerCertificateJSON
+	_, err = certClient.CreateSSLCertificate(&lbaas.CreateSSLCertificateInput{
+		Name:        "trusted",
+		Certificate: "-----BEGIN CERTIFICATE-----",
+		Trusted:     true,
+	})
+	if oracleErr, ok := err.(*opc.OracleError); !ok || oracleErr.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("Expected a %d error, got %v", http.StatusNotAcceptable, err)
+	}
+}
+
+func TestFakeLBaaSAPI_certificateCreationFailed(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	testAccFakeAPIUnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: opcResourceCheck("opc_lbaas_certificate.server-cert", testAccLBaaSCheckCertificateDestroyed),
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					testAccFakeLBaaSAPI.Fail(fmt.Sprintf("acctest-%d", rInt), lbaas.LBaaSStateCreationFailed)
+				},
+				Config:      testAccLBaaSCertificateConfig_ServerCertificate(rInt),
+				ExpectError: regexp.MustCompile("errored state CREATION_FAILED"),
+			},
+			{
+				Config: testAccLBaaSCertificateConfig_ServerCertificate(rInt + 1),
+				Check: resource.ComposeTestCheckFunc(
+					opcResourceCheck("opc_lbaas_certificate.server-cert", testAccLBaaSCheckCertificateExists),
+					resource.TestCheckResourceAttr("opc_lbaas_certificate.server-cert", "type", "SERVER"),
+					resource.TestCheckResourceAttr("opc_lbaas_certificate.server-cert", "state", "CREATED"),
+				),
+			},
+		},
+	})
+}
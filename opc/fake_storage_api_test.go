@@ -0,0 +1,993 @@
+package opc
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/storage"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const (
+	fakeStorageContainerMetaPrefix       = "X-Container-Meta-"
+	fakeStorageRemoveContainerMetaPrefix = "X-Remove-Container-Meta-"
+	fakeStorageObjectMetaPrefix          = "X-Object-Meta-"
+	fakeStorageRemoveObjectMetaPrefix    = "X-Remove-Object-Meta-"
+	fakeStorageDefaultContentType        = "application/octet-stream"
+)
+
+// fakeStorageAPI is an in-memory emulator of the Swift-style Object Storage
+// Classic API used by storage.Client. It implements v1.0 authentication,
+// container ACLs, metadata and quotas, and object metadata, copies, expiry
+// and dynamic and static large object manifests.
+type fakeStorageAPI struct {
+	*httptest.Server
+
+	IdentityDomain string
+	User           string
+	Password       string
+
+	mu         sync.Mutex
+	tokens     map[string]bool
+	containers map[string]*fakeStorageContainer
+	requests   map[string]int
+	failPuts   int
+	lastID     int
+	now        func() time.Time
+}
+
+type fakeStorageContainer struct {
+	headers http.Header
+	objects map[string]*fakeStorageObject
+}
+
+type fakeStorageObject struct {
+	headers      http.Header
+	content      []byte
+	lastModified time.Time
+
+	// segments are the container/object paths of the segments of a static
+	// large object
+	segments []string
+}
+
+// fakeStorageManifestSegment is an entry of a static large object manifest,
+// as it is PUT.
+type fakeStorageManifestSegment struct {
+	Path      string `json:"path"`
+	ETag      string `json:"etag"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+func newFakeStorageAPI(identityDomain, user, password string) *fakeStorageAPI {
+	f := &fakeStorageAPI{
+		IdentityDomain: identityDomain,
+		User:           user,
+		Password:       password,
+		tokens:         make(map[string]bool),
+		containers:     make(map[string]*fakeStorageContainer),
+		requests:       make(map[string]int),
+		now:            time.Now,
+	}
+	// Every account is provisioned with the container machine images are
+	// uploaded to
+	f.containers["compute_images"] = &fakeStorageContainer{
+		headers: make(http.Header),
+		objects: make(map[string]*fakeStorageObject),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// ExpireTokens invalidates every authentication token issued so far.
+func (f *fakeStorageAPI) ExpireTokens() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = make(map[string]bool)
+}
+
+// Authentications returns the number of authentication requests received.
+func (f *fakeStorageAPI) Authentications() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests["GET /auth/v1.0"]
+}
+
+// FailObjectPuts makes the next n object PUTs fail with a 503.
+func (f *fakeStorageAPI) FailObjectPuts(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failPuts = n
+}
+
+// ObjectContent returns the content of an object as it would be downloaded,
+// resolving large object manifests.
+func (f *fakeStorageAPI) ObjectContent(container, name string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.lookupObject(container, name)
+	if !ok {
+		return nil, false
+	}
+	return f.resolveContent(obj), true
+}
+
+// ObjectNames returns the names of the objects in a container, in order.
+func (f *fakeStorageAPI) ObjectNames(container string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.containers[container]
+	if !ok {
+		return nil
+	}
+	return f.sortedObjectNames(c, "")
+}
+
+// Requests returns the number of requests received for the given method and
+// object, e.g. ("DELETE", "container/object").
+func (f *fakeStorageAPI) Requests(method, name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[fmt.Sprintf("%s /v1/%s/%s", method, f.account(), name)]
+}
+
+func (f *fakeStorageAPI) account() string {
+	return fmt.Sprintf("Storage-%s", f.IdentityDomain)
+}
+
+func (f *fakeStorageAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[r.Method+" "+r.URL.Path]++
+	f.lastID++
+	w.Header().Set("X-Trans-Id", fmt.Sprintf("tx%021x", f.lastID))
+	w.Header().Set("Date", f.now().UTC().Format(http.TimeFormat))
+
+	if r.URL.Path == "/auth/v1.0" {
+		f.authenticate(w, r)
+		return
+	}
+
+	if !f.tokens[r.Header.Get("X-Auth-Token")] {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Paths are /v1/{account}/{container}[/{object}]
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 4)
+	if len(parts) < 3 || parts[0] != "v1" || parts[1] != f.account() || parts[2] == "" {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	container := parts[2]
+	if len(parts) == 3 {
+		f.serveContainer(w, r, container)
+		return
+	}
+	f.serveObject(w, r, container, parts[3])
+}
+
+func (f *fakeStorageAPI) authenticate(w http.ResponseWriter, r *http.Request) {
+	user := fmt.Sprintf("%s:%s", f.account(), f.User)
+	if r.Header.Get("X-Storage-User") != user || r.Header.Get("X-Storage-Pass") != f.Password {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token := fmt.Sprintf("AUTH_tk%032x", f.lastID)
+	f.tokens[token] = true
+	w.Header().Set("X-Auth-Token", token)
+	w.Header().Set("X-Storage-Token", token)
+	w.Header().Set("X-Storage-Url", fmt.Sprintf("%s/v1/%s", f.URL, f.account()))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (f *fakeStorageAPI) serveContainer(w http.ResponseWriter, r *http.Request, name string) {
+	c, exists := f.containers[name]
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		if !exists {
+			if r.Method == http.MethodPost {
+				http.Error(w, "Not Found", http.StatusNotFound)
+				return
+			}
+			c = &fakeStorageContainer{
+				headers: http.Header{
+					"X-Timestamp": {f.timestamp()},
+				},
+				objects: make(map[string]*fakeStorageObject),
+			}
+			f.containers[name] = c
+		}
+		for k, v := range r.Header {
+			switch {
+			case strings.HasPrefix(k, fakeStorageRemoveContainerMetaPrefix):
+				c.headers.Del(fakeStorageContainerMetaPrefix + strings.TrimPrefix(k, fakeStorageRemoveContainerMetaPrefix))
+			case strings.HasPrefix(k, fakeStorageContainerMetaPrefix), k == "X-Container-Read", k == "X-Container-Write":
+				if v[0] == "" {
+					// An empty value removes the metadata item
+					c.headers.Del(k)
+				} else {
+					c.headers[k] = v
+				}
+			}
+		}
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodGet, http.MethodHead:
+		if !exists {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		for k, v := range c.headers {
+			w.Header()[k] = v
+		}
+		names := f.sortedObjectNames(c, r.URL.Query().Get("prefix"))
+		w.Header().Set("X-Container-Object-Count", strconv.Itoa(len(names)))
+		if marker := r.URL.Query().Get("marker"); marker != "" {
+			i := sort.SearchStrings(names, marker)
+			for i < len(names) && names[i] <= marker {
+				i++
+			}
+			names = names[i:]
+		}
+		w.Header().Set("X-Container-Bytes-Used", strconv.Itoa(f.bytesUsed(c)))
+		if len(names) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			fmt.Fprintln(w, strings.Join(names, "\n"))
+		}
+	case http.MethodDelete:
+		if !exists {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if len(f.sortedObjectNames(c, "")) > 0 {
+			http.Error(w, "There was a conflict when trying to complete your request.", http.StatusConflict)
+			return
+		}
+		delete(f.containers, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeStorageAPI) serveObject(w http.ResponseWriter, r *http.Request, container, name string) {
+	c, ok := f.containers[container]
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if f.failPuts > 0 {
+			f.failPuts--
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("multipart-manifest") == "put" {
+			f.putStaticLargeObject(w, r, c, name)
+			return
+		}
+		f.putObject(w, r, c, name)
+	case http.MethodPost:
+		obj, ok := f.lookupObject(container, name)
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		// A POST replaces all of the object's metadata
+		for k := range obj.headers {
+			if strings.HasPrefix(k, fakeStorageObjectMetaPrefix) || k == "X-Delete-At" || k == "Content-Disposition" || k == "Content-Encoding" {
+				obj.headers.Del(k)
+			}
+		}
+		if err := f.applyObjectHeaders(obj, r.Header); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodGet, http.MethodHead:
+		obj, ok := f.lookupObject(container, name)
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("multipart-manifest") == "get" && obj.segments != nil {
+			f.getStaticLargeObjectManifest(w, obj)
+			return
+		}
+		content := f.resolveContent(obj)
+		for k, v := range obj.headers {
+			w.Header()[k] = v
+		}
+		if etag := f.largeObjectETag(obj); etag != "" {
+			w.Header().Set("Etag", etag)
+		}
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Last-Modified", obj.lastModified.UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+	case http.MethodDelete:
+		if _, ok := f.lookupObject(container, name); !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		delete(c.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeStorageAPI) putObject(w http.ResponseWriter, r *http.Request, c *fakeStorageContainer, name string) {
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	obj := &fakeStorageObject{
+		headers: http.Header{
+			"Content-Type": {fakeStorageDefaultContentType},
+		},
+		content: content,
+	}
+
+	if source := r.Header.Get("X-Copy-From"); source != "" {
+		sourcePath, _ := url.PathUnescape(strings.TrimPrefix(source, "/"))
+		parts := strings.SplitN(sourcePath, "/", 2)
+		if len(parts) != 2 {
+			http.Error(w, "X-Copy-From header must be of the form <container name>/<object name>", http.StatusPreconditionFailed)
+			return
+		}
+		src, ok := f.lookupObject(parts[0], parts[1])
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		// Copies take the content and metadata of the source, the request
+		// headers override any of the copied metadata.
+		obj.content = f.resolveContent(src)
+		for k, v := range src.headers {
+			if k != "X-Object-Manifest" && k != "X-Static-Large-Object" && k != "X-Delete-At" {
+				obj.headers[k] = v
+			}
+		}
+	}
+
+	if etag := r.Header.Get("Etag"); etag != "" && etag != fakeStorageETag(obj.content) && r.Header.Get("X-Object-Manifest") == "" {
+		http.Error(w, "Unprocessable Entity", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := f.checkQuota(c, name, len(obj.content)); err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := f.applyObjectHeaders(obj, r.Header); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	obj.headers.Set("Etag", fakeStorageETag(obj.content))
+	if v := r.Header.Get("X-Object-Manifest"); v != "" {
+		obj.headers.Set("X-Object-Manifest", v)
+	}
+	obj.headers.Set("X-Timestamp", f.timestamp())
+	obj.lastModified = f.now()
+	c.objects[name] = obj
+
+	w.Header().Set("Etag", obj.headers.Get("Etag"))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// putStaticLargeObject stores a static large object manifest, checking that
+// each of the segments exists with the given etag and size.
+func (f *fakeStorageAPI) putStaticLargeObject(w http.ResponseWriter, r *http.Request, c *fakeStorageContainer, name string) {
+	var manifest []fakeStorageManifestSegment
+	if err := json.NewDecoder(r.Body).Decode(&manifest); err != nil || len(manifest) == 0 {
+		http.Error(w, "Manifest must be valid JSON.", http.StatusBadRequest)
+		return
+	}
+
+	obj := &fakeStorageObject{
+		headers: http.Header{
+			"Content-Type":          {fakeStorageDefaultContentType},
+			"X-Static-Large-Object": {"True"},
+		},
+		segments: make([]string, 0, len(manifest)),
+	}
+	for _, entry := range manifest {
+		path := strings.TrimPrefix(entry.Path, "/")
+		parts := strings.SplitN(path, "/", 2)
+		if len(parts) != 2 {
+			http.Error(w, fmt.Sprintf("Invalid segment path %s", entry.Path), http.StatusBadRequest)
+			return
+		}
+		segment, ok := f.lookupObject(parts[0], parts[1])
+		if !ok {
+			http.Error(w, fmt.Sprintf("%s: 404 Not Found", entry.Path), http.StatusBadRequest)
+			return
+		}
+		if entry.ETag != "" && entry.ETag != segment.headers.Get("Etag") {
+			http.Error(w, fmt.Sprintf("%s: Etag Mismatch", entry.Path), http.StatusBadRequest)
+			return
+		}
+		if entry.SizeBytes != 0 && entry.SizeBytes != int64(len(segment.content)) {
+			http.Error(w, fmt.Sprintf("%s: Size Mismatch", entry.Path), http.StatusBadRequest)
+			return
+		}
+		obj.segments = append(obj.segments, path)
+	}
+
+	if err := f.applyObjectHeaders(obj, r.Header); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	obj.headers.Set("X-Timestamp", f.timestamp())
+	obj.lastModified = f.now()
+	c.objects[name] = obj
+
+	w.Header().Set("Etag", f.largeObjectETag(obj))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// getStaticLargeObjectManifest writes the manifest of a static large object,
+// as it is returned for a GET with ?multipart-manifest=get.
+func (f *fakeStorageAPI) getStaticLargeObjectManifest(w http.ResponseWriter, obj *fakeStorageObject) {
+	type entry struct {
+		Name  string `json:"name"`
+		Hash  string `json:"hash"`
+		Bytes int    `json:"bytes"`
+	}
+	manifest := make([]entry, 0, len(obj.segments))
+	for _, path := range obj.segments {
+		e := entry{Name: "/" + path}
+		parts := strings.SplitN(path, "/", 2)
+		if segment, ok := f.lookupObject(parts[0], parts[1]); ok {
+			e.Hash = segment.headers.Get("Etag")
+			e.Bytes = len(segment.content)
+		}
+		manifest = append(manifest, e)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(manifest)
+}
+
+// largeObjectETag returns the etag of a dynamic or static large object, the
+// quoted MD5 checksum of the etags of its segments, or "" for other objects.
+func (f *fakeStorageAPI) largeObjectETag(obj *fakeStorageObject) string {
+	if obj.segments == nil && obj.headers.Get("X-Object-Manifest") == "" {
+		return ""
+	}
+	var etags bytes.Buffer
+	for _, segment := range f.largeObjectSegments(obj) {
+		etags.WriteString(segment.headers.Get("Etag"))
+	}
+	return fmt.Sprintf("%q", fakeStorageETag(etags.Bytes()))
+}
+
+// largeObjectSegments returns the segments of a dynamic or static large
+// object, in order.
+func (f *fakeStorageAPI) largeObjectSegments(obj *fakeStorageObject) []*fakeStorageObject {
+	var segments []*fakeStorageObject
+	if obj.segments != nil {
+		for _, path := range obj.segments {
+			parts := strings.SplitN(path, "/", 2)
+			if segment, ok := f.lookupObject(parts[0], parts[1]); ok {
+				segments = append(segments, segment)
+			}
+		}
+		return segments
+	}
+
+	manifest := obj.headers.Get("X-Object-Manifest")
+	if manifest == "" {
+		return nil
+	}
+	parts := strings.SplitN(manifest, "/", 2)
+	c, ok := f.containers[parts[0]]
+	if !ok || len(parts) != 2 {
+		return nil
+	}
+	for _, name := range f.sortedObjectNames(c, parts[1]) {
+		if segment, ok := f.lookupObject(parts[0], name); ok {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// applyObjectHeaders stores the user settable headers of an object.
+func (f *fakeStorageAPI) applyObjectHeaders(obj *fakeStorageObject, headers http.Header) error {
+	for k, v := range headers {
+		switch {
+		case strings.HasPrefix(k, fakeStorageRemoveObjectMetaPrefix):
+			obj.headers.Del(fakeStorageObjectMetaPrefix + strings.TrimPrefix(k, fakeStorageRemoveObjectMetaPrefix))
+		case strings.HasPrefix(k, fakeStorageObjectMetaPrefix):
+			obj.headers[k] = v
+		case k == "Content-Type" && v[0] != "", k == "Content-Disposition", k == "Content-Encoding":
+			obj.headers[k] = v
+		case k == "X-Delete-At":
+			deleteAt, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Non-integer X-Delete-At")
+			}
+			if deleteAt <= f.now().Unix() {
+				return fmt.Errorf("X-Delete-At in past")
+			}
+			obj.headers[k] = v
+		case k == "X-Delete-After":
+			seconds, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("Non-integer X-Delete-After")
+			}
+			obj.headers.Set("X-Delete-At", strconv.FormatInt(f.now().Unix()+seconds, 10))
+		}
+	}
+	return nil
+}
+
+// lookupObject returns an object, treating objects past their X-Delete-At
+// time as deleted.
+func (f *fakeStorageAPI) lookupObject(container, name string) (*fakeStorageObject, bool) {
+	c, ok := f.containers[container]
+	if !ok {
+		return nil, false
+	}
+	obj, ok := c.objects[name]
+	if !ok {
+		return nil, false
+	}
+	if v := obj.headers.Get("X-Delete-At"); v != "" {
+		if deleteAt, err := strconv.ParseInt(v, 10, 64); err == nil && deleteAt <= f.now().Unix() {
+			delete(c.objects, name)
+			return nil, false
+		}
+	}
+	return obj, true
+}
+
+// resolveContent returns the content of an object, concatenating the segments
+// referenced by a large object manifest.
+func (f *fakeStorageAPI) resolveContent(obj *fakeStorageObject) []byte {
+	if obj.segments == nil && obj.headers.Get("X-Object-Manifest") == "" {
+		return obj.content
+	}
+
+	var buf bytes.Buffer
+	for _, segment := range f.largeObjectSegments(obj) {
+		buf.Write(segment.content)
+	}
+	return buf.Bytes()
+}
+
+func (f *fakeStorageAPI) checkQuota(c *fakeStorageContainer, name string, size int) error {
+	used := f.bytesUsed(c)
+	count := len(f.sortedObjectNames(c, ""))
+	if existing, ok := c.objects[name]; ok {
+		used -= len(existing.content)
+		count--
+	}
+
+	if v := c.headers.Get("X-Container-Meta-Quota-Bytes"); v != "" {
+		if quota, err := strconv.Atoi(v); err == nil && used+size > quota {
+			return fmt.Errorf("Upload exceeds quota.")
+		}
+	}
+	if v := c.headers.Get("X-Container-Meta-Quota-Count"); v != "" {
+		if quota, err := strconv.Atoi(v); err == nil && count+1 > quota {
+			return fmt.Errorf("Upload exceeds quota.")
+		}
+	}
+	return nil
+}
+
+func (f *fakeStorageAPI) bytesUsed(c *fakeStorageContainer) int {
+	used := 0
+	for _, obj := range c.objects {
+		used += len(obj.content)
+	}
+	return used
+}
+
+func (f *fakeStorageAPI) sortedObjectNames(c *fakeStorageContainer, prefix string) []string {
+	names := make([]string, 0, len(c.objects))
+	for name, obj := range c.objects {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if v := obj.headers.Get("X-Delete-At"); v != "" {
+			if deleteAt, err := strconv.ParseInt(v, 10, 64); err == nil && deleteAt <= f.now().Unix() {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fakeStorageAPI) timestamp() string {
+	return fmt.Sprintf("%d.%05d", f.now().Unix(), f.lastID)
+}
+
+func fakeStorageETag(content []byte) string {
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFakeStorageAPI_containerAndObjects(t *testing.T) {
+	rInt := acctest.RandInt()
+	containerName := fmt.Sprintf("fake-container-%d", rInt)
+
+	testAccFakeAPIUnitTest(t, resource.TestCase{
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckStorageObjectDestroy,
+			testAccCheckStorageContainerDestroy,
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccFakeStorageAPIResources(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageContainerExists,
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr("opc_storage_container.test", "read_acls.#", "1"),
+					resource.TestCheckResourceAttr("opc_storage_container.test", "quota_count", "10"),
+					resource.TestCheckResourceAttr("opc_storage_container.test", "metadata.Foo", "bar"),
+					resource.TestCheckResourceAttr("opc_storage_object.test", "content_length", "11"),
+					resource.TestCheckResourceAttr("opc_storage_object.test", "etag", fakeStorageETag([]byte("hello world"))),
+					resource.TestCheckResourceAttr("opc_storage_object.test", "metadata.Abc-Def", "xyz"),
+					resource.TestCheckResourceAttr("opc_storage_object.copy", "content_length", "11"),
+					resource.TestCheckResourceAttr("opc_storage_object.copy", "metadata.Abc-Def", "xyz"),
+					func(s *terraform.State) error {
+						content, ok := testAccFakeStorageAPI.ObjectContent(containerName, "copy")
+						if !ok || string(content) != "hello world" {
+							return fmt.Errorf("Expected copied object content %q, got %q", "hello world", content)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccFakeStorageAPIResources(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_storage_container" "test" {
+  name        = "fake-container-%d"
+  read_acls   = [".r:*"]
+  quota_count = 10
+  metadata = {
+    Foo = "bar"
+  }
+}
+
+resource "opc_storage_object" "test" {
+  name         = "object"
+  container    = "${opc_storage_container.test.name}"
+  content      = "hello world"
+  content_type = "text/plain;charset=UTF-8"
+  metadata = {
+    Abc-Def = "xyz"
+  }
+}
+
+resource "opc_storage_object" "copy" {
+  name      = "copy"
+  container = "${opc_storage_container.test.name}"
+  copy_from = "${opc_storage_container.test.name}/${opc_storage_object.test.name}"
+}
+`, rInt)
+}
+
+func TestFakeStorageAPI_quotaAndExpiry(t *testing.T) {
+	f := newFakeStorageAPI("fakedomain", "user", "password")
+	defer f.Close()
+
+	config := Config{
+		User:            "user",
+		Password:        "password",
+		IdentityDomain:  "fakedomain",
+		StorageEndpoint: f.URL,
+		MaxRetries:      1,
+	}
+	opcClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("Error authenticating: %s", err)
+	}
+	storageClient := opcClient.storageClient
+
+	if _, err := storageClient.CreateContainer(&storage.CreateContainerInput{Name: "quota", QuotaCount: 1}); err != nil {
+		t.Fatalf("Error creating container: %s", err)
+	}
+
+	objects := storageClient.Objects()
+	for i, name := range []string{"first", "second"} {
+		_, err := objects.CreateObject(&storage.CreateObjectInput{
+			Name:      name,
+			Container: "quota",
+			Body:      bytes.NewReader([]byte(name)),
+			DeleteAt:  int(time.Now().Unix()) + 60,
+		})
+		if i == 0 && err != nil {
+			t.Fatalf("Error creating object: %s", err)
+		}
+		if i == 1 && err == nil {
+			t.Fatal("Expected the container object count quota to be enforced")
+		}
+	}
+
+	f.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := objects.GetObject(&storage.GetObjectInput{Name: "first", Container: "quota"}); err == nil {
+		t.Fatal("Expected the object to have expired")
+	}
+}
+
+func TestFakeStorageAPI_objectUpdatedInPlace(t *testing.T) {
+	rInt := acctest.RandInt()
+	objectName := fmt.Sprintf("acc-test-%d/test-acc-%d", rInt, rInt)
+
+	dir, err := ioutil.TempDir("", "opc-storage-object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("same content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testAccFakeAPIUnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_objectMetadata(rInt, "initial"),
+				Check:  testAccCheckFakeStorageRequests(objectName, 1, 0),
+			},
+			{
+				// Metadata changes are POSTed
+				Config: testAccOPCStorageObject_objectMetadataUpdated(rInt, "initial"),
+				Check:  testAccCheckFakeStorageRequests(objectName, 1, 1),
+			},
+			{
+				// Content changes are PUT to the same object
+				Config: testAccOPCStorageObject_objectMetadataUpdated(rInt, "updated"),
+				Check:  testAccCheckFakeStorageRequests(objectName, 2, 1),
+			},
+			{
+				Config: testAccOPCStorageObject_fileSource(rInt, filepath.Join(dir, "a.txt")),
+				Check:  testAccCheckFakeStorageRequests(objectName, 3, 1),
+			},
+			{
+				// A different file with the same contents is not uploaded
+				Config: testAccOPCStorageObject_fileSource(rInt, filepath.Join(dir, "b.txt")),
+				Check:  testAccCheckFakeStorageRequests(objectName, 3, 1),
+			},
+		},
+	})
+}
+
+func TestFakeStorageAPI_segmentUploadRetried(t *testing.T) {
+	rInt := acctest.RandInt()
+	segmentSize := 1024
+
+	dir, err := ioutil.TempDir("", "opc-storage-object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "large.bin")
+	if err := testAccWriteStorageObjectFile(path, 10*segmentSize+1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	testAccFakeAPIUnitTest(t, resource.TestCase{
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The first attempts at uploading two of the segments fail,
+				// and are retried by the provider's retry policy
+				PreConfig: func() { testAccFakeStorageAPI.FailObjectPuts(2) },
+				Config: `
+provider "opc" {
+  max_retries = 3
+  retry {
+    max_backoff = "10ms"
+  }
+}
+` + testAccOPCStorageObject_segmented(rInt, path, segmentSize, storageLargeObjectTypeStatic),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFakeStorageObjectContent(fmt.Sprintf("acc-test-%d", rInt), fmt.Sprintf("test-acc-%d", rInt), path),
+					testAccCheckStorageObjectSegments(fmt.Sprintf("acc-test-%d-segments", rInt), fmt.Sprintf("test-acc-%d/", rInt), 11),
+				),
+			},
+		},
+	})
+}
+
+func TestFakeStorageAPI_segmentUploadFailed(t *testing.T) {
+	f := newFakeStorageAPI("fakedomain", "user", "password")
+	defer f.Close()
+
+	config := Config{
+		User:            "user",
+		Password:        "password",
+		IdentityDomain:  "fakedomain",
+		StorageEndpoint: f.URL,
+		MaxRetries:      1,
+	}
+	opcClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("Error authenticating: %s", err)
+	}
+	for _, container := range []string{"test", "test_segments"} {
+		if err := opcClient.storageObjectClient.createContainer(container); err != nil {
+			t.Fatalf("Error creating container: %s", err)
+		}
+	}
+
+	// The first failed segment cancels the uploads of the others
+	count := 100
+	content := strings.Repeat("x", count*16)
+	f.FailObjectPuts(count)
+	err = opcClient.storageObjectClient.uploadLargeObject(context.Background(), &largeObjectUploadInput{
+		Container:        "test",
+		Name:             "object",
+		SegmentContainer: "test_segments",
+		SegmentSize:      16,
+		File:             strings.NewReader(content),
+		Size:             int64(len(content)),
+	})
+	if err == nil {
+		t.Fatal("Expected the upload to fail")
+	}
+
+	puts := 0
+	f.mu.Lock()
+	for k, n := range f.requests {
+		if strings.HasPrefix(k, "PUT /v1/Storage-fakedomain/test_segments/object/") {
+			puts += n
+		}
+	}
+	f.mu.Unlock()
+	if puts >= count {
+		t.Fatalf("Expected the upload to stop after the first failed segment, got %d segment uploads", puts)
+	}
+	if names := f.ObjectNames("test_segments"); len(names) != 0 {
+		t.Fatalf("Expected the segments to be deleted, got %v", names)
+	}
+}
+
+func TestFakeStorageAPI_tokenExpired(t *testing.T) {
+	f := newFakeStorageAPI("fakedomain", "user", "password")
+	defer f.Close()
+
+	config := Config{
+		User:            "user",
+		Password:        "password",
+		IdentityDomain:  "fakedomain",
+		StorageEndpoint: f.URL,
+		MaxRetries:      1,
+	}
+	opcClient, err := config.Client()
+	if err != nil {
+		t.Fatalf("Error authenticating: %s", err)
+	}
+	if err := opcClient.storageObjectClient.createContainer("test"); err != nil {
+		t.Fatalf("Error creating container: %s", err)
+	}
+
+	// Uploads from a file are rewound and sent again with the new token
+	f.ExpireTokens()
+	authentications := f.Authentications()
+	content := "hello world"
+	body := io.NewSectionReader(strings.NewReader(content), 0, int64(len(content)))
+	if err := opcClient.storageObjectClient.putObject("test", "object", nil, body); err != nil {
+		t.Fatalf("Error uploading object: %s", err)
+	}
+	if got, ok := f.ObjectContent("test", "object"); !ok || string(got) != content {
+		t.Fatalf("Expected object content %q, got %q", content, got)
+	}
+	if n := f.Authentications() - authentications; n != 1 {
+		t.Fatalf("Expected 1 authentication after the token expired, got %d", n)
+	}
+
+	// The library client authenticated on its own, and its expired token is
+	// replaced with the new one without authenticating again
+	if _, err := opcClient.storageClient.GetContainer(&storage.GetContainerInput{Name: "test"}); err != nil {
+		t.Fatalf("Error reading container: %s", err)
+	}
+	if n := f.Authentications() - authentications; n != 1 {
+		t.Fatalf("Expected 1 authentication after the token expired, got %d", n)
+	}
+
+	// Both clients now share a session, which is replaced once
+	f.ExpireTokens()
+	authentications = f.Authentications()
+	if _, err := opcClient.storageClient.GetContainer(&storage.GetContainerInput{Name: "test"}); err != nil {
+		t.Fatalf("Error reading container: %s", err)
+	}
+	if _, err := opcClient.storageObjectClient.listObjects("test", ""); err != nil {
+		t.Fatalf("Error listing objects: %s", err)
+	}
+	if n := f.Authentications() - authentications; n != 1 {
+		t.Fatalf("Expected 1 authentication after the shared token expired, got %d", n)
+	}
+}
+
+func testAccCheckFakeStorageObjectContent(container, name, path string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		expected, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content, ok := testAccFakeStorageAPI.ObjectContent(container, name)
+		if !ok {
+			return fmt.Errorf("Object %s/%s not found", container, name)
+		}
+		if !bytes.Equal(content, expected) {
+			return fmt.Errorf("Expected %s/%s to hold the %d bytes of %s, got %d bytes", container, name, len(expected), path, len(content))
+		}
+		return nil
+	}
+}
+
+// testAccCheckFakeStorageRequests checks the number of PUT and POST requests
+// made for an object, and that it has never been deleted.
+func testAccCheckFakeStorageRequests(name string, puts, posts int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if n := testAccFakeStorageAPI.Requests("PUT", name); n != puts {
+			return fmt.Errorf("Expected %d PUT requests for %s, got %d", puts, name, n)
+		}
+		if n := testAccFakeStorageAPI.Requests("POST", name); n != posts {
+			return fmt.Errorf("Expected %d POST requests for %s, got %d", posts, name, n)
+		}
+		if n := testAccFakeStorageAPI.Requests("DELETE", name); n != 0 {
+			return fmt.Errorf("Expected %s to be updated in place, got %d DELETE requests", name, n)
+		}
+		return nil
+	}
+}
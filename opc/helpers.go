@@ -89,9 +89,10 @@ func getLoadBalancerContextFromID(id string) lbaas.LoadBalancerContext {
 	return lb
 }
 
-// Skip LB tests if environment variable isn't set.
+// Skip LB tests if environment variable isn't set, unless the acceptance tests
+// are run against the fake service APIs.
 func checkSkipLBTests() bool {
-	if os.Getenv("OPC_LBAAS_ENDPOINT") == "" {
+	if os.Getenv("OPC_LBAAS_ENDPOINT") == "" && os.Getenv("OPC_FAKE_API") == "" {
 		return true
 	}
 	return false
@@ -0,0 +1,176 @@
+package opc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/mitchellh/go-homedir"
+)
+
+// newHTTPTransport returns the transport shared by the clients of every API,
+// with the provider's TLS and proxy settings.
+func (c *Config) newHTTPTransport() (*http.Transport, error) {
+	transport := cleanhttp.DefaultTransport()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.Insecure,
+	}
+
+	if c.CACertificate != "" || c.CACertificateFile != "" {
+		data, source := []byte(c.CACertificate), "ca_certificate"
+		if c.CACertificateFile != "" {
+			path, err := homedir.Expand(c.CACertificateFile)
+			if err != nil {
+				return nil, fmt.Errorf("Error expanding ca_certificate_file %s: %s", c.CACertificateFile, err)
+			}
+			data, err = ioutil.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("Error reading ca_certificate_file %s: %s", c.CACertificateFile, err)
+			}
+			source = fmt.Sprintf("ca_certificate_file %s", c.CACertificateFile)
+		}
+
+		certificates, err := parseCertificates(data, source)
+		if err != nil {
+			return nil, err
+		}
+
+		// The CA certificates are trusted in addition to the system's, so
+		// that endpoints which aren't behind the TLS inspecting proxy are
+		// still verified
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			log.Printf("[WARN] Unable to load the system CA certificates, only trusting the ca_certificate: %s", err)
+			pool = x509.NewCertPool()
+		}
+		for _, certificate := range certificates {
+			pool.AddCert(certificate)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCertificate != "" || c.ClientKey != "" {
+		if c.ClientCertificate == "" || c.ClientKey == "" {
+			return nil, fmt.Errorf("client_certificate and client_key must be set together")
+		}
+		certificate, err := tls.X509KeyPair([]byte(c.ClientCertificate), []byte(c.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing client_certificate and client_key: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	noProxy := strings.FieldsFunc(c.NoProxy, func(r rune) bool { return r == ',' || r == ' ' })
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing proxy_url %s: %s", c.ProxyURL, err)
+		}
+		if proxyURL.Host == "" || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5") {
+			return nil, fmt.Errorf("proxy_url %s must be an http://, https:// or socks5:// URL", c.ProxyURL)
+		}
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if proxyBypassed(noProxy, req.URL) {
+				return nil, nil
+			}
+			return proxyURL, nil
+		}
+	} else if len(noProxy) > 0 {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if proxyBypassed(noProxy, req.URL) {
+				return nil, nil
+			}
+			return http.ProxyFromEnvironment(req)
+		}
+	}
+
+	return transport, nil
+}
+
+// parseCertificates parses every PEM encoded certificate in data, failing on
+// anything which isn't one.
+func parseCertificates(data []byte, source string) ([]*x509.Certificate, error) {
+	certificates := make([]*x509.Certificate, 0)
+	rest := bytes.TrimSpace(data)
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("Error parsing %s: certificate %d is not PEM encoded", source, len(certificates)+1)
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("Error parsing %s: expected a CERTIFICATE PEM block, got %s", source, block.Type)
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing %s: certificate %d: %s", source, len(certificates)+1, err)
+		}
+		certificates = append(certificates, certificate)
+		rest = bytes.TrimSpace(rest)
+	}
+
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("Error parsing %s: no PEM encoded certificates found", source)
+	}
+	return certificates, nil
+}
+
+// proxyBypassed reports whether requests to a URL are sent directly rather
+// than through the proxy. Each no_proxy entry is a host name, which also
+// matches its subdomains, a domain with a leading dot, an IP address or CIDR
+// range, optionally with a port, or * to bypass the proxy for every request.
+func proxyBypassed(noProxy []string, u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	for _, entry := range noProxy {
+		if entry == "*" {
+			return true
+		}
+
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		entryHost := entry
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entryHost = h
+		}
+		entryHost = strings.ToLower(strings.TrimPrefix(entryHost, "*"))
+
+		if strings.HasPrefix(entryHost, ".") {
+			if strings.HasSuffix(host, entryHost) || host == entryHost[1:] {
+				return true
+			}
+			continue
+		}
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return true
+		}
+	}
+	return false
+}
@@ -34,3 +34,29 @@ func TestAccOPCInstance_importBasic(t *testing.T) {
 		},
 	})
 }
+
+func TestAccOPCInstance_importUserData(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resourceName := "opc_compute_instance.test"
+	instanceName := fmt.Sprintf("acc-test-instance-%d", rInt)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceGzipUserData(rInt),
+			},
+			{
+				ResourceName:        resourceName,
+				ImportState:         true,
+				ImportStateVerify:   true,
+				ImportStateIdPrefix: instanceName + "/",
+			},
+		},
+	})
+}
@@ -21,7 +21,7 @@ func TestAccLBaaSServerPool_importBasic(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	config := testAccLBaaSServerPoolConfig_Basic(lbID, rInt, lbCount)
@@ -27,13 +27,11 @@ func orchestrationInstanceSchema() *schema.Schema {
 				"name": {
 					Type:     schema.TypeString,
 					Required: true,
-					ForceNew: true,
 				},
 
 				"shape": {
 					Type:     schema.TypeString,
 					Required: true,
-					ForceNew: true,
 				},
 
 				/////////////////////////
@@ -53,10 +51,26 @@ func orchestrationInstanceSchema() *schema.Schema {
 					DiffSuppressFunc: structure.SuppressJsonDiff,
 				},
 
+				"user_data": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+
+				"gzip_user_data": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+
+				"metadata": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+
 				"boot_order": {
 					Type:     schema.TypeList,
 					Optional: true,
-					ForceNew: true,
 					Elem:     &schema.Schema{Type: schema.TypeInt},
 				},
 
@@ -64,27 +78,23 @@ func orchestrationInstanceSchema() *schema.Schema {
 					Type:     schema.TypeString,
 					Optional: true,
 					Computed: true,
-					ForceNew: true,
 				},
 
 				"image_list": {
 					Type:     schema.TypeString,
 					Optional: true,
-					ForceNew: true,
 				},
 
 				"label": {
 					Type:     schema.TypeString,
 					Optional: true,
 					Computed: true,
-					ForceNew: true,
 				},
 
 				"networking_info": {
 					Type:     schema.TypeList,
 					Optional: true,
 					Computed: true,
-					ForceNew: true,
 					Elem: &schema.Resource{
 						Schema: map[string]*schema.Schema{
 							"dns": {
@@ -93,41 +103,35 @@ func orchestrationInstanceSchema() *schema.Schema {
 								Type:     schema.TypeList,
 								Optional: true,
 								Computed: true,
-								ForceNew: true,
 								Elem:     &schema.Schema{Type: schema.TypeString},
 							},
 
 							"index": {
 								Type:     schema.TypeInt,
-								ForceNew: true,
 								Required: true,
 							},
 
 							"ip_address": {
 								// Optional, IP Network only
 								Type:     schema.TypeString,
-								ForceNew: true,
 								Optional: true,
 							},
 
 							"ip_network": {
 								// Required for an IP Network Interface
 								Type:     schema.TypeString,
-								ForceNew: true,
 								Optional: true,
 							},
 
 							"is_default_gateway": {
 								// Optional, IP Network only
 								Type:     schema.TypeBool,
-								ForceNew: true,
 								Optional: true,
 							},
 
 							"mac_address": {
 								// Optional, IP Network Only
 								Type:     schema.TypeString,
-								ForceNew: true,
 								Computed: true,
 								Optional: true,
 							},
@@ -136,7 +140,6 @@ func orchestrationInstanceSchema() *schema.Schema {
 								// Optional, IP Network + Shared Network
 								Type:     schema.TypeList,
 								Optional: true,
-								ForceNew: true,
 								Elem:     &schema.Schema{Type: schema.TypeString},
 							},
 
@@ -145,7 +148,6 @@ func orchestrationInstanceSchema() *schema.Schema {
 								// Required for Shared Network
 								Type:     schema.TypeList,
 								Optional: true,
-								ForceNew: true,
 								Elem:     &schema.Schema{Type: schema.TypeString},
 							},
 
@@ -153,7 +155,6 @@ func orchestrationInstanceSchema() *schema.Schema {
 								// Optional, IP Network + Shared Network
 								Type:     schema.TypeList,
 								Optional: true,
-								ForceNew: true,
 								Elem:     &schema.Schema{Type: schema.TypeString},
 							},
 
@@ -162,21 +163,18 @@ func orchestrationInstanceSchema() *schema.Schema {
 								Type:     schema.TypeList,
 								Optional: true,
 								Computed: true,
-								ForceNew: true,
 								Elem:     &schema.Schema{Type: schema.TypeString},
 							},
 
 							"shared_network": {
 								Type:     schema.TypeBool,
 								Optional: true,
-								ForceNew: true,
 								Default:  false,
 							},
 
 							"vnic": {
 								// Optional, IP Network only.
 								Type:     schema.TypeString,
-								ForceNew: true,
 								Optional: true,
 							},
 
@@ -184,7 +182,6 @@ func orchestrationInstanceSchema() *schema.Schema {
 								// Optional, IP Network only.
 								Type:     schema.TypeList,
 								Optional: true,
-								ForceNew: true,
 								Elem:     &schema.Schema{Type: schema.TypeString},
 							},
 						},
@@ -195,32 +192,27 @@ func orchestrationInstanceSchema() *schema.Schema {
 					Type:     schema.TypeBool,
 					Optional: true,
 					Default:  true,
-					ForceNew: true,
 				},
 
 				"ssh_keys": {
 					Type:     schema.TypeList,
 					Optional: true,
-					ForceNew: true,
 					Elem:     &schema.Schema{Type: schema.TypeString},
 				},
 
 				"storage": {
 					Type:     schema.TypeList,
 					Optional: true,
-					ForceNew: true,
 					Elem: &schema.Resource{
 						Schema: map[string]*schema.Schema{
 							"index": {
 								Type:         schema.TypeInt,
 								Required:     true,
-								ForceNew:     true,
 								ValidateFunc: validation.IntBetween(1, 10),
 							},
 							"volume": {
 								Type:     schema.TypeString,
 								Required: true,
-								ForceNew: true,
 							},
 							"name": {
 								Type:     schema.TypeString,
@@ -230,7 +222,7 @@ func orchestrationInstanceSchema() *schema.Schema {
 					},
 				},
 
-				"tags": tagsForceNewSchema(),
+				"tags": tagsOptionalSchema(),
 
 				/////////////////////////
 				// Computed Attributes //
@@ -398,14 +390,16 @@ func expandCreateInstanceInput(prefix string, d *schema.ResourceData) (*compute.
 		input.Tags = tags
 	}
 
-	if attributes, ok := d.GetOk(fmt.Sprintf("%s.instance_attributes", prefix)); ok {
-		attrs, err := structure.ExpandJsonFromString(attributes.(string))
-		if err != nil {
-			return nil, fmt.Errorf("Cannot parse attributes as json: %s", err)
-		}
-		if attrs != nil {
-			input.Attributes = attrs
-		}
+	attrs, err := expandInstanceAttributes(
+		d.Get(fmt.Sprintf("%s.instance_attributes", prefix)).(string),
+		d.Get(fmt.Sprintf("%s.user_data", prefix)).(string),
+		d.Get(fmt.Sprintf("%s.gzip_user_data", prefix)).(bool),
+		d.Get(fmt.Sprintf("%s.metadata", prefix)).(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
+	if attrs != nil {
+		input.Attributes = attrs
 	}
 
 	return input, nil
@@ -470,98 +464,123 @@ func expandStorageAttachments(d *schema.ResourceData, prefix string) []compute.S
 }
 
 func flattenOrchestratedInstances(d *schema.ResourceData, meta interface{}, objects []compute.Object) (interface{}, error) {
-	instanceClient := meta.(*Client).computeClient.Instances()
-
+	// Oracle's api returns an unordered list so we'll match the objects to the
+	// instance blocks by name. Objects added outside of Terraform follow the
+	// blocks, so that the plan removes them.
+	objects, indexes := orderOrchestrationObjects(d, "instance", compute.OrchestrationTypeInstance, objects)
 	result := make([]interface{}, len(objects))
-	for i := range objects {
-		v := make(map[string]interface{})
-		getIDInput := &compute.GetInstanceIDInput{
-			// Oracle's api returns an unordered list so we'll find out instances through the config file name
-			Name: d.Get(fmt.Sprintf("instance.%d.name", i)).(string),
-		}
-		instance, err := instanceClient.GetInstanceFromName(getIDInput)
+	for i, object := range objects {
+		v, err := flattenOrchestratedInstance(d, meta, fmt.Sprintf("instance.%d", indexes[i]), object.Label, object.Persistent)
 		if err != nil {
 			return nil, err
 		}
+		result[i] = v
+	}
 
-		v["name"] = instance.Name
-		v["persistent"] = objects[i].Persistent
-		v["shape"] = instance.Shape
-		v["id"] = instance.ID
-
-		if attrs, ok := d.GetOk(fmt.Sprintf("instance.%d.instance_attributes", i)); ok && attrs != nil {
-			v["instance_attributes"] = attrs.(string)
-		} else {
-			instanceAttributes, err := flattenInstanceAttributes(instance.Attributes)
-			if err != nil {
-				return nil, err
-			}
-			v["instance_attributes"] = instanceAttributes
-		}
-
-		sort.Ints(instance.BootOrder)
-		v["boot_order"] = instance.BootOrder
+	return result, nil
+}
 
-		splitHostname := strings.Split(instance.Hostname, ".")
-		if len(splitHostname) == 0 {
-			return nil, fmt.Errorf("Unable to parse hostname: %s", instance.Hostname)
-		}
-		v["hostname"] = splitHostname[0]
-		v["fqdn"] = instance.Hostname
+// flattenOrchestratedInstance reads the instance with the given name. Its
+// user_data and metadata are read as configured in the instance block at
+// prefix.
+func flattenOrchestratedInstance(d *schema.ResourceData, meta interface{}, prefix, name string, persistent bool) (map[string]interface{}, error) {
+	instanceClient := meta.(*Client).computeClient.Instances()
 
-		v["image_list"] = instance.ImageList
-		v["label"] = instance.Label
+	v := make(map[string]interface{})
+	getIDInput := &compute.GetInstanceIDInput{
+		Name: name,
+	}
+	instance, err := instanceClient.GetInstanceFromName(getIDInput)
+	if err != nil {
+		return nil, err
+	}
 
-		networkInterfaces, err := flattenNetworkInterfaces(instance.Networking)
+	v["name"] = instance.Name
+	v["persistent"] = persistent
+	v["shape"] = instance.Shape
+	v["id"] = instance.ID
+
+	userData, gzipUserData, metadata := readInstanceUserData(instance.Attributes,
+		d.Get(fmt.Sprintf("%s.instance_attributes", prefix)).(string),
+		d.Get(fmt.Sprintf("%s.user_data", prefix)).(string),
+		d.Get(fmt.Sprintf("%s.gzip_user_data", prefix)).(bool),
+		d.Get(fmt.Sprintf("%s.metadata", prefix)).(map[string]interface{}))
+	v["user_data"] = userData
+	v["gzip_user_data"] = gzipUserData
+	v["metadata"] = metadata
+
+	// The user_data and metadata are merged into the attributes, so they
+	// are left out of the attributes read into instance_attributes
+	if attrs, ok := d.GetOk(fmt.Sprintf("%s.instance_attributes", prefix)); ok && attrs != nil {
+		v["instance_attributes"] = attrs.(string)
+	} else {
+		instanceAttributes, err := flattenInstanceAttributes(flattenInstanceUserData(instance.Attributes, userData, metadata))
 		if err != nil {
 			return nil, err
 		}
-		if len(networkInterfaces) > 0 {
-			v["networking_info"] = networkInterfaces
-		}
+		v["instance_attributes"] = instanceAttributes
+	}
+
+	sort.Ints(instance.BootOrder)
+	v["boot_order"] = instance.BootOrder
+
+	splitHostname := strings.Split(instance.Hostname, ".")
+	if len(splitHostname) == 0 {
+		return nil, fmt.Errorf("Unable to parse hostname: %s", instance.Hostname)
+	}
+	v["hostname"] = splitHostname[0]
+	v["fqdn"] = instance.Hostname
+
+	v["image_list"] = instance.ImageList
+	v["label"] = instance.Label
 
-		sort.Strings(instance.SSHKeys)
-		v["ssh_keys"] = instance.SSHKeys
+	networkInterfaces, err := flattenNetworkInterfaces(instance.Networking)
+	if err != nil {
+		return nil, err
+	}
+	if len(networkInterfaces) > 0 {
+		v["networking_info"] = networkInterfaces
+	}
 
-		v["reverse_dns"] = instance.ReverseDNS
+	sort.Strings(instance.SSHKeys)
+	v["ssh_keys"] = instance.SSHKeys
 
-		v["storage"] = flattenInstanceStorageAttachments(instance.Storage)
+	v["reverse_dns"] = instance.ReverseDNS
 
-		sort.Strings(instance.Tags)
-		v["tags"] = instance.Tags
+	v["storage"] = flattenInstanceStorageAttachments(instance.Storage)
 
-		v["availability_domain"] = instance.AvailabilityDomain
-		v["domain"] = instance.Domain
-		v["entry"] = instance.Entry
-		v["fingerprint"] = instance.Fingerprint
-		v["image_format"] = instance.ImageFormat
-		v["ip_address"] = instance.IPAddress
+	sort.Strings(instance.Tags)
+	v["tags"] = instance.Tags
 
-		sort.Strings(instance.PlacementRequirements)
-		v["placement_requirements"] = instance.PlacementRequirements
+	v["availability_domain"] = instance.AvailabilityDomain
+	v["domain"] = instance.Domain
+	v["entry"] = instance.Entry
+	v["fingerprint"] = instance.Fingerprint
+	v["image_format"] = instance.ImageFormat
+	v["ip_address"] = instance.IPAddress
 
-		v["platform"] = instance.Platform
-		v["priority"] = instance.Priority
-		v["quota_reservation"] = instance.QuotaReservation
+	sort.Strings(instance.PlacementRequirements)
+	v["placement_requirements"] = instance.PlacementRequirements
 
-		sort.Strings(instance.Relationships)
-		v["relationships"] = instance.Relationships
+	v["platform"] = instance.Platform
+	v["priority"] = instance.Priority
+	v["quota_reservation"] = instance.QuotaReservation
 
-		sort.Strings(instance.Resolvers)
-		v["resolvers"] = instance.Resolvers
+	sort.Strings(instance.Relationships)
+	v["relationships"] = instance.Relationships
 
-		v["site"] = instance.Site
-		v["start_time"] = instance.StartTime
-		v["state"] = instance.State
+	sort.Strings(instance.Resolvers)
+	v["resolvers"] = instance.Resolvers
 
-		v["vcable"] = instance.VCableID
-		v["virtio"] = instance.Virtio
-		v["vnc_address"] = instance.VNC
+	v["site"] = instance.Site
+	v["start_time"] = instance.StartTime
+	v["state"] = instance.State
 
-		result[i] = v
-	}
+	v["vcable"] = instance.VCableID
+	v["virtio"] = instance.Virtio
+	v["vnc_address"] = instance.VNC
 
-	return result, nil
+	return v, nil
 }
 
 // Flattens attributes from the returned instance object, and sets the computed attributes string
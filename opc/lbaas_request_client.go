@@ -0,0 +1,133 @@
+package opc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/client"
+	"github.com/hashicorp/go-oracle-terraform/lbaas"
+	"github.com/hashicorp/go-oracle-terraform/opc"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+// lbaasRequestClient sends the create, update and delete requests of the Load
+// Balancer resources. The lbaas.*Client calls wait for the resource at a fixed
+// interval which can't be cancelled, so the request is sent with this client,
+// and the resource is read with the lbaas.*Client while waiting for it with
+// waitForState.
+type lbaasRequestClient struct {
+	client *client.Client
+}
+
+func newLBaaSRequestClient(c *opc.Config) (*lbaasRequestClient, error) {
+	apiClient, err := client.NewClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return &lbaasRequestClient{client: apiClient}, nil
+}
+
+// do sends body to path, authenticated with the user's credentials. Each
+// resource has its own content types, which are those of the matching
+// lbaas.*Client. PATCH requests are POSTed with an X-HTTP-Method-Override, as
+// the service expects.
+func (c *lbaasRequestClient) do(method, path, accept, contentType string, body interface{}) error {
+	reqBody, err := c.client.MarshallRequestBody(body)
+	if err != nil {
+		return err
+	}
+
+	override := ""
+	if method == "PATCH" {
+		method, override = "POST", method
+	}
+
+	req, err := c.client.BuildRequestBody(method, path, reqBody)
+	if err != nil {
+		return err
+	}
+	if override != "" {
+		req.Header.Set("X-HTTP-Method-Override", override)
+	}
+	req.Header.Set("Accept", accept)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	c.client.DebugLogString(fmt.Sprintf("HTTP %s Req (%s)", method, path))
+	req.SetBasicAuth(*c.client.UserName, *c.client.Password)
+
+	resp, err := c.client.ExecuteRequest(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// lbaasFailedStates are the states of a Load Balancer resource whose request
+// has failed, or which the service has given up on.
+var lbaasFailedStates = []lbaas.LBaaSState{
+	lbaas.LBaaSStateCreationFailed,
+	lbaas.LBaaSStateModificaitonFailed,
+	lbaas.LBaaSStateDeletionFailed,
+	lbaas.LBaaSStateAbandon,
+	lbaas.LBaaSStateAutoAbandoned,
+	lbaas.LBaaSStateAccessDenied,
+	lbaas.LBaaSStateAdministratorInterventionNeeded,
+}
+
+// waitForLBaaSCreate waits for a Load Balancer resource, e.g. "Listener
+// uk/lb/web", to be created. get reads the state of the resource.
+func waitForLBaaSCreate(meta interface{}, name string, timeout time.Duration, get func() (lbaas.LBaaSState, error)) error {
+	return waitForLBaaSState(meta, name, "created", lbaas.LBaaSStateCreationInProgress,
+		[]string{string(lbaas.LBaaSStateCreated), string(lbaas.LBaaSStateHealthy)}, timeout, get)
+}
+
+// waitForLBaaSUpdate waits for the update of a Load Balancer resource.
+func waitForLBaaSUpdate(meta interface{}, name string, timeout time.Duration, get func() (lbaas.LBaaSState, error)) error {
+	return waitForLBaaSState(meta, name, "updated", lbaas.LBaaSStateModificationInProgress,
+		[]string{string(lbaas.LBaaSStateHealthy)}, timeout, get)
+}
+
+// waitForLBaaSDelete waits for a Load Balancer resource to be deleted, which
+// it is once it's DELETED or no longer exists.
+func waitForLBaaSDelete(meta interface{}, name string, timeout time.Duration, get func() (lbaas.LBaaSState, error)) error {
+	return waitForLBaaSState(meta, name, "deleted", lbaas.LBaaSStateDeletionInProgress,
+		[]string{string(lbaas.LBaaSStateDeleted), waitStateDeleted}, timeout, get)
+}
+
+func waitForLBaaSState(meta interface{}, name, operation string, pending lbaas.LBaaSState, target []string, timeout time.Duration, get func() (lbaas.LBaaSState, error)) error {
+	conf := waitConf{
+		Description: fmt.Sprintf("%s to be %s", name, operation),
+		Pending:     []string{string(pending)},
+		Target:      target,
+		Refresh:     lbaasState(name, get),
+		Timeout:     timeout,
+	}
+	if _, err := waitForState(meta.(*Client).stopContext, conf); err != nil {
+		return newAPIError(err)
+	}
+	return nil
+}
+
+// lbaasState reports the state of a Load Balancer resource, or
+// waitStateDeleted once it no longer exists. A resource in one of the failed
+// states fails the wait.
+func lbaasState(name string, get func() (lbaas.LBaaSState, error)) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		state, err := get()
+		if err != nil {
+			if wasNotFoundError(err) {
+				return nil, waitStateDeleted, nil
+			}
+			return nil, "", err
+		}
+
+		for _, failed := range lbaasFailedStates {
+			if state == failed {
+				return nil, "", fmt.Errorf("%s in errored state %s", name, state)
+			}
+		}
+		return state, string(state), nil
+	}
+}
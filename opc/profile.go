@@ -0,0 +1,182 @@
+package opc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	defaultProfileConfigFile = "~/.opc/config"
+	defaultProfileName       = "default"
+)
+
+// profileAttributes are the provider attributes which can be set in a
+// profile.
+var profileAttributes = map[string]bool{
+	"user":                true,
+	"password":            true,
+	"password_command":    true,
+	"identity_domain":     true,
+	"endpoint":            true,
+	"storage_endpoint":    true,
+	"storage_service_id":  true,
+	"lbaas_endpoint":      true,
+	"ca_certificate_file": true,
+	"proxy_url":           true,
+	"no_proxy":            true,
+}
+
+// readProfile reads a named profile from a shared config file, e.g.
+//
+//	[site-a]
+//	user            = jdoe@example.com
+//	identity_domain = acme-a
+//	endpoint        = https://compute.uscom-central-1.oraclecloud.com/
+//
+// A missing or invalid config file is only an error if it or the profile were
+// set explicitly. Otherwise the default config file may belong to other tools,
+// so its problems are logged as warnings, and nil is returned when there is
+// no profile to use.
+func readProfile(configFile, name string) (map[string]string, error) {
+	explicit := configFile != "" || name != ""
+	if configFile == "" {
+		configFile = defaultProfileConfigFile
+	}
+	if name == "" {
+		name = defaultProfileName
+	}
+
+	path, err := homedir.Expand(configFile)
+	if err != nil {
+		if !explicit {
+			log.Printf("[WARN] Not reading profiles: error expanding config_file %s: %s", configFile, err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Error expanding config_file %s: %s", configFile, err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if !explicit {
+			if !os.IsNotExist(err) {
+				log.Printf("[WARN] Not reading profiles: error reading config_file %s: %s", configFile, err)
+			}
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Error reading config_file %s: %s", configFile, err)
+	}
+	defer file.Close()
+
+	profiles, err := parseProfiles(file, configFile, explicit)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		if !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Profile %q not found in config_file %s", name, configFile)
+	}
+	return profile, nil
+}
+
+// parseProfiles parses the sections of an INI style config file. Lines
+// starting with # or ; are comments. Lines which can't be parsed, and keys
+// which can't be set in a profile, are errors when strict, and are otherwise
+// skipped with a warning.
+func parseProfiles(file *os.File, configFile string, strict bool) (map[string]map[string]string, error) {
+	profiles := make(map[string]map[string]string)
+	var profile map[string]string
+
+	invalid := func(lineNumber int, format string, a ...interface{}) error {
+		problem := fmt.Sprintf("Error parsing config_file %s on line %d: %s", configFile, lineNumber, fmt.Sprintf(format, a...))
+		if strict {
+			return errors.New(problem)
+		}
+		log.Printf("[WARN] Skipping line: %s", problem)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = make(map[string]string)
+			}
+			profile = profiles[name]
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			if err := invalid(lineNumber, "expected key = value"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		if profile == nil {
+			if err := invalid(lineNumber, "%s is not in a [profile] section", key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !profileAttributes[key] {
+			if err := invalid(lineNumber, "%s can't be set in a profile", key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		profile[key] = strings.TrimSpace(parts[1])
+	}
+	if err := scanner.Err(); err != nil {
+		if !strict {
+			log.Printf("[WARN] Not reading profiles: error reading config_file %s: %s", configFile, err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Error reading config_file %s: %s", configFile, err)
+	}
+
+	return profiles, nil
+}
+
+// runPasswordCommand runs a command through the shell, and returns its output
+// with any trailing newline removed.
+func runPasswordCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("Error running password_command: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	password := strings.TrimRight(stdout.String(), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password_command printed an empty password")
+	}
+	return password, nil
+}
@@ -1,35 +1,64 @@
 package opc
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 )
 
 // Provider returns the provider schema
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	provider := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"user": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPC_USERNAME", nil),
 				Description: "The user name for OPC API operations.",
 			},
 
 			"password": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("OPC_PASSWORD", nil),
+				ConflictsWith: []string{"password_command"},
+				Description:   "The user password for OPC API operations.",
+			},
+
+			"password_command": {
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("OPC_PASSWORD", nil),
-				Description: "The user password for OPC API operations.",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_PASSWORD_COMMAND", nil),
+				Description: "A command which prints the user password for OPC API operations.",
 			},
 
 			"identity_domain": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPC_IDENTITY_DOMAIN", nil),
 				Description: "The OPC identity domain for API operations",
 			},
 
+			"config_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_CONFIG_FILE", nil),
+				Description: "The shared config file to read the profile from (defaults to ~/.opc/config)",
+			},
+
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_PROFILE", nil),
+				Description: "The profile in the shared config file which sets any provider attributes not set otherwise (defaults to default)",
+			},
+
 			"endpoint": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -44,6 +73,53 @@ func Provider() terraform.ResourceProvider {
 				Description: "Maximum number retries to wait for a successful response when operating on resources within OPC (defaults to 1)",
 			},
 
+			"retry": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "The policy for retrying failed requests, up to `max_retries` attempts.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"retryable_status_codes": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeInt, ValidateFunc: validation.IntBetween(400, 599)},
+							Description: "The HTTP status codes of the responses which are retried (defaults to 429, 500, 502, 503 and 504)",
+						},
+						"max_elapsed_time": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "5m",
+							ValidateFunc: validateDuration,
+							Description:  "The longest time to spend retrying a request, e.g. `10m`",
+						},
+						"max_backoff": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "30s",
+							ValidateFunc: validateDuration,
+							Description:  "The longest time to wait between attempts, unless the response asks for longer with a `Retry-After` header",
+						},
+					},
+				},
+			},
+
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OPC_REQUESTS_PER_SECOND", 0),
+				ValidateFunc: validation.FloatBetween(0, 1000),
+				Description:  "The maximum number of requests per second to send to each OPC API endpoint (defaults to 0, unlimited)",
+			},
+
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("OPC_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "The maximum number of requests in flight to each OPC API endpoint (defaults to 0, unlimited)",
+			},
+
 			"insecure": {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -51,6 +127,65 @@ func Provider() terraform.ResourceProvider {
 				Description: "Skip TLS Verification for self-signed certificates. Should only be used if absolutely required.",
 			},
 
+			"ca_certificate": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("OPC_CA_CERTIFICATE", nil),
+				ConflictsWith: []string{"ca_certificate_file"},
+				Description:   "PEM encoded CA certificates to trust in addition to the system's, e.g. for a TLS inspecting proxy.",
+			},
+
+			"ca_certificate_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("OPC_CA_CERTIFICATE_FILE", nil),
+				ConflictsWith: []string{"ca_certificate"},
+				Description:   "The path to a file of PEM encoded CA certificates to trust in addition to the system's.",
+			},
+
+			"client_certificate": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_CLIENT_CERTIFICATE", nil),
+				Description: "A PEM encoded certificate to authenticate to the endpoints with. Requires client_key.",
+			},
+
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_CLIENT_KEY", nil),
+				Description: "The PEM encoded private key of the client_certificate.",
+			},
+
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_PROXY_URL", nil),
+				Description: "The URL of the proxy to send requests through, instead of the one in the HTTP_PROXY and HTTPS_PROXY environment variables.",
+			},
+
+			"no_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPC_NO_PROXY", nil),
+				Description: "A comma separated list of hosts, domains and CIDR ranges to connect to directly, bypassing the proxy.",
+			},
+
+			"default_tags": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Tags to add to every resource which has tags, in addition to its own tags.",
+			},
+
+			"ignore_tags_prefixes": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Tags which start with any of these prefixes are left as they are on every resource, e.g. tags added by other tooling.",
+			},
+
 			"storage_endpoint": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -75,12 +210,17 @@ func Provider() terraform.ResourceProvider {
 
 		DataSourcesMap: map[string]*schema.Resource{
 			"opc_compute_image_list_entry":        dataSourceImageListEntry(),
+			"opc_compute_instances":               dataSourceInstances(),
 			"opc_compute_ip_address_reservation":  dataSourceIPAddressReservation(),
+			"opc_compute_ip_networks":             dataSourceIPNetworks(),
 			"opc_compute_ip_reservation":          dataSourceIPReservation(),
 			"opc_compute_machine_image":           dataSourceMachineImage(),
+			"opc_compute_machine_images":          dataSourceMachineImages(),
 			"opc_compute_network_interface":       dataSourceNetworkInterface(),
 			"opc_compute_ssh_key":                 dataSourceSSHKey(),
+			"opc_compute_ssh_keys":                dataSourceSSHKeys(),
 			"opc_compute_storage_volume_snapshot": dataSourceStorageVolumeSnapshot(),
+			"opc_compute_storage_volumes":         dataSourceStorageVolumes(),
 			"opc_compute_vnic":                    dataSourceVNIC(),
 		},
 
@@ -112,6 +252,8 @@ func Provider() terraform.ResourceProvider {
 			"opc_compute_ip_address_association":  resourceOPCIPAddressAssociation(),
 			"opc_compute_snapshot":                resourceOPCSnapshot(),
 			"opc_compute_orchestrated_instance":   resourceOPCOrchestratedInstance(),
+			"opc_compute_orchestration":           resourceOPCOrchestration(),
+			"opc_compute_orchestration_document":  resourceOPCOrchestrationDocument(),
 			"opc_compute_vpn_endpoint_v2":         resourceOPCVPNEndpointV2(),
 			"opc_lbaas_certificate":               resourceLBaaSSSLCertificate(),
 			"opc_lbaas_listener":                  resourceLBaaSListener(),
@@ -121,23 +263,114 @@ func Provider() terraform.ResourceProvider {
 			"opc_storage_container":               resourceOPCStorageContainer(),
 			"opc_storage_object":                  resourceOPCStorageObject(),
 		},
+	}
 
-		ConfigureFunc: providerConfigure,
+	// Waits and requests in flight are cancelled when Terraform is stopped,
+	// e.g. by Ctrl-C
+	provider.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		return providerConfigure(d, provider.StopContext())
 	}
+	return provider
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+// Each of the credentials and endpoints is resolved in order from:
+//
+//  1. the provider configuration
+//  2. the OPC_* environment variables
+//  3. the profile in the shared config file
+//
+// The password attribute or OPC_PASSWORD takes precedence over the
+// password_command or OPC_PASSWORD_COMMAND, and the password in the profile
+// over the password_command in the profile.
+func providerConfigure(d *schema.ResourceData, stopContext context.Context) (interface{}, error) {
+	profile, err := readProfile(d.Get("config_file").(string), d.Get("profile").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	get := func(key string) string {
+		if v := d.Get(key).(string); v != "" {
+			return v
+		}
+		return profile[key]
+	}
+
 	config := Config{
-		User:             d.Get("user").(string),
-		Password:         d.Get("password").(string),
-		IdentityDomain:   d.Get("identity_domain").(string),
-		Endpoint:         d.Get("endpoint").(string),
+		User:             get("user"),
+		IdentityDomain:   get("identity_domain"),
+		Endpoint:         get("endpoint"),
 		MaxRetries:       d.Get("max_retries").(int),
 		Insecure:         d.Get("insecure").(bool),
-		StorageEndpoint:  d.Get("storage_endpoint").(string),
-		StorageServiceID: d.Get("storage_service_id").(string),
-		LBaaSEndpoint:    d.Get("lbaas_endpoint").(string),
+		StorageEndpoint:  get("storage_endpoint"),
+		StorageServiceID: get("storage_service_id"),
+		LBaaSEndpoint:    get("lbaas_endpoint"),
+
+		RequestsPerSecond:     d.Get("requests_per_second").(float64),
+		MaxConcurrentRequests: d.Get("max_concurrent_requests").(int),
+
+		CACertificate:     d.Get("ca_certificate").(string),
+		CACertificateFile: get("ca_certificate_file"),
+		ClientCertificate: d.Get("client_certificate").(string),
+		ClientKey:         d.Get("client_key").(string),
+		ProxyURL:          get("proxy_url"),
+		NoProxy:           get("no_proxy"),
+
+		StopContext: stopContext,
+
+		DefaultTags:        getTagsValue(d.Get("default_tags")),
+		IgnoreTagsPrefixes: getTagsValue(d.Get("ignore_tags_prefixes")),
+	}
+
+	// A ca_certificate in the configuration takes precedence over a
+	// ca_certificate_file in the profile
+	if config.CACertificate != "" && d.Get("ca_certificate_file").(string) == "" {
+		config.CACertificateFile = ""
+	}
+
+	config.Password, err = getProviderPassword(d, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]string, 0)
+	for key, value := range map[string]string{"user": config.User, "password": config.Password, "identity_domain": config.IdentityDomain} {
+		if value == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%s must be set in the provider configuration, the OPC_* environment variables, or a profile in the config_file", strings.Join(missing, ", "))
+	}
+
+	if v, ok := d.GetOk("retry"); ok && len(v.([]interface{})) > 0 && v.([]interface{})[0] != nil {
+		retry := v.([]interface{})[0].(map[string]interface{})
+		for _, code := range retry["retryable_status_codes"].(*schema.Set).List() {
+			config.Retry.StatusCodes = append(config.Retry.StatusCodes, code.(int))
+		}
+		// The durations have already been validated
+		config.Retry.MaxElapsedTime, _ = time.ParseDuration(retry["max_elapsed_time"].(string))
+		config.Retry.MaxBackoff, _ = time.ParseDuration(retry["max_backoff"].(string))
 	}
 
 	return config.Client()
 }
+
+// getProviderPassword returns the password, or the output of the
+// password_command, from the provider configuration or the environment, and
+// then from the profile.
+func getProviderPassword(d *schema.ResourceData, profile map[string]string) (string, error) {
+	if v := d.Get("password").(string); v != "" {
+		return v, nil
+	}
+	if v := d.Get("password_command").(string); v != "" {
+		return runPasswordCommand(v)
+	}
+	if v := profile["password"]; v != "" {
+		return v, nil
+	}
+	if v := profile["password_command"]; v != "" {
+		return runPasswordCommand(v)
+	}
+	return "", nil
+}
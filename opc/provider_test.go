@@ -2,13 +2,19 @@ package opc
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/go-homedir"
 )
 
 var testAccProviders map[string]terraform.ResourceProvider
@@ -31,7 +37,145 @@ func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
 
+func TestProvider_profile(t *testing.T) {
+	for _, k := range []string{
+		"OPC_USERNAME", "OPC_PASSWORD", "OPC_PASSWORD_COMMAND", "OPC_IDENTITY_DOMAIN", "OPC_ENDPOINT",
+		"OPC_STORAGE_ENDPOINT", "OPC_STORAGE_SERVICE_ID", "OPC_LBAAS_ENDPOINT", "OPC_CONFIG_FILE", "OPC_PROFILE",
+	} {
+		if v, ok := os.LookupEnv(k); ok {
+			defer os.Setenv(k, v)
+			os.Unsetenv(k)
+		}
+	}
+
+	f := newFakeComputeAPI("fakedomain", "user", "password")
+	defer f.Close()
+
+	dir, err := ioutil.TempDir("", "opc-profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config")
+	profiles := fmt.Sprintf(`
+# Shared credentials
+[default]
+user = nobody
+
+[site-a]
+user             = user
+identity_domain  = fakedomain
+endpoint         = %s
+password_command = echo password
+`, f.URL)
+	if err := ioutil.WriteFile(configFile, []byte(profiles), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		config map[string]interface{}
+		err    string
+	}{
+		{
+			config: map[string]interface{}{"config_file": configFile, "profile": "site-a"},
+		},
+		{
+			// The provider configuration takes precedence over the profile
+			config: map[string]interface{}{"config_file": configFile, "profile": "site-a", "password": "wrong"},
+			err:    "Incorrect username or password",
+		},
+		{
+			config: map[string]interface{}{"config_file": configFile, "profile": "site-b"},
+			err:    `Profile "site-b" not found`,
+		},
+		{
+			config: map[string]interface{}{"config_file": configFile},
+			err:    "identity_domain, password must be set",
+		},
+		{
+			config: map[string]interface{}{"config_file": filepath.Join(dir, "missing")},
+			err:    "Error reading config_file",
+		},
+		{
+			config: map[string]interface{}{"config_file": configFile, "profile": "site-a", "password_command": "exit 1"},
+			err:    "Error running password_command",
+		},
+	}
+
+	for _, c := range cases {
+		err := Provider().Configure(terraform.NewResourceConfigRaw(c.config))
+		if c.err == "" && err != nil {
+			t.Fatalf("Error configuring the provider with %v: %s", c.config, err)
+		}
+		if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Fatalf("Expected configuring the provider with %v to fail with %q, got %v", c.config, c.err, err)
+		}
+	}
+}
+
+func TestReadProfile_implicit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opc-profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The default config file may be shared with other tools, which set keys
+	// the provider doesn't know
+	if err := os.Mkdir(filepath.Join(dir, ".opc"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, ".opc", "config")
+	profiles := `
+region = us-phoenix-1
+
+[default]
+user   = user
+output = json
+`
+	if err := ioutil.WriteFile(configFile, []byte(profiles), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", dir)
+	homedir.DisableCache = true
+	defer func() { homedir.DisableCache = false }()
+
+	profile, err := readProfile("", "")
+	if err != nil {
+		t.Fatalf("Expected the unknown keys of the default config file to be skipped, got %s", err)
+	}
+	if !reflect.DeepEqual(profile, map[string]string{"user": "user"}) {
+		t.Fatalf("Expected the default profile to only set the user, got %v", profile)
+	}
+
+	// They're errors when the config file is set explicitly
+	if _, err := readProfile(configFile, ""); err == nil || !strings.Contains(err.Error(), "on line 2: region is not in a [profile] section") {
+		t.Fatalf("Expected the explicit config file to fail on line 2, got %v", err)
+	}
+	if _, err := readProfile("", "default"); err == nil || !strings.Contains(err.Error(), "on line 2: region is not in a [profile] section") {
+		t.Fatalf("Expected the explicit profile to fail on line 2, got %v", err)
+	}
+}
+
+// testAccFakeAPIEnv runs the acceptance tests against the in-process fake
+// services instead of a real identity domain when set.
+const testAccFakeAPIEnv = "OPC_FAKE_API"
+
+var (
+	testAccFakeAPIOnce    sync.Once
+	testAccFakeComputeAPI *fakeComputeAPI
+	testAccFakeStorageAPI *fakeStorageAPI
+	testAccFakeLBaaSAPI   *fakeLBaaSAPI
+)
+
 func testAccPreCheck(t *testing.T) {
+	if os.Getenv(testAccFakeAPIEnv) != "" {
+		testAccFakeAPIPreCheck(t)
+		return
+	}
+
 	required := []string{"OPC_USERNAME", "OPC_PASSWORD",
 		"OPC_IDENTITY_DOMAIN", "OPC_ENDPOINT",
 	}
@@ -81,6 +225,67 @@ func testAccPreCheck(t *testing.T) {
 	}
 }
 
+// testAccFakeAPIPreCheck starts the fake services on first use and points the
+// provider configuration at them.
+func testAccFakeAPIPreCheck(t *testing.T) {
+	testAccFakeAPIOnce.Do(func() {
+		testAccFakeComputeAPI = newFakeComputeAPI("fakedomain", "fake-user@example.com", "fake-password")
+		testAccFakeStorageAPI = newFakeStorageAPI("fakedomain", "fake-user@example.com", "fake-password")
+		testAccFakeLBaaSAPI = newFakeLBaaSAPI("fake-user@example.com", "fake-password")
+		testAccFakeComputeAPI.ObjectExists = func(container, name string) bool {
+			_, ok := testAccFakeStorageAPI.ObjectContent(container, name)
+			return ok
+		}
+	})
+
+	env := map[string]string{
+		"OPC_USERNAME":         testAccFakeComputeAPI.User,
+		"OPC_PASSWORD":         testAccFakeComputeAPI.Password,
+		"OPC_IDENTITY_DOMAIN":  testAccFakeComputeAPI.IdentityDomain,
+		"OPC_ENDPOINT":         testAccFakeComputeAPI.URL,
+		"OPC_STORAGE_ENDPOINT": testAccFakeStorageAPI.URL,
+		"OPC_LBAAS_ENDPOINT":   testAccFakeLBaaSAPI.URL,
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatalf("Error setting %s: %s", k, err)
+		}
+	}
+
+	config := Config{
+		User:            testAccFakeComputeAPI.User,
+		Password:        testAccFakeComputeAPI.Password,
+		IdentityDomain:  testAccFakeComputeAPI.IdentityDomain,
+		Endpoint:        testAccFakeComputeAPI.URL,
+		StorageEndpoint: testAccFakeStorageAPI.URL,
+		LBaaSEndpoint:   testAccFakeLBaaSAPI.URL,
+		MaxRetries:      1,
+	}
+
+	if _, err := config.Client(); err != nil {
+		t.Fatalf("Error authenticating with the fake API: %+v", err)
+	}
+}
+
+// testAccFakeAPIUnitTest runs a test case against the fake services as part of
+// the regular unit test run, restoring the provider environment afterwards.
+func testAccFakeAPIUnitTest(t *testing.T, c resource.TestCase) {
+	vars := []string{
+		"OPC_USERNAME", "OPC_PASSWORD", "OPC_IDENTITY_DOMAIN", "OPC_ENDPOINT",
+		"OPC_STORAGE_ENDPOINT", "OPC_STORAGE_SERVICE_ID", "OPC_LBAAS_ENDPOINT",
+	}
+	for _, k := range vars {
+		if v, ok := os.LookupEnv(k); ok {
+			defer os.Setenv(k, v)
+		} else {
+			defer os.Unsetenv(k)
+		}
+	}
+
+	c.PreCheck = func() { testAccFakeAPIPreCheck(t) }
+	resource.UnitTest(t, c)
+}
+
 type OPCResourceState struct {
 	*compute.Client
 	*terraform.InstanceState
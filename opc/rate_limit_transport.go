@@ -0,0 +1,77 @@
+package opc
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitTransport spaces out the requests to an API so that no more than
+// the given number are sent per second, and caps the number of requests in
+// flight. Requests wait their turn rather than failing, so that large applies
+// with many resources slow down instead of being throttled by the service.
+// Zero disables either limit.
+type rateLimitTransport struct {
+	transport http.RoundTripper
+	interval  time.Duration
+	inFlight  chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRateLimitTransport(transport http.RoundTripper, requestsPerSecond float64, maxConcurrent int) *rateLimitTransport {
+	t := &rateLimitTransport{
+		transport: transport,
+	}
+	if requestsPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	if maxConcurrent > 0 {
+		t.inFlight = make(chan struct{}, maxConcurrent)
+	}
+	return t
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A request is in flight until its response headers have been received
+	if t.inFlight != nil {
+		select {
+		case t.inFlight <- struct{}{}:
+			defer func() { <-t.inFlight }()
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if wait := t.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	return t.transport.RoundTrip(req)
+}
+
+// reserve takes the next free slot to send a request in, and returns how long
+// to wait for it.
+func (t *rateLimitTransport) reserve() time.Duration {
+	if t.interval == 0 {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	wait := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	return wait
+}
@@ -0,0 +1,52 @@
+package opc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// sensitiveLogFields are the names of the secrets which the resources send to
+// and receive from the APIs, as request body fields, headers, struct fields
+// and schema attributes. A field is redacted from the debug logs when its name
+// contains one of them, ignoring case, so that "password" also covers any
+// admin_password in the instance_attributes.
+var sensitiveLogFields = map[string][]string{
+	"provider":                    {"password", "x-auth-token", "x-storage-pass", "authorization", "cookie"},
+	"opc_compute_instance":        {"password", "passwd", "secret"},
+	"opc_compute_vpn_endpoint_v2": {"psk", "pre_shared_key"},
+	"opc_lbaas_certificate":       {"private_key", "privatekey"},
+	"opc_storage_container":       {"temp-url-key", "primary_key", "primarykey", "secondary_key", "secondarykey"},
+}
+
+var (
+	// "name": "value", as in JSON and %#v formatted maps
+	sensitiveQuotedFieldRegexp *regexp.Regexp
+	// name: value, as in headers and %+v formatted structs and maps
+	sensitiveFieldRegexp *regexp.Regexp
+)
+
+func init() {
+	names := make([]string, 0)
+	for _, fields := range sensitiveLogFields {
+		for _, field := range fields {
+			names = append(names, regexp.QuoteMeta(field))
+		}
+	}
+	sort.Strings(names)
+
+	name := fmt.Sprintf(`[\w.-]*(?:%s)[\w.-]*`, strings.Join(names, "|"))
+	quoted := `"(?:[^"\\]|\\.)*"`
+	sensitiveQuotedFieldRegexp = regexp.MustCompile(fmt.Sprintf(`(?i)("%s"\s*:\s*)%s`, name, quoted))
+	sensitiveFieldRegexp = regexp.MustCompile(fmt.Sprintf(`(?i)\b(%s:[ \t]*)(?:basic \S+|bearer \S+|%s|[^\s,}\]]+)`, name, quoted))
+}
+
+// redactLogString replaces the values of the sensitiveLogFields in a log
+// message, so that debug logs can be shared without leaking secrets.
+func redactLogString(s string) string {
+	s = sensitiveQuotedFieldRegexp.ReplaceAllString(s, fmt.Sprintf(`${1}"%s"`, redactedValue))
+	return sensitiveFieldRegexp.ReplaceAllString(s, "${1}"+redactedValue)
+}
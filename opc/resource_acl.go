@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -19,6 +18,8 @@ func resourceOPCACL() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsForceNew,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -38,8 +39,9 @@ func resourceOPCACL() *schema.Resource {
 				Type:     schema.TypeList,
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
-				ForceNew: true,
 			},
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"uri": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -63,7 +65,7 @@ func resourceOPCACLCreate(d *schema.ResourceData, meta interface{}) error {
 		Enabled: d.Get("enabled").(bool),
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -74,7 +76,7 @@ func resourceOPCACLCreate(d *schema.ResourceData, meta interface{}) error {
 
 	info, err := resClient.CreateACL(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating ACL: %s", err)
+		return fmt.Errorf("Error creating ACL: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -97,11 +99,11 @@ func resourceOPCACLRead(d *schema.ResourceData, meta interface{}) error {
 	result, err := resClient.GetACL(&getInput)
 	if err != nil {
 		// ACL does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading acl %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading acl %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -114,7 +116,7 @@ func resourceOPCACLRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("enabled", result.Enabled)
 	d.Set("description", result.Description)
 	d.Set("uri", result.URI)
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -135,7 +137,7 @@ func resourceOPCACLUpdate(d *schema.ResourceData, meta interface{}) error {
 		Enabled: d.Get("enabled").(bool),
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -146,7 +148,7 @@ func resourceOPCACLUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	info, err := resClient.UpdateACL(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating ACL: %s", err)
+		return fmt.Errorf("Error updating ACL: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
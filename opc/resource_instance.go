@@ -9,9 +9,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/hashcode"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 )
@@ -30,6 +30,9 @@ func resourceInstance() *schema.Resource {
 				}
 				d.Set("name", strings.Join(combined[0:len(combined)-1], "/"))
 				d.SetId(combined[len(combined)-1])
+				if err := importStorageAttachments(d, meta); err != nil {
+					return nil, err
+				}
 				return []*schema.ResourceData{d}, nil
 			},
 		},
@@ -40,6 +43,8 @@ func resourceInstance() *schema.Resource {
 			Delete: schema.DefaultTimeout(20 * time.Minute),
 		},
 
+		CustomizeDiff: resourceInstanceCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			/////////////////////////
 			// Required Attributes //
@@ -53,7 +58,6 @@ func resourceInstance() *schema.Resource {
 			"shape": {
 				Type:     schema.TypeString,
 				Required: true,
-				ForceNew: true,
 			},
 
 			/////////////////////////
@@ -66,6 +70,26 @@ func resourceInstance() *schema.Resource {
 				ValidateFunc: validation.ValidateJsonString,
 			},
 
+			"user_data": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"gzip_user_data": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+
+			"metadata": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
 			"boot_order": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -243,29 +267,32 @@ func resourceInstance() *schema.Resource {
 					}
 					return false
 				},
-				ForceNew: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"index": {
 							Type:         schema.TypeInt,
 							Required:     true,
-							ForceNew:     true,
 							ValidateFunc: validation.IntBetween(1, 10),
 						},
 						"volume": {
 							Type:     schema.TypeString,
 							Required: true,
-							ForceNew: true,
 						},
 						"name": {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"size": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
 					},
 				},
 			},
 
-			"tags": tagsForceNewSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 
 			/////////////////////////
 			// Computed Attributes //
@@ -377,23 +404,33 @@ func resourceInstance() *schema.Resource {
 }
 
 func resourceInstanceCreate(d *schema.ResourceData, meta interface{}) error {
-	computeClient, err := meta.(*Client).getComputeClient()
+	input, err := expandInstanceInput(d, meta)
 	if err != nil {
 		return err
 	}
-	resClient := computeClient.Instances()
 
+	if err := launchInstance(d, meta, input, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return fmt.Errorf("Error creating instance %s: %s", input.Name, err)
+	}
+
+	log.Printf("[DEBUG] Created instance %s: %#v", input.Name, d.Id())
+
+	return resourceInstanceRead(d, meta)
+}
+
+// expandInstanceInput builds the launch plan of the instance from its
+// configuration.
+func expandInstanceInput(d *schema.ResourceData, meta interface{}) (*compute.CreateInstanceInput, error) {
 	// Get Required Attributes
 	input := &compute.CreateInstanceInput{
-		Name:    d.Get("name").(string),
-		Shape:   d.Get("shape").(string),
-		Timeout: d.Timeout(schema.TimeoutCreate),
+		Name:  d.Get("name").(string),
+		Shape: d.Get("shape").(string),
 	}
 
 	// Get optional instance attributes
 	attributes, attrErr := getInstanceAttributes(d)
 	if attrErr != nil {
-		return attrErr
+		return nil, attrErr
 	}
 
 	if attributes != nil {
@@ -418,7 +455,7 @@ func resourceInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 
 	interfaces, err := readNetworkInterfacesFromConfig(d)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if interfaces != nil {
 		input.Networking = interfaces
@@ -437,20 +474,11 @@ func resourceInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 		input.Storage = storage
 	}
 
-	if tags := getStringList(d, "tags"); len(tags) > 0 {
+	if tags := expandTags(d, meta); len(tags) > 0 {
 		input.Tags = tags
 	}
 
-	result, err := resClient.CreateInstance(input)
-	if err != nil {
-		return fmt.Errorf("Error creating instance %s: %s", input.Name, err)
-	}
-
-	log.Printf("[DEBUG] Created instance %s: %#v", input.Name, result.ID)
-
-	d.SetId(result.ID)
-
-	return resourceInstanceRead(d, meta)
+	return input, nil
 }
 
 func resourceInstanceRead(d *schema.ResourceData, meta interface{}) error {
@@ -471,12 +499,12 @@ func resourceInstanceRead(d *schema.ResourceData, meta interface{}) error {
 	result, err := resClient.GetInstance(input)
 	if err != nil {
 		// Instance doesn't exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			log.Printf("[DEBUG] Instance %s not found", name)
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading instance %s: %s", name, err)
+		return fmt.Errorf("Error reading instance %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -488,10 +516,10 @@ func resourceInstanceRead(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] Instance '%s' found", name)
 
 	// Update attributes
-	return updateInstanceAttributes(d, result)
+	return updateInstanceAttributes(d, meta, computeClient, result)
 }
 
-func updateInstanceAttributes(d *schema.ResourceData, instance *compute.InstanceInfo) error {
+func updateInstanceAttributes(d *schema.ResourceData, meta interface{}, computeClient *compute.Client, instance *compute.InstanceInfo) error {
 	d.Set("name", instance.Name)
 	d.Set("shape", instance.Shape)
 
@@ -503,6 +531,17 @@ func updateInstanceAttributes(d *schema.ResourceData, instance *compute.Instance
 		d.Set("instance_attributes", attrs.(string))
 	}
 
+	userData, gzipUserData, metadata := readInstanceUserData(instance.Attributes,
+		d.Get("instance_attributes").(string),
+		d.Get("user_data").(string),
+		d.Get("gzip_user_data").(bool),
+		d.Get("metadata").(map[string]interface{}))
+	d.Set("user_data", userData)
+	d.Set("gzip_user_data", gzipUserData)
+	if err := d.Set("metadata", metadata); err != nil {
+		return err
+	}
+
 	if err := setIntList(d, "boot_order", instance.BootOrder); err != nil {
 		return err
 	}
@@ -525,11 +564,11 @@ func updateInstanceAttributes(d *schema.ResourceData, instance *compute.Instance
 		return err
 	}
 
-	if err := readStorageAttachments(d, instance.Storage); err != nil {
+	if err := readStorageAttachments(d, computeClient.StorageVolumes(), instance.Storage); err != nil {
 		return err
 	}
 
-	if err := setStringList(d, "tags", instance.Tags); err != nil {
+	if err := flattenTags(d, meta, instance.Tags); err != nil {
 		return err
 	}
 	d.Set("availability_domain", instance.AvailabilityDomain)
@@ -560,10 +599,6 @@ func updateInstanceAttributes(d *schema.ResourceData, instance *compute.Instance
 	d.Set("start_time", instance.StartTime)
 	d.Set("state", instance.State)
 
-	if err := setStringList(d, "tags", instance.Tags); err != nil {
-		return err
-	}
-
 	d.Set("vcable", instance.VCableID)
 	d.Set("virtio", instance.Virtio)
 	d.Set("vnc_address", instance.VNC)
@@ -572,57 +607,455 @@ func updateInstanceAttributes(d *schema.ResourceData, instance *compute.Instance
 }
 
 func resourceInstanceUpdate(d *schema.ResourceData, meta interface{}) error {
-	computeClient, err := meta.(*Client).getComputeClient()
-	if err != nil {
-		return err
+	name := d.Get("name").(string)
+
+	// Volumes are attached before reshaping, so that the reshaped instance
+	// is launched with them
+	if d.HasChange("storage") {
+		if err := updateStorageAttachments(d, meta); err != nil {
+			return err
+		}
 	}
-	resClient := computeClient.Instances()
 
-	name := d.Get("name").(string)
+	reshaped := false
+	if d.HasChange("shape") {
+		if err := reshapeInstance(d, meta); err != nil {
+			return err
+		}
+		reshaped = true
+	}
 
 	input := &compute.UpdateInstanceInput{
-		Name:    name,
-		ID:      d.Id(),
-		Timeout: d.Timeout(schema.TimeoutUpdate),
+		Name: name,
+		ID:   d.Id(),
 	}
 
-	if d.HasChange("desired_state") {
+	// A reshaped instance is relaunched in the running state
+	if d.HasChange("desired_state") || reshaped {
 		input.DesiredState = compute.InstanceDesiredState(d.Get("desired_state").(string))
 	}
 
-	if d.HasChange("tags") {
-		tags := getStringList(d, "tags")
+	if d.HasChange("tags") || d.HasChange("tags_map") {
+		tags := expandTags(d, meta)
 		input.Tags = tags
 
 	}
 
-	result, err := resClient.UpdateInstance(input)
-	if err != nil {
+	if err := updateInstance(meta, input, d.Timeout(schema.TimeoutUpdate)); err != nil {
 		return fmt.Errorf("Error updating instance %s: %s", input.Name, err)
 	}
 
-	log.Printf("[DEBUG] Updated instance %s: %#v", result.Name, result.ID)
+	log.Printf("[DEBUG] Updated instance %s: %#v", input.Name, input.ID)
 
 	return resourceInstanceRead(d, meta)
 }
 
-func resourceInstanceDelete(d *schema.ResourceData, meta interface{}) error {
+// Only instances booted from a storage volume can be reshaped in place, as the
+// boot volume outlives the instance. Changing the shape of any other instance
+// replaces it. Volumes can be attached to and detached from a running
+// instance, except for the boot volume. Conflicts between the user_data,
+// metadata and instance_attributes fail the plan.
+func resourceInstanceCustomizeDiff(diff *schema.ResourceDiff, v interface{}) error {
+	if err := customizeDiffTagsForceNew(diff, v); err != nil {
+		return err
+	}
+
+	if diff.NewValueKnown("instance_attributes") && diff.NewValueKnown("user_data") && diff.NewValueKnown("metadata") {
+		if _, err := expandInstanceAttributes(
+			diff.Get("instance_attributes").(string),
+			diff.Get("user_data").(string),
+			diff.Get("gzip_user_data").(bool),
+			diff.Get("metadata").(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+
+	if diff.Id() == "" {
+		return nil
+	}
+
+	if diff.HasChange("storage") && bootStorageChanged(diff) {
+		if err := diff.ForceNew("storage"); err != nil {
+			return err
+		}
+	}
+
+	if !diff.HasChange("shape") {
+		return nil
+	}
+	if bootOrder, ok := diff.GetOk("boot_order"); ok && len(bootOrder.([]interface{})) > 0 {
+		// The instance is launched again by reshapeInstance, so every
+		// attribute the service assigns it is known after the update
+		for _, k := range instanceLaunchAttributes {
+			if err := diff.SetNewComputed(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return diff.ForceNew("shape")
+}
+
+// instanceLaunchAttributes are the computed attributes which the service
+// assigns to an instance when it is launched.
+var instanceLaunchAttributes = []string{
+	"attributes",
+	"availability_domain",
+	"domain",
+	"entry",
+	"fingerprint",
+	"fqdn",
+	"image_format",
+	"ip_address",
+	"placement_requirements",
+	"platform",
+	"priority",
+	"quota_reservation",
+	"relationships",
+	"resolvers",
+	"site",
+	"start_time",
+	"state",
+	"vcable",
+	"virtio",
+	"vnc_address",
+}
+
+// bootStorageChanged reports whether the volume attached at any of the
+// indexes in boot_order is changing.
+func bootStorageChanged(diff *schema.ResourceDiff) bool {
+	o, n := diff.GetChange("storage")
+	oldVolumes := getStorageVolumesByIndex(o.(*schema.Set))
+	newVolumes := getStorageVolumesByIndex(n.(*schema.Set))
+	for _, index := range diff.Get("boot_order").([]interface{}) {
+		if oldVolumes[index.(int)] != newVolumes[index.(int)] {
+			return true
+		}
+	}
+	return false
+}
+
+func getStorageVolumesByIndex(storage *schema.Set) map[int]string {
+	result := make(map[int]string)
+	for _, i := range storage.List() {
+		attrs := i.(map[string]interface{})
+		result[attrs["index"].(int)] = attrs["volume"].(string)
+	}
+	return result
+}
+
+// updateStorageAttachments detaches the volumes removed from the storage block
+// of an instance, and attaches the volumes added to it. Volumes are detached
+// first, so that their indexes can be reused.
+func updateStorageAttachments(d *schema.ResourceData, meta interface{}) error {
 	computeClient, err := meta.(*Client).getComputeClient()
 	if err != nil {
 		return err
 	}
-	resClient := computeClient.Instances()
+	name := d.Get("name").(string)
+	timeout := d.Timeout(schema.TimeoutUpdate)
+
+	o, n := d.GetChange("storage")
+	oldStorage := o.(*schema.Set)
+	newStorage := n.(*schema.Set)
+
+	for _, i := range oldStorage.Difference(newStorage).List() {
+		attrs := i.(map[string]interface{})
+		log.Printf("[DEBUG] Detaching storage volume %s from instance %s", attrs["volume"], name)
+		if err := detachStorageVolume(meta, attrs["name"].(string), timeout); err != nil {
+			return fmt.Errorf("Error detaching storage volume %s from instance %s: %s", attrs["volume"], name, err)
+		}
+	}
+
+	added := newStorage.Difference(oldStorage).List()
+	if len(added) == 0 {
+		return nil
+	}
+
+	// Volumes attached by opc_compute_storage_attachment aren't tracked in
+	// the storage block, so check the indexes in use on the instance
+	getInput := &compute.GetInstanceInput{
+		ID:   d.Id(),
+		Name: name,
+	}
+	instance, err := computeClient.Instances().GetInstance(getInput)
+	if err != nil {
+		return fmt.Errorf("Error reading instance %s: %s", name, newAPIError(err))
+	}
+
+	for _, i := range added {
+		attrs := i.(map[string]interface{})
+		index := attrs["index"].(int)
+		if !checkForEmptyIndex(instance.Storage, index) {
+			return fmt.Errorf("Storage index %d is already in use on instance %s", index, name)
+		}
+
+		log.Printf("[DEBUG] Attaching storage volume %s to instance %s at index %d", attrs["volume"], name, index)
+		input := &compute.CreateStorageAttachmentInput{
+			StorageVolumeName: attrs["volume"].(string),
+			InstanceName:      fmt.Sprintf("%s/%s", name, d.Id()),
+			Index:             index,
+		}
+		if _, err := attachStorageVolume(meta, input, timeout); err != nil {
+			return fmt.Errorf("Error attaching storage volume %s to instance %s: %s", attrs["volume"], name, err)
+		}
+	}
+
+	return nil
+}
 
+// reshapeInstance changes the shape of an instance booted from a storage
+// volume. Compute Classic can't change the shape of an existing instance, so
+// the instance is shut down and deleted, and launched again with the new shape
+// from the same boot volume. The instance is launched with the network
+// interfaces of its configuration, and keeps its storage attachments,
+// including volumes attached outside of the instance's storage block. If the
+// launch request fails, the id of the deleted instance is kept, so that the
+// next refresh finds it gone and the next plan launches it again.
+func reshapeInstance(d *schema.ResourceData, meta interface{}) error {
+	computeClient, err := meta.(*Client).getComputeClient()
+	if err != nil {
+		return err
+	}
 	name := d.Get("name").(string)
+	timeout := d.Timeout(schema.TimeoutUpdate)
+
+	getInput := &compute.GetInstanceInput{
+		ID:   d.Id(),
+		Name: name,
+	}
+	instance, err := computeClient.Instances().GetInstance(getInput)
+	if err != nil {
+		return fmt.Errorf("Error reading instance %s: %s", name, newAPIError(err))
+	}
+
+	input, err := expandInstanceInput(d, meta)
+	if err != nil {
+		return err
+	}
+	input.Storage = make([]compute.StorageAttachmentInput, 0, len(instance.Storage))
+	for _, attachment := range instance.Storage {
+		input.Storage = append(input.Storage, compute.StorageAttachmentInput{
+			Index:  attachment.Index,
+			Volume: attachment.StorageVolumeName,
+		})
+	}
 
-	input := &compute.DeleteInstanceInput{
-		ID:      d.Id(),
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
+	if compute.InstanceState(instance.State) != compute.InstanceShutdown {
+		log.Printf("[DEBUG] Shutting down instance %s to change its shape to %s", name, input.Shape)
+		updateInput := &compute.UpdateInstanceInput{
+			Name:         name,
+			ID:           d.Id(),
+			DesiredState: compute.InstanceDesiredShutdown,
+		}
+		if err := updateInstance(meta, updateInput, timeout); err != nil {
+			return fmt.Errorf("Error shutting down instance %s: %s", name, err)
+		}
 	}
+
+	if err := deleteInstance(meta, name, d.Id(), timeout); err != nil {
+		return fmt.Errorf("Error deleting instance %s to change its shape: %s", name, err)
+	}
+
+	log.Printf("[DEBUG] Launching instance %s with shape %s", name, input.Shape)
+	if err := launchInstance(d, meta, input, timeout); err != nil {
+		return fmt.Errorf("Error launching instance %s with shape %s: %s", name, input.Shape, err)
+	}
+
+	return nil
+}
+
+// launchInstance submits a launch plan for an instance, and waits for it to be
+// running. The id of the instance is set as soon as it has been launched, so
+// that an instance which fails to start, or whose wait is cancelled, is kept
+// in the state rather than left running outside of it.
+func launchInstance(d *schema.ResourceData, meta interface{}, input *compute.CreateInstanceInput, timeout time.Duration) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	// The launch plan is sent by the provider, as the Instances client waits
+	// for the instance at a fixed interval which can't be cancelled
+	plan := struct {
+		Instances []*compute.CreateInstanceInput `json:"instances"`
+	}{[]*compute.CreateInstanceInput{qualifyInstanceInput(client, input)}}
+	var result struct {
+		Instances []struct {
+			ID string `json:"id"`
+		} `json:"instances"`
+	}
+	if err := client.do("POST", "/launchplan/", plan, &result); err != nil {
+		return newAPIError(err)
+	}
+	if len(result.Instances) == 0 {
+		return fmt.Errorf("No instance was launched")
+	}
+	d.SetId(result.Instances[0].ID)
+
+	return waitForInstanceState(meta, input.Name, d.Id(), compute.InstanceRunning, timeout)
+}
+
+// updateInstance updates the desired state and tags of an instance, and waits
+// for it to be running or shut down, as its desired state is.
+func updateInstance(meta interface{}, input *compute.UpdateInstanceInput, timeout time.Duration) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	body := *input
+	body.Name = client.qualify(input.Name)
+	var result struct {
+		DesiredState compute.InstanceDesiredState `json:"desired_state"`
+	}
+	if err := client.do("PUT", fmt.Sprintf("/instance%s/%s", body.Name, input.ID), body, &result); err != nil {
+		return newAPIError(err)
+	}
+
+	target := compute.InstanceRunning
+	if result.DesiredState == compute.InstanceDesiredShutdown {
+		target = compute.InstanceShutdown
+	}
+	return waitForInstanceState(meta, input.Name, input.ID, target, timeout)
+}
+
+// deleteInstance deletes an instance, and waits for it to be gone.
+func deleteInstance(meta interface{}, name, id string, timeout time.Duration) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.do("DELETE", fmt.Sprintf("/instance%s/%s", client.qualify(name), id), nil, nil); err != nil {
+		return newAPIError(err)
+	}
+	return waitForInstanceState(meta, name, id, waitStateDeleted, timeout)
+}
+
+// instanceStates are the states an instance passes through, which are
+// pending while waiting for any other state.
+var instanceStates = []compute.InstanceState{
+	compute.InstanceQueued,
+	compute.InstancePreparing,
+	compute.InstanceInitializing,
+	compute.InstanceStarting,
+	compute.InstanceRunning,
+	compute.InstanceStopping,
+	compute.InstanceShutdown,
+}
+
+func waitForInstanceState(meta interface{}, name, id string, target compute.InstanceState, timeout time.Duration) error {
+	computeClient, err := meta.(*Client).getComputeClient()
+	if err != nil {
+		return err
+	}
+
+	pending := make([]string, 0, len(instanceStates))
+	for _, state := range instanceStates {
+		if state != target {
+			pending = append(pending, string(state))
+		}
+	}
+
+	conf := waitConf{
+		Description:     fmt.Sprintf("instance %s to be %s", name, target),
+		Pending:         pending,
+		Target:          []string{string(target)},
+		Refresh:         instanceState(computeClient.Instances(), name, id),
+		Timeout:         timeout,
+		MinPollInterval: 5 * time.Second,
+	}
+	if _, err := waitForState(meta.(*Client).stopContext, conf); err != nil {
+		return newAPIError(err)
+	}
+	return nil
+}
+
+// instanceState reports the state of an instance, or waitStateDeleted once it
+// no longer exists. An instance in the error state fails the wait with its
+// error reason.
+func instanceState(resClient *compute.InstancesClient, name, id string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		input := &compute.GetInstanceInput{
+			Name: name,
+			ID:   id,
+		}
+		info, err := resClient.GetInstance(input)
+		if err != nil {
+			if wasNotFoundError(err) {
+				return nil, waitStateDeleted, nil
+			}
+			return nil, "", err
+		}
+		if info.State == compute.InstanceError {
+			return info, string(info.State), fmt.Errorf("Instance %s is in the error state: %s", name, info.ErrorReason)
+		}
+		return info, string(info.State), nil
+	}
+}
+
+// qualifyInstanceInput returns a copy of the launch plan of an instance with
+// the names of the objects it refers to qualified, as the Instances client
+// qualifies them.
+func qualifyInstanceInput(client *computeCollectionClient, input *compute.CreateInstanceInput) *compute.CreateInstanceInput {
+	qualified := *input
+	qualified.Name = client.qualify(input.Name)
+
+	qualified.SSHKeys = make([]string, len(input.SSHKeys))
+	for i, key := range input.SSHKeys {
+		qualified.SSHKeys[i] = client.qualify(key)
+	}
+
+	qualified.Storage = make([]compute.StorageAttachmentInput, len(input.Storage))
+	for i, attachment := range input.Storage {
+		attachment.Volume = client.qualify(attachment.Volume)
+		qualified.Storage[i] = attachment
+	}
+
+	qualified.Networking = make(map[string]compute.NetworkingInfo, len(input.Networking))
+	for k, v := range input.Networking {
+		// NAT reservations of IP network interfaces are IP reservations
+		prefix := compute.ReservationPrefix
+		if v.IPNetwork != "" {
+			v.IPNetwork = client.qualify(v.IPNetwork)
+			prefix = compute.ReservationIPPrefix
+		}
+		v.Vnic = client.qualify(v.Vnic)
+		if v.Nat != nil {
+			nat := make([]string, len(v.Nat))
+			for i, name := range v.Nat {
+				nat[i] = name
+				if !strings.HasPrefix(name, "ippool:/oracle") {
+					nat[i] = fmt.Sprintf("%s:%s", prefix, client.qualify(name))
+				}
+			}
+			v.Nat = nat
+		}
+		v.VnicSets = qualifyNames(client, v.VnicSets)
+		v.SecLists = qualifyNames(client, v.SecLists)
+		qualified.Networking[k] = v
+	}
+
+	return &qualified
+}
+
+func qualifyNames(client *computeCollectionClient, names []string) []string {
+	if names == nil {
+		return nil
+	}
+	qualified := make([]string, len(names))
+	for i, name := range names {
+		qualified[i] = client.qualify(name)
+	}
+	return qualified
+}
+
+func resourceInstanceDelete(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
 	log.Printf("[DEBUG] Deleting instance %s", name)
 
-	if err := resClient.DeleteInstance(input); err != nil {
+	if err := deleteInstance(meta, name, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
 		return fmt.Errorf("Error deleting instance %s: %s", name, err)
 	}
 
@@ -642,21 +1075,42 @@ func getStorageAttachments(d *schema.ResourceData) []compute.StorageAttachmentIn
 	return storageAttachments
 }
 
-// Parses instance_attributes from a string to a map[string]interface and returns any errors.
-func getInstanceAttributes(d *schema.ResourceData) (map[string]interface{}, error) {
-	var attrs map[string]interface{}
+// importStorageAttachments seeds the storage block of an imported instance with
+// every volume attached to it, as there is no configuration to tell which
+// attachments the instance manages.
+func importStorageAttachments(d *schema.ResourceData, meta interface{}) error {
+	computeClient, err := meta.(*Client).getComputeClient()
+	if err != nil {
+		return err
+	}
 
-	// Empty instance attributes
-	attributes, ok := d.GetOk("instance_attributes")
-	if !ok {
-		return attrs, nil
+	input := &compute.GetInstanceInput{
+		ID:   d.Id(),
+		Name: d.Get("name").(string),
+	}
+	instance, err := computeClient.Instances().GetInstance(input)
+	if err != nil {
+		return fmt.Errorf("Error reading instance %s: %s", input.Name, newAPIError(err))
 	}
 
-	if err := json.Unmarshal([]byte(attributes.(string)), &attrs); err != nil {
-		return attrs, fmt.Errorf("Cannot parse attributes as json: %s", err)
+	storage := make([]map[string]interface{}, 0, len(instance.Storage))
+	for _, attachment := range instance.Storage {
+		storage = append(storage, map[string]interface{}{
+			"index":  attachment.Index,
+			"volume": attachment.StorageVolumeName,
+		})
 	}
+	return d.Set("storage", storage)
+}
 
-	return attrs, nil
+// Parses instance_attributes from a string to a map[string]interface, merges
+// the user_data and metadata into it, and returns any errors.
+func getInstanceAttributes(d *schema.ResourceData) (map[string]interface{}, error) {
+	return expandInstanceAttributes(
+		d.Get("instance_attributes").(string),
+		d.Get("user_data").(string),
+		d.Get("gzip_user_data").(bool),
+		d.Get("metadata").(map[string]interface{}))
 }
 
 // Reads attributes from the returned instance object, and sets the computed attributes string
@@ -982,19 +1436,35 @@ func readNetworkInterfaces(d *schema.ResourceData, ifaces map[string]compute.Net
 	return d.Set("networking_info", result)
 }
 
-// Flattens the returned slice of storage attachments to a map
-func readStorageAttachments(d *schema.ResourceData, attachments []compute.StorageAttachment) error {
+// Flattens the returned slice of storage attachments to a map, along with the
+// current size of each attached volume. Only the indexes declared in the
+// storage block are tracked, volumes attached at any other index are managed
+// by opc_compute_storage_attachment.
+func readStorageAttachments(d *schema.ResourceData, volumeClient *compute.StorageVolumeClient, attachments []compute.StorageAttachment) error {
 	result := make([]map[string]interface{}, 0)
 
 	if attachments == nil || len(attachments) == 0 {
 		return d.Set("storage", nil)
 	}
 
+	managed := make(map[int]bool)
+	for _, i := range d.Get("storage").(*schema.Set).List() {
+		managed[i.(map[string]interface{})["index"].(int)] = true
+	}
+
 	for _, attachment := range attachments {
+		if !managed[attachment.Index] {
+			continue
+		}
 		res := make(map[string]interface{})
 		res["index"] = attachment.Index
 		res["volume"] = attachment.StorageVolumeName
 		res["name"] = attachment.Name
+		size, err := getStorageVolumeSize(volumeClient, attachment.StorageVolumeName)
+		if err != nil {
+			return fmt.Errorf("Error reading size of storage volume %s: %s", attachment.StorageVolumeName, newAPIError(err))
+		}
+		res["size"] = size
 		result = append(result, res)
 	}
 	return d.Set("storage", result)
@@ -2,6 +2,8 @@ package opc
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-oracle-terraform/compute"
@@ -33,6 +35,31 @@ func TestAccOPCInstance_basic(t *testing.T) {
 	})
 }
 
+func TestAccOPCInstance_userData(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceUserData(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "metadata.environment", "test"),
+					resource.TestMatchResourceAttr(resName, "attributes", regexp.MustCompile(`"userdata":\{"environment":"test","role":"web","user_data":"#cloud-config\\npackages: \[httpd\]\\n"\}`)),
+				),
+			},
+			{
+				Config:      testAccInstanceUserDataConflict(rInt),
+				ExpectError: regexp.MustCompile(`metadata key "role" conflicts`),
+			},
+		},
+	})
+}
+
 func TestAccOPCInstance_sharedNetworking(t *testing.T) {
 	rInt := acctest.RandInt()
 	resName := "opc_compute_instance.test"
@@ -179,6 +206,78 @@ func TestAccOPCInstance_storage(t *testing.T) {
 	})
 }
 
+func TestAccOPCInstance_updateStorage(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+	var instanceID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceUpdateStorage(rInt, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					testAccCheckInstanceID(resName, &instanceID),
+					resource.TestCheckResourceAttr(resName, "storage.#", "1"),
+				),
+			},
+			{
+				Config: testAccInstanceUpdateStorage(rInt, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					testAccCheckInstanceID(resName, &instanceID),
+					resource.TestCheckResourceAttr(resName, "storage.#", "2"),
+				),
+			},
+			{
+				Config: testAccInstanceUpdateStorage(rInt, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					testAccCheckInstanceID(resName, &instanceID),
+					resource.TestCheckResourceAttr(resName, "storage.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCInstance_resizeStorage(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceResizeStorage(rInt, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					testAccCheckInstanceStorageSize(resName, "1"),
+				),
+			},
+			{
+				Config: testAccInstanceResizeStorage(rInt, 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("opc_compute_storage_volume.foo", "size", "2"),
+				),
+			},
+			{
+				// The storage block reports the new size once refreshed
+				Config: testAccInstanceResizeStorage(rInt, 2),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					testAccCheckInstanceStorageSize(resName, "2"),
+				),
+			},
+		},
+	})
+}
+
 func TestAccOPCInstance_emptyLabel(t *testing.T) {
 	resName := "opc_compute_instance.test"
 	rInt := acctest.RandInt()
@@ -282,6 +381,84 @@ func TestAccOPCInstance_Restart(t *testing.T) {
 	})
 }
 
+func TestAccOPCInstance_reshape(t *testing.T) {
+	resName := "opc_compute_instance.test"
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccOPCCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceReshape(rInt, "oc3"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "shape", "oc3"),
+				),
+			},
+			{
+				// The instance is relaunched from its boot volume, and the
+				// volume attached by opc_compute_storage_attachment is
+				// attached again at the same index
+				Config: testAccInstanceReshape(rInt, "oc4"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccOPCCheckInstanceExists,
+					resource.TestCheckResourceAttr(resName, "shape", "oc4"),
+					resource.TestCheckResourceAttr(resName, "state", string(compute.InstanceRunning)),
+					resource.TestCheckResourceAttr(resName, "storage.#", "1"),
+					// The association follows the vcable of the new instance
+					resource.TestCheckResourceAttrPair("opc_compute_ip_association.test", "vcable", resName, "vcable"),
+				),
+			},
+			{
+				// The storage attachment picks up the new attachment on refresh
+				Config: testAccInstanceReshape(rInt, "oc4"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageAttachmentExists,
+					resource.TestCheckResourceAttr("opc_compute_storage_attachment.test", "index", "2"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckInstanceID records the ID of the instance, and checks that it
+// hasn't changed since it was last recorded.
+func testAccCheckInstanceID(resName string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", resName)
+		}
+		if *id != "" && *id != rs.Primary.ID {
+			return fmt.Errorf("Instance %s was recreated: %s", *id, rs.Primary.ID)
+		}
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+// testAccCheckInstanceStorageSize checks the size of the volume of the only
+// storage block of an instance, whose key is a hash of the block.
+func testAccCheckInstanceStorageSize(resName, size string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", resName)
+		}
+		for k, v := range rs.Primary.Attributes {
+			if strings.HasPrefix(k, "storage.") && strings.HasSuffix(k, ".size") {
+				if v != size {
+					return fmt.Errorf("Expected %s to be %s, got %s", k, size, v)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("No storage size found for %s", resName)
+	}
+}
+
 func testAccOPCCheckInstanceExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.Instances()
 
@@ -338,6 +515,50 @@ JSON
 }`, rInt, TestImageList)
 }
 
+func testAccInstanceUserData(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name = "acc-test-instance-%d"
+  label = "TestAccOPCInstance_userData"
+  shape = "oc3"
+  image_list = "%s"
+  instance_attributes = jsonencode({ userdata = { role = "web" } })
+  user_data = "#cloud-config\npackages: [httpd]\n"
+  metadata = {
+    environment = "test"
+  }
+}`, rInt, TestImageList)
+}
+
+func testAccInstanceGzipUserData(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name = "acc-test-instance-%d"
+  label = "TestAccOPCInstance_importUserData"
+  shape = "oc3"
+  image_list = "%s"
+  user_data = "#cloud-config\npackages: [httpd]\n"
+  gzip_user_data = true
+  metadata = {
+    environment = "test"
+  }
+}`, rInt, TestImageList)
+}
+
+func testAccInstanceUserDataConflict(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_instance" "test" {
+  name = "acc-test-instance-%d"
+  label = "TestAccOPCInstance_userData"
+  shape = "oc3"
+  image_list = "%s"
+  instance_attributes = jsonencode({ userdata = { role = "web" } })
+  metadata = {
+    role = "db"
+  }
+}`, rInt, TestImageList)
+}
+
 func testAccInstanceSharedNetworking(rInt int) string {
 	return fmt.Sprintf(`
 resource "opc_compute_instance" "test" {
@@ -448,6 +669,57 @@ resource "opc_compute_instance" "test" {
 }`, rInt, rInt, rInt, TestImageList)
 }
 
+func testAccInstanceUpdateStorage(rInt int, attached bool) string {
+	storage := ""
+	if attached {
+		storage = `
+	storage {
+		volume = "${opc_compute_storage_volume.bar.name}"
+		index = 2
+	}`
+	}
+	return fmt.Sprintf(`
+resource "opc_compute_storage_volume" "foo" {
+  name = "acc-test-instance-%d"
+  size = 1
+}
+
+resource "opc_compute_storage_volume" "bar" {
+  name = "acc-test-instance-2-%d"
+  size = 1
+}
+
+resource "opc_compute_instance" "test" {
+	name = "acc-test-instance-%d"
+	label = "TestAccOPCInstance_updateStorage"
+	shape = "oc3"
+	image_list = "%s"
+	storage {
+		volume = "${opc_compute_storage_volume.foo.name}"
+		index = 1
+	}%s
+}`, rInt, rInt, rInt, TestImageList, storage)
+}
+
+func testAccInstanceResizeStorage(rInt, size int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_storage_volume" "foo" {
+  name = "acc-test-instance-%d"
+  size = %d
+}
+
+resource "opc_compute_instance" "test" {
+	name = "acc-test-instance-%d"
+	label = "TestAccOPCInstance_resizeStorage"
+	shape = "oc3"
+	image_list = "%s"
+	storage {
+		volume = "${opc_compute_storage_volume.foo.name}"
+		index = 1
+	}
+}`, rInt, size, rInt, TestImageList)
+}
+
 func testAccInstanceEmptyLabel(rInt int) string {
 	return fmt.Sprintf(`
 resource "opc_compute_instance" "test" {
@@ -579,6 +851,61 @@ resource "opc_compute_instance" "test" {
 }`, rInt, rInt, rInt)
 }
 
+func testAccInstanceReshape(rInt int, shape string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_image_list" "test" {
+  name = "acc-test-instance-%d"
+  description = "testing instance reshape"
+}
+
+resource "opc_compute_image_list_entry" "test" {
+  name = "${opc_compute_image_list.test.name}"
+  machine_images = [ "/oracle/public/oel_6.7_apaas_16.4.5_1610211300" ]
+  version = 1
+}
+
+resource "opc_compute_storage_volume" "test" {
+  name = "acc-test-instance-%d"
+  size = "20"
+  image_list = "${opc_compute_image_list.test.name}"
+  image_list_entry = "${opc_compute_image_list_entry.test.version}"
+  bootable = true
+}
+
+resource "opc_compute_storage_volume" "data" {
+  name = "acc-test-instance-data-%d"
+  size = 1
+}
+
+resource "opc_compute_instance" "test" {
+  name = "acc-test-instance-%d"
+  label = "TestAccOPCInstance_reshape"
+  shape = "%s"
+  boot_order = [1]
+  storage {
+    volume = "${opc_compute_storage_volume.test.name}"
+    index = 1
+  }
+}
+
+resource "opc_compute_storage_attachment" "test" {
+  instance = "${opc_compute_instance.test.name}"
+  storage_volume = "${opc_compute_storage_volume.data.name}"
+  index = 2
+}
+
+resource "opc_compute_ip_reservation" "test" {
+  name = "acc-test-instance-%d"
+  parent_pool = "/oracle/public/ippool"
+  permanent = true
+}
+
+resource "opc_compute_ip_association" "test" {
+  vcable = "${opc_compute_instance.test.vcable}"
+  parent_pool = "ipreservation:${opc_compute_ip_reservation.test.name}"
+}`, rInt, rInt, rInt, rInt, shape, rInt)
+}
+
 func testAccInstanceHostname(rInt int) string {
 	return fmt.Sprintf(`
 resource "opc_compute_instance" "test" {
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -12,11 +11,15 @@ func resourceOPCIPAddressAssociation() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceOPCIPAddressAssociationCreate,
 		Read:   resourceOPCIPAddressAssociationRead,
+		// Only moving tags between tags and tags_map updates it in place
+		Update: resourceOPCIPAddressAssociationRead,
 		Delete: resourceOPCIPAddressAssociationDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsForceNew,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -38,7 +41,9 @@ func resourceOPCIPAddressAssociation() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
-			"tags": tagsForceNewSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"uri": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -66,7 +71,7 @@ func resourceOPCIPAddressAssociationCreate(d *schema.ResourceData, meta interfac
 		input.Vnic = vnic.(string)
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -77,7 +82,7 @@ func resourceOPCIPAddressAssociationCreate(d *schema.ResourceData, meta interfac
 
 	info, err := resClient.CreateIPAddressAssociation(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating IP Address Association: %s", err)
+		return fmt.Errorf("Error creating IP Address Association: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -98,15 +103,15 @@ func resourceOPCIPAddressAssociationRead(d *schema.ResourceData, meta interface{
 	result, err := resClient.GetIPAddressAssociation(&getInput)
 	if err != nil {
 		// IP Address Association does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading IP Address Association %s: %s", name, err)
+		return fmt.Errorf("Error reading IP Address Association %s: %s", name, newAPIError(err))
 	}
 	if result == nil {
 		d.SetId("")
-		return fmt.Errorf("Error reading IP Address Association %s: %s", name, err)
+		return fmt.Errorf("Error reading IP Address Association %s: %s", name, newAPIError(err))
 	}
 
 	d.Set("name", result.Name)
@@ -114,7 +119,7 @@ func resourceOPCIPAddressAssociationRead(d *schema.ResourceData, meta interface{
 	d.Set("vnic", result.Vnic)
 	d.Set("description", result.Description)
 	d.Set("uri", result.URI)
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -132,7 +137,7 @@ func resourceOPCIPAddressAssociationDelete(d *schema.ResourceData, meta interfac
 		Name: name,
 	}
 	if err := resClient.DeleteIPAddressAssociation(&input); err != nil {
-		return fmt.Errorf("Error deleting IP Address Association: %s", err)
+		return fmt.Errorf("Error deleting IP Address Association: %s", newAPIError(err))
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -18,6 +17,8 @@ func resourceOPCIPAddressPrefixSet() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -37,7 +38,9 @@ func resourceOPCIPAddressPrefixSet() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
-			"tags": tagsOptionalSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"uri": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -62,7 +65,7 @@ func resourceOPCIPAddressPrefixSetCreate(d *schema.ResourceData, meta interface{
 		input.IPAddressPrefixes = prefixes
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -73,7 +76,7 @@ func resourceOPCIPAddressPrefixSetCreate(d *schema.ResourceData, meta interface{
 
 	info, err := resClient.CreateIPAddressPrefixSet(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating IP Address Prefix Set: %s", err)
+		return fmt.Errorf("Error creating IP Address Prefix Set: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -94,11 +97,11 @@ func resourceOPCIPAddressPrefixSetRead(d *schema.ResourceData, meta interface{})
 	result, err := resClient.GetIPAddressPrefixSet(&input)
 	if err != nil {
 		// IP Address Prefix Set does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading IP Address Prefix Set %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading IP Address Prefix Set %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -112,7 +115,7 @@ func resourceOPCIPAddressPrefixSetRead(d *schema.ResourceData, meta interface{})
 	if err := setStringList(d, "prefixes", result.IPAddressPrefixes); err != nil {
 		return err
 	}
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -134,7 +137,7 @@ func resourceOPCIPAddressPrefixSetUpdate(d *schema.ResourceData, meta interface{
 		input.IPAddressPrefixes = prefixes
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -145,7 +148,7 @@ func resourceOPCIPAddressPrefixSetUpdate(d *schema.ResourceData, meta interface{
 
 	info, err := resClient.UpdateIPAddressPrefixSet(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating IP Address Prefix Set: %s", err)
+		return fmt.Errorf("Error updating IP Address Prefix Set: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -164,7 +167,7 @@ func resourceOPCIPAddressPrefixSetDelete(d *schema.ResourceData, meta interface{
 		Name: name,
 	}
 	if err := resClient.DeleteIPAddressPrefixSet(&input); err != nil {
-		return fmt.Errorf("Error deleting IP Address Prefix Set: %s", err)
+		return fmt.Errorf("Error deleting IP Address Prefix Set: %s", newAPIError(err))
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -18,6 +17,8 @@ func resourceOPCIPAddressReservation() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -33,7 +34,9 @@ func resourceOPCIPAddressReservation() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
-			"tags": tagsOptionalSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"ip_address": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -57,7 +60,7 @@ func resourceOPCIPAddressReservationCreate(d *schema.ResourceData, meta interfac
 		Name:          d.Get("name").(string),
 		IPAddressPool: d.Get("ip_address_pool").(string),
 	}
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -67,7 +70,7 @@ func resourceOPCIPAddressReservationCreate(d *schema.ResourceData, meta interfac
 
 	info, err := resClient.CreateIPAddressReservation(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating IP Address Reservation: %s", err)
+		return fmt.Errorf("Error creating IP Address Reservation: %s", newAPIError(err))
 	}
 	d.SetId(info.Name)
 	return resourceOPCIPAddressReservationRead(d, meta)
@@ -87,11 +90,11 @@ func resourceOPCIPAddressReservationRead(d *schema.ResourceData, meta interface{
 	result, err := resClient.GetIPAddressReservation(&input)
 	if err != nil {
 		// IP Address Reservation does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ip address reservation %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading ip address reservation %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -105,7 +108,7 @@ func resourceOPCIPAddressReservationRead(d *schema.ResourceData, meta interface{
 	d.Set("ip_address", result.IPAddress)
 	d.Set("uri", result.URI)
 
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -122,7 +125,7 @@ func resourceOPCIPAddressReservationUpdate(d *schema.ResourceData, meta interfac
 		Name:          d.Get("name").(string),
 		IPAddressPool: d.Get("ip_address_pool").(string),
 	}
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -132,7 +135,7 @@ func resourceOPCIPAddressReservationUpdate(d *schema.ResourceData, meta interfac
 
 	info, err := resClient.UpdateIPAddressReservation(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating IP Address Reservation: %s", err)
+		return fmt.Errorf("Error updating IP Address Reservation: %s", newAPIError(err))
 	}
 	d.SetId(info.Name)
 	return resourceOPCIPAddressReservationRead(d, meta)
@@ -150,7 +153,7 @@ func resourceOPCIPAddressReservationDelete(d *schema.ResourceData, meta interfac
 		Name: name,
 	}
 	if err := resClient.DeleteIPAddressReservation(&input); err != nil {
-		return fmt.Errorf("Error deleting IP Address Reservation: %+v", err)
+		return fmt.Errorf("Error deleting IP Address Reservation: %+v", newAPIError(err))
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -54,7 +53,7 @@ func resourceOPCIPAssociationCreate(d *schema.ResourceData, meta interface{}) er
 	}
 	info, err := resClient.CreateIPAssociation(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating ip association between vcable %s and parent pool %s: %s", vCable, parentPool, err)
+		return fmt.Errorf("Error creating ip association between vcable %s and parent pool %s: %s", vCable, parentPool, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -77,11 +76,11 @@ func resourceOPCIPAssociationRead(d *schema.ResourceData, meta interface{}) erro
 	result, err := resClient.GetIPAssociation(&input)
 	if err != nil {
 		// IP Association does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ip association '%s': %s", name, err)
+		return fmt.Errorf("Error reading ip association '%s': %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -108,7 +107,7 @@ func resourceOPCIPAssociationDelete(d *schema.ResourceData, meta interface{}) er
 		Name: name,
 	}
 	if err := resClient.DeleteIPAssociation(&input); err != nil {
-		return fmt.Errorf("Error deleting ip association '%s': %s", name, err)
+		return fmt.Errorf("Error deleting ip association '%s': %s", name, newAPIError(err))
 	}
 
 	return nil
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -18,6 +17,8 @@ func resourceOPCIPNetwork() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -52,7 +53,9 @@ func resourceOPCIPNetwork() *schema.Resource {
 				Computed: true,
 			},
 
-			"tags": tagsOptionalSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 		},
 	}
 }
@@ -85,14 +88,14 @@ func resourceOPCIPNetworkCreate(d *schema.ResourceData, meta interface{}) error
 		input.IPNetworkExchange = ipEx.(string)
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
 
 	info, err := resClient.CreateIPNetwork(input)
 	if err != nil {
-		return fmt.Errorf("Error creating IP Network '%s': %v", name, err)
+		return fmt.Errorf("Error creating IP Network '%s': %v", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -114,11 +117,11 @@ func resourceOPCIPNetworkRead(d *schema.ResourceData, meta interface{}) error {
 
 	result, err := resClient.GetIPNetwork(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading IP Network '%s': %v", name, err)
+		return fmt.Errorf("Error reading IP Network '%s': %v", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -132,7 +135,7 @@ func resourceOPCIPNetworkRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("description", result.Description)
 	d.Set("public_napt_enabled", result.PublicNaptEnabled)
 	d.Set("uri", result.URI)
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -168,14 +171,14 @@ func resourceOPCIPNetworkUpdate(d *schema.ResourceData, meta interface{}) error
 		input.IPNetworkExchange = ipEx.(string)
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
 
 	info, err := resClient.UpdateIPNetwork(input)
 	if err != nil {
-		return fmt.Errorf("Error updating IP Network '%s': %v", name, err)
+		return fmt.Errorf("Error updating IP Network '%s': %v", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -196,7 +199,7 @@ func resourceOPCIPNetworkDelete(d *schema.ResourceData, meta interface{}) error
 	}
 
 	if err := resClient.DeleteIPNetwork(input); err != nil {
-		return fmt.Errorf("Error deleting IP Network '%s': %v", name, err)
+		return fmt.Errorf("Error deleting IP Network '%s': %v", name, newAPIError(err))
 	}
 	return nil
 }
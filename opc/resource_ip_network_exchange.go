@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -13,11 +12,15 @@ func resourceOPCIPNetworkExchange() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceOPCIPNetworkExchangeCreate,
 		Read:   resourceOPCIPNetworkExchangeRead,
+		// Only moving tags between tags and tags_map updates it in place
+		Update: resourceOPCIPNetworkExchangeRead,
 		Delete: resourceOPCIPNetworkExchangeDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsForceNew,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -29,7 +32,9 @@ func resourceOPCIPNetworkExchange() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
-			"tags": tagsForceNewSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"uri": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -49,7 +54,7 @@ func resourceOPCIPNetworkExchangeCreate(d *schema.ResourceData, meta interface{}
 	}
 
 	log.Printf("[DEBUG] Creating ip network exchange '%s'", input.Name)
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -60,7 +65,7 @@ func resourceOPCIPNetworkExchangeCreate(d *schema.ResourceData, meta interface{}
 
 	info, err := resClient.CreateIPNetworkExchange(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating IP Network Exchange: %s", err)
+		return fmt.Errorf("Error creating IP Network Exchange: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -82,11 +87,11 @@ func resourceOPCIPNetworkExchangeRead(d *schema.ResourceData, meta interface{})
 	result, err := resClient.GetIPNetworkExchange(&input)
 	if err != nil {
 		// IP NetworkExchange does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ip network exchange %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading ip network exchange %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -98,7 +103,7 @@ func resourceOPCIPNetworkExchangeRead(d *schema.ResourceData, meta interface{})
 	d.Set("description", result.Description)
 	d.Set("uri", result.URI)
 
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 
@@ -118,7 +123,7 @@ func resourceOPCIPNetworkExchangeDelete(d *schema.ResourceData, meta interface{}
 		Name: name,
 	}
 	if err := resClient.DeleteIPNetworkExchange(&input); err != nil {
-		return fmt.Errorf("Error deleting IP Network Exchange '%s': %+v", name, err)
+		return fmt.Errorf("Error deleting IP Network Exchange '%s': %+v", name, newAPIError(err))
 	}
 	return nil
 }
@@ -102,6 +102,46 @@ func TestAccOPCIPNetwork_UpdateName(t *testing.T) {
 	})
 }
 
+func TestAccOPCIPNetwork_DefaultTags(t *testing.T) {
+	rInt := acctest.RandInt()
+	resName := "opc_compute_ip_network.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: opcResourceCheck(resName, testAccOPCCheckIPNetworkDestroyed),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCIPNetworkConfig_DefaultTags(rInt, `["env:test"]`),
+				Check: resource.ComposeTestCheckFunc(
+					opcResourceCheck(resName, testAccOPCCheckIPNetworkExists),
+					resource.TestCheckResourceAttr(resName, "tags.#", "1"),
+					resource.TestCheckResourceAttr(resName, "tags.0", "app:web"),
+					resource.TestCheckResourceAttr(resName, "tags_all.#", "2"),
+					resource.TestCheckResourceAttr(resName, "tags_all.0", "app:web"),
+					resource.TestCheckResourceAttr(resName, "tags_all.1", "env:test"),
+				),
+			},
+			{
+				Config: testAccOPCIPNetworkConfig_DefaultTags(rInt, `["env:prod"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "tags.#", "1"),
+					resource.TestCheckResourceAttr(resName, "tags_all.#", "2"),
+					resource.TestCheckResourceAttr(resName, "tags_all.1", "env:prod"),
+				),
+			},
+			{
+				Config: testAccOPCIPNetworkConfig_DefaultTags(rInt, `[]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "tags.#", "1"),
+					resource.TestCheckResourceAttr(resName, "tags_all.#", "1"),
+					resource.TestCheckResourceAttr(resName, "tags_all.0", "app:web"),
+				),
+			},
+		},
+	})
+}
+
 func testAccOPCIPNetworkConfig_Basic(rInt int) string {
 	return fmt.Sprintf(`
 resource "opc_compute_ip_network" "test" {
@@ -157,3 +197,16 @@ func testAccOPCCheckIPNetworkDestroyed(state *OPCResourceState) error {
 	}
 	return nil
 }
+
+func testAccOPCIPNetworkConfig_DefaultTags(rInt int, defaultTags string) string {
+	return fmt.Sprintf(`
+provider "opc" {
+  default_tags = %s
+}
+
+resource "opc_compute_ip_network" "test" {
+  name = "testing-ip-network-%d"
+  ip_address_prefix = "10.0.12.0/24"
+  tags = ["app:web"]
+}`, defaultTags, rInt)
+}
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -12,11 +11,15 @@ func resourceOPCIPReservation() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceOPCIPReservationCreate,
 		Read:   resourceOPCIPReservationRead,
+		// Only moving tags between tags and tags_map updates it in place
+		Update: resourceOPCIPReservationRead,
 		Delete: resourceOPCIPReservationDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsForceNew,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -34,7 +37,9 @@ func resourceOPCIPReservation() *schema.Resource {
 				Default:  string(compute.PublicReservationPool),
 				ForceNew: true,
 			},
-			"tags": tagsForceNewSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"ip": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -55,7 +60,7 @@ func resourceOPCIPReservationCreate(d *schema.ResourceData, meta interface{}) er
 		Permanent:  d.Get("permanent").(bool),
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		reservation.Tags = tags
 	}
@@ -89,11 +94,11 @@ func resourceOPCIPReservationRead(d *schema.ResourceData, meta interface{}) erro
 	result, err := resClient.GetIPReservation(&input)
 	if err != nil {
 		// IP Reservation does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ip reservation %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading ip reservation %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -105,7 +110,7 @@ func resourceOPCIPReservationRead(d *schema.ResourceData, meta interface{}) erro
 	d.Set("parent_pool", result.ParentPool)
 	d.Set("permanent", result.Permanent)
 
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 
@@ -48,6 +48,51 @@ func TestAccOPCIPreservation_OptionalParentPool(t *testing.T) {
 	})
 }
 
+func TestAccOPCIPReservation_tagsMap(t *testing.T) {
+	rInt := acctest.RandInt()
+	resName := "opc_compute_ip_reservation.test"
+	var ip string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckIPReservationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCIPReservationTags(rInt, `tags = ["app", "owner=jdoe"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckIPReservationExists,
+					resource.TestCheckResourceAttr(resName, "tags.#", "2"),
+					resource.TestCheckResourceAttr(resName, "tags_map.%", "0"),
+					func(s *terraform.State) error {
+						ip = s.RootModule().Resources[resName].Primary.Attributes["ip"]
+						return nil
+					},
+				),
+			},
+			{
+				// Moving the tags to the tags_map doesn't replace the reservation
+				Config: testAccOPCIPReservationTags(rInt, `tags = ["app"]
+  tags_map = {
+    owner = "jdoe"
+  }`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "tags.#", "1"),
+					resource.TestCheckResourceAttr(resName, "tags_map.%", "1"),
+					resource.TestCheckResourceAttr(resName, "tags_map.owner", "jdoe"),
+					resource.TestCheckResourceAttr(resName, "tags_all.#", "2"),
+					func(s *terraform.State) error {
+						if newIP := s.RootModule().Resources[resName].Primary.Attributes["ip"]; newIP != ip {
+							return fmt.Errorf("Expected the IP reservation not to be replaced, its ip changed from %s to %s", ip, newIP)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckIPReservationExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.IPReservations()
 
@@ -101,3 +146,13 @@ resource "opc_compute_ip_reservation" "test" {
   permanent   = true
 }`, rInt)
 }
+
+func testAccOPCIPReservationTags(rInt int, tags string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_ip_reservation" "test" {
+  name        = "acc-test-ip-reservation-%d"
+  parent_pool = "/oracle/public/ippool"
+  permanent   = true
+  %s
+}`, rInt, tags)
+}
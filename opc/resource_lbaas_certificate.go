@@ -3,8 +3,8 @@ package opc
 import (
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/lbaas"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -16,6 +16,11 @@ func resourceLBaaSSSLCertificate() *schema.Resource {
 		Read:   resourceSSLCertificateRead,
 		Delete: resourceSSLCertificateDelete,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:         schema.TypeString,
@@ -71,6 +76,10 @@ func resourceSSLCertificateCreate(d *schema.ResourceData, meta interface{}) erro
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	sslCertClient := lbaasClient.SSLCertificateClient()
 
 	input := lbaas.CreateSSLCertificateInput{
@@ -84,12 +93,21 @@ func resourceSSLCertificateCreate(d *schema.ResourceData, meta interface{}) erro
 		input.PrivateKey = key.(string)
 	}
 
-	info, err := sslCertClient.CreateSSLCertificate(&input)
-	if err != nil {
+	contentType := lbaas.ContentTypeServerCertificateJSON
+	if input.Trusted {
+		contentType = lbaas.ContentTypeTrustedCertificateJSON
+	}
+
+	if err := requestClient.do("POST", sslCertClient.ContainerPath, sslCertClient.Accept, contentType, &input); err != nil {
+		return fmt.Errorf("Error creating Load Balancer Server Pool: %s", newAPIError(err))
+	}
+
+	d.SetId(input.Name)
+
+	if err := waitForLBaaSCreate(meta, "SSL Certificate "+d.Id(), d.Timeout(schema.TimeoutCreate), sslCertificateState(sslCertClient, d.Id())); err != nil {
 		return fmt.Errorf("Error creating Load Balancer Server Pool: %s", err)
 	}
 
-	d.SetId(info.Name)
 	return resourceSSLCertificateRead(d, meta)
 }
 
@@ -104,11 +122,11 @@ func resourceSSLCertificateRead(d *schema.ResourceData, meta interface{}) error
 	result, err := sslCertClient.GetSSLCertificate(name)
 	if err != nil {
 		// SSLCertificate does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Server Pool %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading Server Pool %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -136,15 +154,35 @@ func resourceSSLCertificateDelete(d *schema.ResourceData, meta interface{}) erro
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	sslCertClient := lbaasClient.SSLCertificateClient()
 	name := d.Id()
 
-	if _, err := sslCertClient.DeleteSSLCertificate(name); err != nil {
+	path := fmt.Sprintf(sslCertClient.ResourceRootPath, name)
+	if err := requestClient.do("DELETE", path, sslCertClient.Accept, "", nil); err != nil {
+		return fmt.Errorf("Error deleting SSLCertificate: %v", newAPIError(err))
+	}
+
+	if err := waitForLBaaSDelete(meta, "SSL Certificate "+d.Id(), d.Timeout(schema.TimeoutDelete), sslCertificateState(sslCertClient, d.Id())); err != nil {
 		return fmt.Errorf("Error deleting SSLCertificate: %v", err)
 	}
 	return nil
 }
 
+// sslCertificateState reads the state of the certificate with the given name.
+func sslCertificateState(sslCertClient *lbaas.SSLCertificateClient, name string) func() (lbaas.LBaaSState, error) {
+	return func() (lbaas.LBaaSState, error) {
+		info, err := sslCertClient.GetSSLCertificate(name)
+		if err != nil {
+			return "", err
+		}
+		return info.State, nil
+	}
+}
+
 // simple check to validate content is in the expected PEM format
 func validateIsPEMFormat(v interface{}, k string) (ws []string, errors []error) {
 	if !strings.HasPrefix(v.(string), "-----BEGIN") {
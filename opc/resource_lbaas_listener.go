@@ -2,8 +2,8 @@ package opc
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/lbaas"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -19,6 +19,14 @@ func resourceLBaaSListener() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"load_balancer": {
 				Type:         schema.TypeString,
@@ -83,6 +91,8 @@ func resourceLBaaSListener() *schema.Resource {
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"virtual_hosts": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -115,6 +125,10 @@ func resourceListenerCreate(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	listenerClient := lbaasClient.ListenerClient()
 	lb := getLoadBalancerContextFromID(d.Get("load_balancer").(string))
 
@@ -146,7 +160,7 @@ func resourceListenerCreate(d *schema.ResourceData, meta interface{}) error {
 		input.SSLCerts = sslCerts
 	}
 
-	tags := getStringSet(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -156,12 +170,17 @@ func resourceListenerCreate(d *schema.ResourceData, meta interface{}) error {
 		input.VirtualHosts = virtualHosts
 	}
 
-	info, err := listenerClient.CreateListener(lb, &input)
-	if err != nil {
+	path := fmt.Sprintf(listenerClient.ContainerPath, lb.Region, lb.Name)
+	if err := requestClient.do("POST", path, listenerClient.Accept, listenerClient.ContentType, &input); err != nil {
+		return fmt.Errorf("Error creating Load Balancer Listener: %s", newAPIError(err))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, input.Name))
+
+	if err := waitForLBaaSCreate(meta, "Listener "+d.Id(), d.Timeout(schema.TimeoutCreate), listenerState(listenerClient, d.Id())); err != nil {
 		return fmt.Errorf("Error creating Load Balancer Listener: %s", err)
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, info.Name))
 	return resourceListenerRead(d, meta)
 }
 
@@ -177,11 +196,11 @@ func resourceListenerRead(d *schema.ResourceData, meta interface{}) error {
 	result, err := listenerClient.GetListener(lb, name)
 	if err != nil {
 		// Listener does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Load Balancer Listener %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading Load Balancer Listener %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -204,7 +223,9 @@ func resourceListenerRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("path_prefixes", result.PathPrefixes)
 	d.Set("policies", result.Policies)
 	d.Set("certificates", result.SSLCerts)
-	d.Set("tags", result.Tags)
+	if err := flattenTags(d, meta, result.Tags); err != nil {
+		return err
+	}
 	d.Set("virtual_hosts", result.VirtualHosts)
 
 	return nil
@@ -215,6 +236,10 @@ func resourceListenerUpdate(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	listenerClient := lbaasClient.ListenerClient()
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
@@ -254,15 +279,20 @@ func resourceListenerUpdate(d *schema.ResourceData, meta interface{}) error {
 	input.PathPrefixes = updateOrRemoveStringListAttribute(d, "path_prefixes")
 	input.Policies = updateOrRemoveStringListAttribute(d, "policies")
 	input.SSLCerts = updateOrRemoveStringListAttribute(d, "certificates")
-	input.Tags = updateOrRemoveStringListAttribute(d, "tags")
+	if d.HasChange("tags") || d.HasChange("tags_map") || d.HasChange("tags_all") {
+		tags := expandTags(d, meta)
+		input.Tags = &tags
+	}
 	input.VirtualHosts = updateOrRemoveStringListAttribute(d, "virtual_hosts")
 
-	result, err := listenerClient.UpdateListener(lb, name, &input)
-	if err != nil {
-		return fmt.Errorf("Error updating Listener: %s", err)
+	path := fmt.Sprintf(listenerClient.ResourceRootPath, lb.Region, lb.Name, name)
+	if err := requestClient.do("PUT", path, listenerClient.Accept, listenerClient.ContentType, &input); err != nil {
+		return fmt.Errorf("Error updating Listener: %s", newAPIError(err))
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, result.Name))
+	if err := waitForLBaaSUpdate(meta, "Listener "+d.Id(), d.Timeout(schema.TimeoutUpdate), listenerState(listenerClient, d.Id())); err != nil {
+		return fmt.Errorf("Error updating Listener: %s", err)
+	}
 
 	return resourceListenerRead(d, meta)
 }
@@ -272,12 +302,32 @@ func resourceListenerDelete(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	listenerClient := lbaasClient.ListenerClient()
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := listenerClient.DeleteListener(lb, name); err != nil {
+	path := fmt.Sprintf(listenerClient.ResourceRootPath, lb.Region, lb.Name, name)
+	if err := requestClient.do("DELETE", path, listenerClient.Accept, listenerClient.ContentType, nil); err != nil {
+		return fmt.Errorf("Error deleting Listener: %v", newAPIError(err))
+	}
+
+	if err := waitForLBaaSDelete(meta, "Listener "+d.Id(), d.Timeout(schema.TimeoutDelete), listenerState(listenerClient, d.Id())); err != nil {
 		return fmt.Errorf("Error deleting Listener: %v", err)
 	}
 	return nil
 }
+
+// listenerState reads the state of the listener with the given id.
+func listenerState(listenerClient *lbaas.ListenerClient, id string) func() (lbaas.LBaaSState, error) {
+	return func() (lbaas.LBaaSState, error) {
+		info, err := listenerClient.GetListener(getLoadBalancerContextFromID(id), getLastNameInPath(id))
+		if err != nil {
+			return "", err
+		}
+		return info.State, nil
+	}
+}
@@ -24,7 +24,7 @@ func TestAccLBaaSListener_Basic(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -64,7 +64,7 @@ func TestAccLBaaSListener_BasicUpdate(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
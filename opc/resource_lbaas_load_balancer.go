@@ -3,8 +3,8 @@ package opc
 import (
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/lbaas"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -20,6 +20,14 @@ func resourceLBaaSLoadBalancer() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:         schema.TypeString,
@@ -88,6 +96,9 @@ func resourceLBaaSLoadBalancer() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
+
 			// Read only attributes
 			"balancer_vips": {
 				Type:     schema.TypeList,
@@ -115,6 +126,10 @@ func resourceOPCLoadBalancerCreate(d *schema.ResourceData, meta interface{}) err
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	lbClient := lbaasClient.LoadBalancerClient()
 
 	input := lbaas.CreateLoadBalancerInput{
@@ -155,17 +170,21 @@ func resourceOPCLoadBalancerCreate(d *schema.ResourceData, meta interface{}) err
 		input.Policies = policies
 	}
 
-	tags := getStringSet(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
 
-	info, err := lbClient.CreateLoadBalancer(&input)
-	if err != nil {
+	if err := requestClient.do("POST", lbClient.ContainerPath, lbClient.Accept, lbClient.ContentType, &input); err != nil {
+		return fmt.Errorf("Error creating Load Balancer: %s", newAPIError(err))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", input.Region, input.Name))
+
+	if err := waitForLBaaSCreate(meta, "Load Balancer "+d.Id(), d.Timeout(schema.TimeoutCreate), loadBalancerState(lbClient, d.Id())); err != nil {
 		return fmt.Errorf("Error creating Load Balancer: %s", err)
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", info.Region, info.Name))
 	return resourceOPCLoadBalancerRead(d, meta)
 }
 
@@ -185,11 +204,11 @@ func resourceOPCLoadBalancerRead(d *schema.ResourceData, meta interface{}) error
 	result, err := lbClient.GetLoadBalancer(lb)
 	if err != nil {
 		// LoadBalancer does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Load Balancer %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading Load Balancer %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -221,7 +240,7 @@ func resourceOPCLoadBalancerRead(d *schema.ResourceData, meta interface{}) error
 	if err := setStringList(d, "policies", result.Policies); err != nil {
 		return err
 	}
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -232,6 +251,10 @@ func resourceOPCLoadBalancerUpdate(d *schema.ResourceData, meta interface{}) err
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	lbClient := lbaasClient.LoadBalancerClient()
 	lb := getLoadBalancerContextFromID(d.Id())
 
@@ -251,14 +274,19 @@ func resourceOPCLoadBalancerUpdate(d *schema.ResourceData, meta interface{}) err
 	input.PermittedClients = updateOrRemoveStringListAttribute(d, "permitted_clients")
 	input.PermittedMethods = updateOrRemoveStringListAttribute(d, "permitted_methods")
 	input.Policies = updateOrRemoveStringListAttribute(d, "policies")
-	input.Tags = updateOrRemoveStringListAttribute(d, "tags")
+	if d.HasChange("tags") || d.HasChange("tags_map") || d.HasChange("tags_all") {
+		tags := expandTags(d, meta)
+		input.Tags = &tags
+	}
 
-	result, err := lbClient.UpdateLoadBalancer(lb, &input)
-	if err != nil {
-		return fmt.Errorf("Error updating LoadBalancer: %s", err)
+	path := fmt.Sprintf(lbClient.ResourceRootPath, lb.Region, lb.Name)
+	if err := requestClient.do("PUT", path, lbClient.Accept, lbClient.ContentType, &input); err != nil {
+		return fmt.Errorf("Error updating LoadBalancer: %s", newAPIError(err))
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", lb.Region, result.Name))
+	if err := waitForLBaaSUpdate(meta, "Load Balancer "+d.Id(), d.Timeout(schema.TimeoutUpdate), loadBalancerState(lbClient, d.Id())); err != nil {
+		return fmt.Errorf("Error updating LoadBalancer: %s", err)
+	}
 
 	return resourceOPCLoadBalancerRead(d, meta)
 }
@@ -268,15 +296,35 @@ func resourceOPCLoadBalancerDelete(d *schema.ResourceData, meta interface{}) err
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	lbClient := lbaasClient.LoadBalancerClient()
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := lbClient.DeleteLoadBalancer(lb); err != nil {
+	path := fmt.Sprintf(lbClient.ResourceRootPath, lb.Region, lb.Name)
+	if err := requestClient.do("DELETE", path, lbClient.Accept, lbClient.ContentType, nil); err != nil {
+		return fmt.Errorf("Error deleting LoadBalancer: %v", newAPIError(err))
+	}
+
+	if err := waitForLBaaSDelete(meta, "Load Balancer "+d.Id(), d.Timeout(schema.TimeoutDelete), loadBalancerState(lbClient, d.Id())); err != nil {
 		return fmt.Errorf("Error deleting LoadBalancer: %v", err)
 	}
 	return nil
 }
 
+// loadBalancerState reads the state of the load balancer with the given id.
+func loadBalancerState(lbClient *lbaas.LoadBalancerClient, id string) func() (lbaas.LBaaSState, error) {
+	return func() (lbaas.LBaaSState, error) {
+		info, err := lbClient.GetLoadBalancer(getLoadBalancerContextFromID(id))
+		if err != nil {
+			return "", err
+		}
+		return info.State, nil
+	}
+}
+
 // return the Disbaled State keyword for Load Balancer enabled state
 func getDisabledStateKeyword(enabled bool) lbaas.LBaaSDisabled {
 	if enabled {
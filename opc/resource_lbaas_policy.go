@@ -2,8 +2,8 @@ package opc
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/lbaas"
 	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -34,6 +34,12 @@ func resourceLBaaSPolicy() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		CustomizeDiff: customdiff.Sequence(
 			func(diff *schema.ResourceDiff, v interface{}) error {
 				// ForceNew when changing parent load_balancer
@@ -460,6 +466,10 @@ func resourcePolicyCreate(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	policyClient := lbaasClient.PolicyClient()
 	lb := getLoadBalancerContextFromID(d.Get("load_balancer").(string))
 
@@ -508,12 +518,21 @@ func resourcePolicyCreate(d *schema.ResourceData, meta interface{}) error {
 		input.TrustedCertificatePolicyInfo = expandTrustedCertificatePolicy(d)
 	}
 
-	info, err := policyClient.CreatePolicy(lb, &input)
-	if err != nil {
+	if input.Type == "" {
+		return fmt.Errorf("Error creating Load Balancer Policy: Policy type for %s is not set", input.Name)
+	}
+
+	path := fmt.Sprintf(policyClient.ContainerPath, lb.Region, lb.Name)
+	if err := requestClient.do("POST", path, policyClient.Accept, policyContentType(input.Type), &input); err != nil {
+		return fmt.Errorf("Error creating Load Balancer Policy: %s", newAPIError(err))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, input.Name))
+
+	if err := waitForLBaaSCreate(meta, "Policy "+d.Id(), d.Timeout(schema.TimeoutCreate), policyState(policyClient, d.Id())); err != nil {
 		return fmt.Errorf("Error creating Load Balancer Policy: %s", err)
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, info.Name))
 	return resourcePolicyRead(d, meta)
 }
 
@@ -529,11 +548,11 @@ func resourcePolicyRead(d *schema.ResourceData, meta interface{}) error {
 	result, err := policyClient.GetPolicy(lb, name)
 	if err != nil {
 		// Policy does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Load Balancer Policy %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading Load Balancer Policy %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -586,6 +605,10 @@ func resourcePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	policyClient := lbaasClient.PolicyClient()
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
@@ -635,12 +658,14 @@ func resourcePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
 		input.TrustedCertificatePolicyInfo = expandTrustedCertificatePolicy(d)
 	}
 
-	result, err := policyClient.UpdatePolicy(lb, name, input.Type, &input)
-	if err != nil {
-		return fmt.Errorf("Error updating Policy: %s", err)
+	path := fmt.Sprintf(policyClient.ResourceRootPath, lb.Region, lb.Name, name)
+	if err := requestClient.do("PUT", path, policyClient.Accept, policyContentType(input.Type), &input); err != nil {
+		return fmt.Errorf("Error updating Policy: %s", newAPIError(err))
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, result.Name))
+	if err := waitForLBaaSUpdate(meta, "Policy "+d.Id(), d.Timeout(schema.TimeoutUpdate), policyState(policyClient, d.Id())); err != nil {
+		return fmt.Errorf("Error updating Policy: %s", err)
+	}
 
 	return resourcePolicyRead(d, meta)
 }
@@ -651,16 +676,42 @@ func resourcePolicyDelete(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	policyClient := lbaasClient.PolicyClient()
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := policyClient.DeletePolicy(lb, name); err != nil {
+	path := fmt.Sprintf(policyClient.ResourceRootPath, lb.Region, lb.Name, name)
+	if err := requestClient.do("DELETE", path, policyClient.Accept, "", nil); err != nil {
+		return fmt.Errorf("Error deleting Policy: %v", newAPIError(err))
+	}
+
+	if err := waitForLBaaSDelete(meta, "Policy "+d.Id(), d.Timeout(schema.TimeoutDelete), policyState(policyClient, d.Id())); err != nil {
 		return fmt.Errorf("Error deleting Policy: %v", err)
 	}
 	return nil
 }
 
+// policyContentType returns the content type of the requests for a type of
+// policy, e.g. RedirectPolicy. Each type has its own.
+func policyContentType(policyType string) string {
+	return fmt.Sprintf("application/vnd.com.oracle.oracloud.lbaas.%s+json", policyType)
+}
+
+// policyState reads the state of the policy with the given id.
+func policyState(policyClient *lbaas.PolicyClient, id string) func() (lbaas.LBaaSState, error) {
+	return func() (lbaas.LBaaSState, error) {
+		info, err := policyClient.GetPolicy(getLoadBalancerContextFromID(id), getLastNameInPath(id))
+		if err != nil {
+			return "", err
+		}
+		return info.State, nil
+	}
+}
+
 // ApplicationCookieStickinessPolicy
 
 func expandApplicationCookieStickinessPolicy(d *schema.ResourceData) lbaas.ApplicationCookieStickinessPolicyInfo {
@@ -24,7 +24,7 @@ func TestAccLBaaSPolicy_ApplicationCookieStickinessPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -60,7 +60,7 @@ func TestAccLBaaSPolicy_CloudGatePolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -99,7 +99,7 @@ func TestAccLBaaSPolicy_LoadBalancerCookieStickinessPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -135,7 +135,7 @@ func TestAccLBaaSPolicy_LoadBalancingMechanismPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -171,7 +171,7 @@ func TestAccLBaaSPolicy_RateLimitingRequestPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -214,7 +214,7 @@ func TestAccLBaaSPolicy_RedirectPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -251,7 +251,7 @@ func TestAccLBaaSPolicy_ResourceAccessControlPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -289,7 +289,7 @@ func TestAccLBaaSPolicy_SetRequestHeaderPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -329,7 +329,7 @@ func TestAccLBaaSPolicy_SSLNegotiationPolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -368,7 +368,7 @@ func TestAccLBaaSPolicy_TrustedCertificatePolicy(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -404,7 +404,7 @@ func TestAccLBaaSPolicy_ApplicationCookieStickinessPolicy_Update(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -451,7 +451,7 @@ func TestAccLBaaSPolicy_Update_ChangePolicyType(t *testing.T) {
 	lbID := os.Getenv("OPC_TEST_USE_EXISTING_LB")
 	if lbID == "" {
 		lbCount = 1
-		lbID = "${opc_lbaas_load_balancer.test.id}"
+		lbID = "${opc_lbaas_load_balancer.test[0].id}"
 	}
 
 	resource.Test(t, resource.TestCase{
@@ -5,8 +5,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/lbaas"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -22,6 +22,14 @@ func resourceLBaaSOriginServerPool() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"load_balancer": {
 				Type:         schema.TypeString,
@@ -118,6 +126,9 @@ func resourceLBaaSOriginServerPool() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
+
 			// Read only attributes
 			"consumers": {
 				Type:     schema.TypeString,
@@ -148,6 +159,10 @@ func resourceOriginServerPoolCreate(d *schema.ResourceData, meta interface{}) er
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	serverPoolClient := lbaasClient.OriginServerPoolClient()
 	lb := getLoadBalancerContextFromID(d.Get("load_balancer").(string))
 
@@ -174,7 +189,7 @@ func resourceOriginServerPoolCreate(d *schema.ResourceData, meta interface{}) er
 		input.OriginServers = servers
 	}
 
-	tags := getStringSet(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -184,12 +199,17 @@ func resourceOriginServerPoolCreate(d *schema.ResourceData, meta interface{}) er
 		input.HealthCheck = &healthCheck
 	}
 
-	info, err := serverPoolClient.CreateOriginServerPool(lb, &input)
-	if err != nil {
+	path := fmt.Sprintf(serverPoolClient.ContainerPath, lb.Region, lb.Name)
+	if err := requestClient.do("POST", path, serverPoolClient.Accept, serverPoolClient.ContentType, &input); err != nil {
+		return fmt.Errorf("Error creating Load Balancer Server Pool: %s", newAPIError(err))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, input.Name))
+
+	if err := waitForLBaaSCreate(meta, "Server Pool "+d.Id(), d.Timeout(schema.TimeoutCreate), originServerPoolState(serverPoolClient, d.Id())); err != nil {
 		return fmt.Errorf("Error creating Load Balancer Server Pool: %s", err)
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, info.Name))
 	return resourceOriginServerPoolRead(d, meta)
 }
 
@@ -205,11 +225,11 @@ func resourceOriginServerPoolRead(d *schema.ResourceData, meta interface{}) erro
 	result, err := serverPoolClient.GetOriginServerPool(lb, name)
 	if err != nil {
 		// OriginServerPool does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Server Pool %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading Server Pool %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -231,7 +251,9 @@ func resourceOriginServerPoolRead(d *schema.ResourceData, meta interface{}) erro
 		return err
 	}
 
-	d.Set("tags", result.Tags)
+	if err := flattenTags(d, meta, result.Tags); err != nil {
+		return err
+	}
 
 	if result.HealthCheck.Enabled != "" {
 		if err := flattenHealthCheckConfig(d, result.HealthCheck); err != nil {
@@ -247,6 +269,10 @@ func resourceOriginServerPoolUpdate(d *schema.ResourceData, meta interface{}) er
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	serverPoolClient := lbaasClient.OriginServerPoolClient()
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
@@ -295,14 +321,19 @@ func resourceOriginServerPoolUpdate(d *schema.ResourceData, meta interface{}) er
 		}
 	}
 
-	input.Tags = updateOrRemoveStringListAttribute(d, "tags")
+	if d.HasChange("tags") || d.HasChange("tags_map") || d.HasChange("tags_all") {
+		tags := expandTags(d, meta)
+		input.Tags = &tags
+	}
 
-	result, err := serverPoolClient.UpdateOriginServerPool(lb, name, &input)
-	if err != nil {
-		return fmt.Errorf("Error updating OriginServerPool: %s", err)
+	path := fmt.Sprintf(serverPoolClient.ResourceRootPath, lb.Region, lb.Name, name)
+	if err := requestClient.do("PATCH", path, serverPoolClient.Accept, serverPoolClient.ContentType, &input); err != nil {
+		return fmt.Errorf("Error updating OriginServerPool: %s", newAPIError(err))
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s/%s", lb.Region, lb.Name, result.Name))
+	if err := waitForLBaaSUpdate(meta, "Server Pool "+d.Id(), d.Timeout(schema.TimeoutUpdate), originServerPoolState(serverPoolClient, d.Id())); err != nil {
+		return fmt.Errorf("Error updating OriginServerPool: %s", err)
+	}
 
 	return resourceOriginServerPoolRead(d, meta)
 }
@@ -312,16 +343,36 @@ func resourceOriginServerPoolDelete(d *schema.ResourceData, meta interface{}) er
 	if err != nil {
 		return err
 	}
+	requestClient, err := meta.(*Client).getLBaaSRequestClient()
+	if err != nil {
+		return err
+	}
 	serverPoolClient := lbaasClient.OriginServerPoolClient()
 	name := getLastNameInPath(d.Id())
 	lb := getLoadBalancerContextFromID(d.Id())
 
-	if _, err := serverPoolClient.DeleteOriginServerPool(lb, name); err != nil {
+	path := fmt.Sprintf(serverPoolClient.ResourceRootPath, lb.Region, lb.Name, name)
+	if err := requestClient.do("DELETE", path, serverPoolClient.Accept, serverPoolClient.ContentType, nil); err != nil {
+		return fmt.Errorf("Error deleting Server Pool: %v", newAPIError(err))
+	}
+
+	if err := waitForLBaaSDelete(meta, "Server Pool "+d.Id(), d.Timeout(schema.TimeoutDelete), originServerPoolState(serverPoolClient, d.Id())); err != nil {
 		return fmt.Errorf("Error deleting Server Pool: %v", err)
 	}
 	return nil
 }
 
+// originServerPoolState reads the state of the server pool with the given id.
+func originServerPoolState(serverPoolClient *lbaas.OriginServerPoolClient, id string) func() (lbaas.LBaaSState, error) {
+	return func() (lbaas.LBaaSState, error) {
+		info, err := serverPoolClient.GetOriginServerPool(getLoadBalancerContextFromID(id), getLastNameInPath(id))
+		if err != nil {
+			return "", err
+		}
+		return info.State, nil
+	}
+}
+
 // convert the list of "server:port" strings to a list of CreateOriginServerInput
 func expandOriginServerConfig(servers []string) ([]lbaas.CreateOriginServerInput, error) {
 	config := []lbaas.CreateOriginServerInput{}
@@ -2,12 +2,28 @@ package opc
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/go-oracle-terraform/storage"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/structure"
 	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	// machineImageContainer is the storage container machine image files are
+	// registered from.
+	machineImageContainer = "compute_images"
+
+	machineImageStateAvailable = "available"
+	machineImageStateError     = "error"
+	machineImageStatePending   = "pending"
 )
 
 func resourceOPCMachineImage() *schema.Resource {
@@ -19,6 +35,10 @@ func resourceOPCMachineImage() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"account": {
 				Type:         schema.TypeString,
@@ -68,6 +88,12 @@ func resourceOPCMachineImage() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"source_file": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
 			"no_upload": {
 				Type:     schema.TypeBool,
 				Computed: true,
@@ -102,6 +128,12 @@ func resourceOPCMachineImageCreate(d *schema.ResourceData, meta interface{}) err
 	name := d.Get("name").(string)
 	file := d.Get("file").(string)
 
+	if source, ok := d.GetOk("source_file"); ok {
+		if err := uploadMachineImageFile(meta.(*Client), source.(string), file); err != nil {
+			return fmt.Errorf("Error uploading Machine Image file '%s': %v", source, newAPIError(err))
+		}
+	}
+
 	input := &compute.CreateMachineImageInput{
 		Name: name,
 		File: file,
@@ -127,14 +159,122 @@ func resourceOPCMachineImageCreate(d *schema.ResourceData, meta interface{}) err
 
 	info, err := resClient.CreateMachineImage(input)
 	if err != nil {
-		return fmt.Errorf("Error creating Machine Image '%s': %v", name, err)
+		return fmt.Errorf("Error creating Machine Image '%s': %v", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
 
+	conf := waitConf{
+		Description: fmt.Sprintf("Machine Image '%s' to become available", name),
+		Pending:     []string{machineImageStatePending},
+		Target:      []string{machineImageStateAvailable},
+		Refresh:     machineImageState(resClient, name),
+		Timeout:     d.Timeout(schema.TimeoutCreate),
+	}
+	if _, err := waitForState(meta.(*Client).stopContext, conf); err != nil {
+		return fmt.Errorf("Error waiting for Machine Image '%s' to become available: %v", name, newAPIError(err))
+	}
+
 	return resourceOPCMachineImageRead(d, meta)
 }
 
+// machineImageState reports the state of a machine image, treating any state
+// other than available or error as pending. A machine image in the error
+// state fails the wait with its error reason.
+func machineImageState(resClient *compute.MachineImagesClient, name string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		input := &compute.GetMachineImageInput{
+			Name: name,
+		}
+		info, err := resClient.GetMachineImage(input)
+		if err != nil {
+			return nil, "", err
+		}
+		if info == nil {
+			return nil, "", fmt.Errorf("Machine Image '%s' no longer exists", name)
+		}
+
+		switch strings.ToLower(info.State) {
+		case machineImageStateAvailable:
+			return info, machineImageStateAvailable, nil
+		case machineImageStateError:
+			return info, machineImageStateError, fmt.Errorf("Machine Image '%s' is in an error state: %s", name, info.ErrorReason)
+		default:
+			return info, machineImageStatePending, nil
+		}
+	}
+}
+
+// uploadMachineImageFile uploads a local machine image file to the
+// compute_images container, in segments when it is too large for a single
+// PUT, and verifies the etag of the uploaded object against the file.
+func uploadMachineImageFile(meta *Client, source, name string) error {
+	etag, err := getStorageObjectFileETag(source, 0)
+	if err != nil {
+		return err
+	}
+	path, err := homedir.Expand(source)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	storageClient, err := meta.getStorageClient()
+	if err != nil {
+		return err
+	}
+	resClient := storageClient.Objects()
+
+	log.Printf("[DEBUG] Uploading Machine Image file %s to %s/%s", source, machineImageContainer, name)
+	if segmentSize := getStorageObjectSegmentSize(0, info.Size()); segmentSize != 0 {
+		objectClient, err := meta.getStorageObjectClient()
+		if err != nil {
+			return err
+		}
+		err = objectClient.uploadLargeObject(meta.stopContext, &largeObjectUploadInput{
+			Container:        machineImageContainer,
+			Name:             name,
+			SegmentContainer: machineImageContainer + "_segments",
+			SegmentSize:      segmentSize,
+			File:             file,
+			Size:             info.Size(),
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		input := &storage.CreateObjectInput{
+			Name:      name,
+			Container: machineImageContainer,
+			Body:      file,
+			ETag:      etag,
+		}
+		if _, err := resClient.CreateObject(input); err != nil {
+			return err
+		}
+	}
+
+	object, err := resClient.GetObject(&storage.GetObjectInput{
+		Name:      name,
+		Container: machineImageContainer,
+	})
+	if err != nil {
+		return err
+	}
+	if strings.Trim(object.Etag, "\"") != etag {
+		return fmt.Errorf("Etag %s of the uploaded object %s/%s does not match the etag %s of the file", object.Etag, machineImageContainer, name, etag)
+	}
+	return nil
+}
+
 func resourceOPCMachineImageRead(d *schema.ResourceData, meta interface{}) error {
 	computeClient, err := meta.(*Client).getComputeClient()
 	if err != nil {
@@ -149,11 +289,11 @@ func resourceOPCMachineImageRead(d *schema.ResourceData, meta interface{}) error
 
 	result, err := resClient.GetMachineImage(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Machine Image '%s': %v", name, err)
+		return fmt.Errorf("Error reading Machine Image '%s': %v", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -200,7 +340,27 @@ func resourceOPCMachineImageDelete(d *schema.ResourceData, meta interface{}) err
 	}
 
 	if err := resClient.DeleteMachineImage(input); err != nil {
-		return fmt.Errorf("Error deleting Machine Image '%s': %v", name, err)
+		return fmt.Errorf("Error deleting Machine Image '%s': %v", name, newAPIError(err))
+	}
+
+	// The file is deleted along with the machine image when it was uploaded
+	// by this resource
+	if _, ok := d.GetOk("source_file"); ok {
+		objectClient, err := meta.(*Client).getStorageObjectClient()
+		if err != nil {
+			return err
+		}
+		file := d.Get("file").(string)
+		segments, err := objectClient.getLargeObjectSegments(machineImageContainer, file)
+		if err != nil {
+			return fmt.Errorf("Error reading segments of Machine Image file '%s': %v", file, newAPIError(err))
+		}
+		if err := objectClient.deleteObject(machineImageContainer, file); err != nil {
+			return fmt.Errorf("Error deleting Machine Image file '%s': %v", file, newAPIError(err))
+		}
+		if err := objectClient.deleteSegments(segments); err != nil {
+			return fmt.Errorf("Error deleting segments of Machine Image file '%s': %v", file, newAPIError(err))
+		}
 	}
 	return nil
 }
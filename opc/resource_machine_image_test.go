@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/go-oracle-terraform/storage"
 	"github.com/hashicorp/terraform/helper/acctest"
 	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/terraform"
@@ -28,6 +29,46 @@ func TestAccOPCMachineImage_Basic(t *testing.T) {
 	})
 }
 
+func TestAccOPCMachineImage_sourceFile(t *testing.T) {
+	resName := "opc_compute_machine_image.test"
+	ri := acctest.RandInt()
+	file := fmt.Sprintf("acc-test-machine-image-%d.tar.gz", ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckMachineImageDestroy,
+			testAccCheckMachineImageFileDestroyed(file),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMachineImage_sourceFile(ri),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMachineImageExists,
+					resource.TestCheckResourceAttr(resName, "file", file),
+					resource.TestCheckResourceAttr(resName, "state", "available"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckMachineImageFileDestroyed(file string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client).storageClient.Objects()
+
+		input := &storage.GetObjectInput{
+			Name:      file,
+			Container: machineImageContainer,
+		}
+		if info, err := client.GetObject(input); err == nil {
+			return fmt.Errorf("Machine Image file %s still exists: %#v", file, info)
+		}
+		return nil
+	}
+}
+
 func testAccCheckMachineImageExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.MachineImages()
 
@@ -86,3 +127,13 @@ func testAccMachineImage_basic(rInt int) string {
 
 	return fmt.Sprintf(testAccMachineImageBasic, identity_domain, rInt)
 }
+
+func testAccMachineImage_sourceFile(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_machine_image" "test" {
+  account     = "/Compute-%s/cloud_storage"
+  name        = "acc-test-machine-image-%d"
+  file        = "acc-test-machine-image-%d.tar.gz"
+  source_file = "test-fixtures/dummy.tar.gz"
+}`, os.Getenv("OPC_IDENTITY_DOMAIN"), rInt, rInt)
+}
@@ -1,11 +1,12 @@
 package opc
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"reflect"
 	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -21,6 +22,8 @@ func resourceOPCOrchestratedInstance() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceOPCOrchestratedInstanceCustomizeDiff,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(20 * time.Minute),
 			Update: schema.DefaultTimeout(20 * time.Minute),
@@ -46,7 +49,9 @@ func resourceOPCOrchestratedInstance() *schema.Resource {
 					"suspend",
 				}, true),
 			},
-			"tags": tagsOptionalSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 
 			"instance": orchestrationInstanceSchema(),
 
@@ -54,31 +59,40 @@ func resourceOPCOrchestratedInstance() *schema.Resource {
 				Type:     schema.TypeInt,
 				Computed: true,
 			},
+
+			"object_changes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
 
+// The changes an update makes to the objects of an orchestration, as planned
+// in object_changes.
+const (
+	orchestrationObjectCreate  = "create"
+	orchestrationObjectUpdate  = "update"
+	orchestrationObjectReplace = "replace"
+	orchestrationObjectDelete  = "delete"
+)
+
 func resourceOPCOrchestratedInstanceCreate(d *schema.ResourceData, meta interface{}) error {
 	log.Printf("[DEBUG] Resource state: %#v", d.State())
 
 	log.Print("[DEBUG] Creating Orchestration")
 
-	computeClient, err := meta.(*Client).getComputeClient()
-	if err != nil {
-		return err
-	}
-	resClient := computeClient.Orchestrations()
 	input := compute.CreateOrchestrationInput{
 		Name:         d.Get("name").(string),
 		DesiredState: compute.OrchestrationDesiredState(d.Get("desired_state").(string)),
-		Timeout:      d.Timeout(schema.TimeoutCreate),
 	}
 
 	if v, ok := d.GetOk("description"); ok {
 		input.Description = v.(string)
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -89,12 +103,10 @@ func resourceOPCOrchestratedInstanceCreate(d *schema.ResourceData, meta interfac
 	}
 	input.Objects = instances
 
-	info, err := resClient.CreateOrchestration(&input)
-	if err != nil {
+	if err := createOrchestration(d, meta, &input); err != nil {
 		return fmt.Errorf("Error creating Orchestration: %s", err)
 	}
 
-	d.SetId(info.Name)
 	return resourceOPCOrchestratedInstanceRead(d, meta)
 }
 
@@ -114,11 +126,11 @@ func resourceOPCOrchestratedInstanceRead(d *schema.ResourceData, meta interface{
 	result, err := resClient.GetOrchestration(&getInput)
 	if err != nil {
 		// Orchestration does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -131,12 +143,16 @@ func resourceOPCOrchestratedInstanceRead(d *schema.ResourceData, meta interface{
 	d.Set("version", result.Version)
 	d.Set("description", result.Description)
 	d.Set("desired_state", result.DesiredState)
+	// The changes are only planned, once they're applied there are none left
+	d.Set("object_changes", map[string]interface{}{})
 
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 
-	if result.DesiredState == "active" {
+	// The instances of an orchestration which is still starting, or failed
+	// to start, e.g. one tainted by a failed create, may not exist
+	if result.DesiredState == "active" && result.Status == compute.OrchestrationStatusActive {
 		instances, err := flattenOrchestratedInstances(d, meta, result.Objects)
 		if err != nil {
 			return err
@@ -167,23 +183,14 @@ func resourceOPCOrchestratedInstanceUpdate(d *schema.ResourceData, meta interfac
 
 	result, err := resClient.GetOrchestration(&getInput)
 	if err != nil {
-		// Orchestration does not exist
-		if client.WasNotFoundError(err) {
-			d.SetId("")
-			return nil
-		}
-		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), err)
-	}
-
-	if result == nil {
-		d.SetId("")
-		return nil
+		// An orchestration which no longer exists is only removed from the
+		// state by a refresh, so the update fails
+		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), newAPIError(err))
 	}
 
 	input := compute.UpdateOrchestrationInput{
 		Name:         d.Get("name").(string),
 		DesiredState: compute.OrchestrationDesiredState(d.Get("desired_state").(string)),
-		Timeout:      d.Timeout(schema.TimeoutUpdate),
 		Version:      d.Get("version").(int),
 	}
 
@@ -191,68 +198,274 @@ func resourceOPCOrchestratedInstanceUpdate(d *schema.ResourceData, meta interfac
 		input.Description = v.(string)
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
 
 	input.Objects = result.Objects
+	if d.HasChange("instance") {
+		objects, err := updateOrchestrationInstances(d, meta, result.Objects)
+		if err != nil {
+			return err
+		}
+		input.Objects = objects
+	}
 
-	info, err := resClient.UpdateOrchestration(&input)
-	if err != nil {
-		return fmt.Errorf("Error updating Orchestration: %s", err)
+	if err := updateOrchestration(d, meta, &input); err != nil {
+		return err
 	}
 
-	d.SetId(info.Name)
 	return resourceOPCOrchestratedInstanceRead(d, meta)
 }
 
 func resourceOPCOrchestratedInstanceDelete(d *schema.ResourceData, meta interface{}) error {
-	computeClient, err := meta.(*Client).getComputeClient()
-	if err != nil {
-		return err
+	name := d.Id()
+	log.Printf("[DEBUG] Deleting orchestration %s", name)
+
+	if err := deleteOrchestration(meta, name, d.Timeout(schema.TimeoutDelete)); err != nil {
+		return fmt.Errorf("Error deleting orchestration %s for instance %s: %s", name, d.Id(), err)
 	}
-	resClient := computeClient.Orchestrations()
 
-	name := d.Id()
+	return nil
+}
 
-	input := &compute.DeleteOrchestrationInput{
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
+// Conflicts between the user_data, metadata and instance_attributes of an
+// instance fail the plan.
+func resourceOPCOrchestratedInstanceCustomizeDiff(diff *schema.ResourceDiff, v interface{}) error {
+	if err := customizeDiffTagsAll(diff, v); err != nil {
+		return err
 	}
-	log.Printf("[DEBUG] Deleting orchestration %s", name)
+	if err := customizeDiffOrchestrationInstances(diff); err != nil {
+		return err
+	}
+	return customizeDiffOrchestrationObjectChanges(diff)
+}
 
-	if err := resClient.DeleteOrchestration(input); err != nil {
-		return fmt.Errorf("Error deleting orchestration %s for instance %s: %s", name, d.Id(), err)
+// customizeDiffOrchestrationObjectChanges plans the change to each object of
+// the orchestration in object_changes, as the diff of the instance list is by
+// position rather than by name. Read clears object_changes, so they only show
+// up in the plan of an update.
+func customizeDiffOrchestrationObjectChanges(diff *schema.ResourceDiff) error {
+	if diff.Id() == "" || !diff.HasChange("instance") {
+		return nil
+	}
+	o, n := diff.GetChange("instance")
+	changes := orchestrationInstanceChanges(o.([]interface{}), n.([]interface{}))
+	if len(changes) == 0 {
+		return nil
 	}
+	return diff.SetNew("object_changes", changes)
+}
 
+// customizeDiffOrchestrationInstances checks the user_data, metadata and
+// instance_attributes of every instance block whose values are known.
+func customizeDiffOrchestrationInstances(diff *schema.ResourceDiff) error {
+	for i := 0; i < diff.Get("instance.#").(int); i++ {
+		prefix := fmt.Sprintf("instance.%d", i)
+		if !diff.NewValueKnown(prefix+".instance_attributes") || !diff.NewValueKnown(prefix+".user_data") || !diff.NewValueKnown(prefix+".metadata") {
+			continue
+		}
+		if _, err := expandInstanceAttributes(
+			diff.Get(prefix+".instance_attributes").(string),
+			diff.Get(prefix+".user_data").(string),
+			diff.Get(prefix+".gzip_user_data").(bool),
+			diff.Get(prefix+".metadata").(map[string]interface{})); err != nil {
+			return fmt.Errorf("instance %s: %s", diff.Get(prefix+".name"), err)
+		}
+	}
 	return nil
 }
 
 func expandOrchestrationInstances(d *schema.ResourceData) ([]compute.Object, error) {
-	instances_info := d.Get("instance").([]interface{})
-	instances := make([]compute.Object, 0, len(instances_info))
-	for i := range instances_info {
-		// The value for orchestration is the name of the orchestration
-		orchestrationName := d.Get("name").(string)
-		objectLabel := d.Get(fmt.Sprintf("instance.%d.name", i)).(string)
-		persistent := d.Get(fmt.Sprintf("instance.%d.persistent", i)).(bool)
-
-		instanceCreateInput, instanceErr := expandCreateInstanceInput(fmt.Sprintf("instance.%d", i), d)
-		if instanceErr != nil {
-			return nil, instanceErr
+	instances := make([]compute.Object, 0, d.Get("instance.#").(int))
+	for i := 0; i < d.Get("instance.#").(int); i++ {
+		instance, err := expandOrchestrationInstance(d, fmt.Sprintf("instance.%d", i))
+		if err != nil {
+			return nil, err
 		}
+		instances = append(instances, instance)
+	}
+
+	return instances, nil
+}
+
+func expandOrchestrationInstance(d *schema.ResourceData, prefix string) (compute.Object, error) {
+	instanceCreateInput, err := expandCreateInstanceInput(prefix, d)
+	if err != nil {
+		return compute.Object{}, err
+	}
+
+	// The value for orchestration is the name of the orchestration
+	return compute.Object{
+		Label:         d.Get(fmt.Sprintf("%s.name", prefix)).(string),
+		Orchestration: d.Get("name").(string),
+		Type:          compute.OrchestrationTypeInstance,
+		Template:      instanceCreateInput,
+		Persistent:    d.Get(fmt.Sprintf("%s.persistent", prefix)).(bool),
+	}, nil
+}
+
+// updateOrchestrationInstances returns the objects of the orchestration with
+// the changes to the instance blocks applied. The objects of unchanged
+// instances are returned as read, so that the service leaves them as they
+// are, and changing whether an instance is persistent doesn't replace it.
+func updateOrchestrationInstances(d *schema.ResourceData, meta interface{}, objects []compute.Object) ([]compute.Object, error) {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return nil, err
+	}
+
+	o, n := d.GetChange("instance")
+	changes := orchestrationInstanceChanges(o.([]interface{}), n.([]interface{}))
+	prefixes := make(map[string]string)
+	for i := 0; i < d.Get("instance.#").(int); i++ {
+		prefix := fmt.Sprintf("instance.%d", i)
+		prefixes[d.Get(fmt.Sprintf("%s.name", prefix)).(string)] = prefix
+	}
 
-		instance := compute.Object{
-			Label:         objectLabel,
-			Orchestration: orchestrationName,
-			Type:          compute.OrchestrationTypeInstance,
-			Template:      instanceCreateInput,
-			Persistent:    persistent,
+	result := make([]compute.Object, 0, len(prefixes))
+	for _, object := range objects {
+		if object.Type == compute.OrchestrationTypeInstance {
+			switch changes[object.Label] {
+			case orchestrationObjectCreate, orchestrationObjectReplace, orchestrationObjectDelete:
+				// Replaced objects are added from their blocks below
+				continue
+			case orchestrationObjectUpdate:
+				object.Persistent = d.Get(fmt.Sprintf("%s.persistent", prefixes[object.Label])).(bool)
+			}
 		}
+		result = append(result, object)
+	}
 
-		instances = append(instances, instance)
+	for i := 0; i < d.Get("instance.#").(int); i++ {
+		prefix := fmt.Sprintf("instance.%d", i)
+		name := d.Get(fmt.Sprintf("%s.name", prefix)).(string)
+		if changes[name] != orchestrationObjectCreate && changes[name] != orchestrationObjectReplace {
+			continue
+		}
+		log.Printf("[DEBUG] Orchestration %s: %s instance %s", d.Id(), changes[name], name)
+
+		object, err := expandOrchestrationInstance(d, prefix)
+		if err != nil {
+			return nil, err
+		}
+		template, err := expandOrchestrationInstanceTemplate(collectionClient, object.Template.(*compute.CreateInstanceInput))
+		if err != nil {
+			return nil, err
+		}
+		object.Template = template
+		result = append(result, object)
 	}
 
-	return instances, nil
+	return result, nil
+}
+
+// expandOrchestrationInstanceTemplate returns an instance template in the
+// form the service returns it, with the names of the objects it refers to
+// qualified.
+func expandOrchestrationInstanceTemplate(client *computeCollectionClient, input *compute.CreateInstanceInput) (map[string]interface{}, error) {
+	input = qualifyInstanceInput(client, input)
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var template map[string]interface{}
+	if err := json.Unmarshal(b, &template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// orchestrationInstanceChanges returns the change to the object of each
+// instance whose block differs between o and n, by instance name.
+func orchestrationInstanceChanges(o, n []interface{}) map[string]string {
+	return orchestrationObjectChanges(orchestrationInstanceSchema().Elem.(*schema.Resource).Schema, o, n)
+}
+
+// orchestrationObjectChanges returns the change to the object of each block
+// which differs between o and n, by object name. Changing whether an object
+// is persistent, or the objects it depends on, updates its object, while any
+// other change replaces the object.
+func orchestrationObjectChanges(attributes map[string]*schema.Schema, o, n []interface{}) map[string]string {
+	old := orchestrationObjectsByName(o)
+	changes := make(map[string]string)
+	for name, block := range orchestrationObjectsByName(n) {
+		previous, ok := old[name]
+		delete(old, name)
+		switch {
+		case !ok:
+			changes[name] = orchestrationObjectCreate
+		case !equalOrchestrationTemplates(attributes, previous, block):
+			changes[name] = orchestrationObjectReplace
+		case previous["persistent"] != block["persistent"] || !equalOrchestrationValues(previous["depends"], block["depends"]):
+			changes[name] = orchestrationObjectUpdate
+		}
+	}
+	for name := range old {
+		changes[name] = orchestrationObjectDelete
+	}
+	return changes
+}
+
+func orchestrationObjectsByName(blocks []interface{}) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	for _, v := range blocks {
+		if block, ok := v.(map[string]interface{}); ok {
+			result[block["name"].(string)] = block
+		}
+	}
+	return result
+}
+
+// equalOrchestrationTemplates compares the arguments of two object blocks
+// which make up the template of their object, which are all of them but
+// persistent and depends.
+func equalOrchestrationTemplates(attributes map[string]*schema.Schema, a, b map[string]interface{}) bool {
+	for k, attribute := range attributes {
+		if k == "persistent" || k == "depends" || !(attribute.Required || attribute.Optional) {
+			continue
+		}
+		if !equalOrchestrationValues(a[k], b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalOrchestrationValues compares two values of a block, treating unset
+// and empty lists and maps as equal.
+func equalOrchestrationValues(a, b interface{}) bool {
+	switch a := a.(type) {
+	case []interface{}:
+		b, _ := b.([]interface{})
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalOrchestrationValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, _ := b.(map[string]interface{})
+		if len(a) != len(b) {
+			return false
+		}
+		for k := range a {
+			if !equalOrchestrationValues(a[k], b[k]) {
+				return false
+			}
+		}
+		return true
+	case *schema.Set:
+		if b, ok := b.(*schema.Set); ok {
+			return a.Equal(b)
+		}
+		return a.Len() == 0 && b == nil
+	case nil:
+		return b == nil || equalOrchestrationValues(b, a)
+	}
+	return reflect.DeepEqual(a, b)
 }
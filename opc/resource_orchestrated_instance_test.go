@@ -2,6 +2,7 @@ package opc
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"testing"
 
@@ -364,6 +365,120 @@ func TestAccOPCOrchestratedInstance_UserData(t *testing.T) {
 	})
 }
 
+func TestAccOPCOrchestratedInstance_typedUserData(t *testing.T) {
+	resName := "opc_compute_orchestrated_instance.test"
+	ri := acctest.RandInt()
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckOrchestrationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOrchestrationTypedUserData(ri, "test"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationExists,
+					resource.TestCheckResourceAttr(resName, "instance.0.user_data", "#!/bin/sh\necho test\n"),
+					resource.TestCheckResourceAttr(resName, "instance.0.metadata.environment", "test"),
+					resource.TestCheckResourceAttr(resName, "instance.0.instance_attributes", "{}"),
+				),
+			},
+			{
+				Config: testAccOrchestrationTypedUserData(ri, "production"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationExists,
+					resource.TestCheckResourceAttr(resName, "instance.0.user_data", "#!/bin/sh\necho production\n"),
+					resource.TestCheckResourceAttr(resName, "instance.0.metadata.environment", "production"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCOrchestratedInstance_updateInstance(t *testing.T) {
+	resName := "opc_compute_orchestrated_instance.test"
+	ri := acctest.RandInt()
+	var first, second string
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckOrchestrationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOrchestrationUpdateInstance(ri, false, "oc3"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestratedInstanceID(resName, "instance.0.id", &first, false),
+					testAccCheckOrchestratedInstanceID(resName, "instance.1.id", &second, false),
+					resource.TestCheckResourceAttr(resName, "version", "1"),
+				),
+			},
+			{
+				// Only the second instance is replaced
+				Config: testAccOrchestrationUpdateInstance(ri, true, "oc4"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestratedInstanceID(resName, "instance.0.id", &first, false),
+					testAccCheckOrchestratedInstanceID(resName, "instance.1.id", &second, true),
+					resource.TestCheckResourceAttr(resName, "instance.0.persistent", "true"),
+					resource.TestCheckResourceAttr(resName, "instance.1.shape", "oc4"),
+					// The planned changes are cleared once they're applied
+					resource.TestCheckResourceAttr(resName, "object_changes.%", "0"),
+					resource.TestCheckResourceAttr(resName, "version", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestOrchestrationInstanceChanges(t *testing.T) {
+	instance := func(name string, persistent bool, shape string) interface{} {
+		return map[string]interface{}{
+			"name":       name,
+			"persistent": persistent,
+			"shape":      shape,
+			"ssh_keys":   []interface{}{},
+			"metadata":   map[string]interface{}{},
+		}
+	}
+	o := []interface{}{
+		instance("web", false, "oc3"),
+		instance("db", false, "oc3"),
+		instance("app", false, "oc3"),
+		instance("cache", false, "oc3"),
+	}
+	n := []interface{}{
+		instance("new", false, "oc3"),
+		instance("app", true, "oc3"),
+		instance("db", false, "oc4"),
+		map[string]interface{}{"name": "cache", "persistent": false, "shape": "oc3"},
+	}
+
+	expected := map[string]string{
+		"new": orchestrationObjectCreate,
+		"app": orchestrationObjectUpdate,
+		"db":  orchestrationObjectReplace,
+		"web": orchestrationObjectDelete,
+	}
+	if changes := orchestrationInstanceChanges(o, n); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected changes %#v, got %#v", expected, changes)
+	}
+}
+
+// testAccCheckOrchestratedInstanceID stores the id of an instance, after
+// checking whether it has changed since it was last stored.
+func testAccCheckOrchestratedInstanceID(name, key string, id *string, changed bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", name)
+		}
+		value := rs.Primary.Attributes[key]
+		if *id != "" && (value != *id) != changed {
+			return fmt.Errorf("Expected %s of %s to change from %s: %t, got %s", key, name, *id, changed, value)
+		}
+		*id = value
+		return nil
+	}
+}
+
 func testAccCheckOrchestrationExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.Orchestrations()
 
@@ -677,3 +792,44 @@ JSON
 }
   `, rInt, rInt)
 }
+
+func testAccOrchestrationTypedUserData(rInt int, environment string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_orchestrated_instance" "test" {
+  name          = "test_orchestration-%d"
+  desired_state = "active"
+  instance {
+    name       = "acc-test-instance-%d"
+    label      = "TestAccOPCInstance_typedUserData"
+    shape      = "oc3"
+    image_list = "/oracle/public/OL_7.2_UEKR4_x86_64"
+    user_data  = "#!/bin/sh\necho %s\n"
+    metadata = {
+      environment = "%s"
+    }
+  }
+}
+`, rInt, rInt, environment, environment)
+}
+
+func testAccOrchestrationUpdateInstance(rInt int, persistent bool, shape string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_orchestrated_instance" "test" {
+  name          = "test_orchestration-%[1]d"
+  desired_state = "active"
+
+  instance {
+    name       = "acc-test-instance-%[1]d"
+    persistent = %[2]t
+    shape      = "oc3"
+    image_list = "/oracle/public/OL_7.2_UEKR4_x86_64"
+  }
+
+  instance {
+    name       = "acc-test-instance-two-%[1]d"
+    shape      = "%[3]s"
+    image_list = "/oracle/public/OL_7.2_UEKR4_x86_64"
+  }
+}
+`, rInt, persistent, shape)
+}
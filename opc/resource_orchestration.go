@@ -0,0 +1,955 @@
+package opc
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+// The orchestration object types besides instances, which is the only type
+// go-oracle-terraform defines
+const (
+	orchestrationTypeStorageVolume compute.OrchestrationType = "StorageVolume"
+	orchestrationTypeIPNetwork     compute.OrchestrationType = "IpNetwork"
+	orchestrationTypeIPReservation compute.OrchestrationType = "IpReservation"
+	orchestrationTypeSecurityList  compute.OrchestrationType = "SecList"
+	orchestrationTypeSecRule       compute.OrchestrationType = "SecRule"
+)
+
+// orchestrationObjectType maps an object block of opc_compute_orchestration
+// to the type and template of the orchestration objects it describes.
+type orchestrationObjectType struct {
+	key        string
+	objectType compute.OrchestrationType
+	// expand returns the template of the object in the block at prefix
+	expand func(d *schema.ResourceData, prefix string, client *computeCollectionClient) (interface{}, error)
+	// flatten returns the block attributes held in the template of an object
+	flatten func(template map[string]interface{}, client *computeCollectionClient) (map[string]interface{}, error)
+}
+
+// Instances are expanded and read by the same functions as the instances of
+// opc_compute_orchestrated_instance, so they aren't listed here.
+var orchestrationObjectTypes = []orchestrationObjectType{
+	{"storage_volume", orchestrationTypeStorageVolume, expandOrchestrationStorageVolume, flattenOrchestrationStorageVolume},
+	{"ip_network", orchestrationTypeIPNetwork, expandOrchestrationIPNetwork, flattenOrchestrationIPNetwork},
+	{"ip_reservation", orchestrationTypeIPReservation, expandOrchestrationIPReservation, flattenOrchestrationIPReservation},
+	{"security_list", orchestrationTypeSecurityList, expandOrchestrationSecurityList, flattenOrchestrationSecurityList},
+	{"sec_rule", orchestrationTypeSecRule, expandOrchestrationSecRule, flattenOrchestrationSecRule},
+}
+
+func resourceOPCOrchestration() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceOPCOrchestrationCreate,
+		Read:   resourceOPCOrchestrationRead,
+		Update: resourceOPCOrchestrationUpdate,
+		Delete: resourceOPCOrchestrationDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: resourceOPCOrchestrationCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"desired_state": {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"active",
+					"inactive",
+					"suspend",
+				}, true),
+			},
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
+
+			// The objects are updated in place, by updating the orchestration
+			// with the objects which changed
+			"instance": orchestrationObjectInstanceSchema(),
+
+			"storage_volume": orchestrationObjectSchema(map[string]*schema.Schema{
+				"size": {
+					Type:         schema.TypeInt,
+					Required:     true,
+					ValidateFunc: validation.IntBetween(1, 2048),
+				},
+				"storage_type": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  compute.StorageVolumeKindDefault,
+				},
+				"bootable": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"image_list": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"image_list_entry": {
+					Type:     schema.TypeInt,
+					Optional: true,
+					Default:  -1,
+				},
+				"description": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"tags": tagsOptionalSchema(),
+			}),
+
+			"ip_network": orchestrationObjectSchema(map[string]*schema.Schema{
+				"ip_address_prefix": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validateIPPrefixCIDR,
+				},
+				"ip_network_exchange": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"public_napt_enabled": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"description": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				"tags": tagsOptionalSchema(),
+			}),
+
+			"ip_reservation": orchestrationObjectSchema(map[string]*schema.Schema{
+				"permanent": {
+					Type:     schema.TypeBool,
+					Required: true,
+				},
+				"parent_pool": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  string(compute.PublicReservationPool),
+				},
+				"tags": tagsOptionalSchema(),
+			}),
+
+			"security_list": orchestrationObjectSchema(map[string]*schema.Schema{
+				"policy": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "deny",
+					ValidateFunc: validation.StringInSlice([]string{
+						string(compute.SecurityListPolicyDeny),
+						string(compute.SecurityListPolicyPermit),
+						string(compute.SecurityListPolicyReject),
+					}, true),
+					DiffSuppressFunc: suppressCaseDifferences,
+				},
+				"outbound_cidr_policy": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "permit",
+					ValidateFunc: validation.StringInSlice([]string{
+						string(compute.SecurityListPolicyDeny),
+						string(compute.SecurityListPolicyPermit),
+						string(compute.SecurityListPolicyReject),
+					}, true),
+					DiffSuppressFunc: suppressCaseDifferences,
+				},
+				"description": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+
+			"sec_rule": orchestrationObjectSchema(map[string]*schema.Schema{
+				"source_list": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"destination_list": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"application": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"action": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Default:  "permit",
+				},
+				"disabled": {
+					Type:     schema.TypeBool,
+					Optional: true,
+					Default:  false,
+				},
+				"description": {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+			}),
+
+			"version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// orchestrationObjectSchema returns the schema of an object block with the
+// given template attributes, and the name, persistent and depends attributes
+// every object has. The name of an object is also its label.
+func orchestrationObjectSchema(attributes map[string]*schema.Schema) *schema.Schema {
+	attributes["name"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Required: true,
+	}
+	attributes["persistent"] = &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+	}
+	attributes["depends"] = orchestrationDependsSchema()
+
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: attributes,
+		},
+	}
+}
+
+// orchestrationObjectInstanceSchema returns the instance block of
+// opc_compute_orchestrated_instance, with the depends attribute of the other
+// object blocks.
+func orchestrationObjectInstanceSchema() *schema.Schema {
+	s := orchestrationInstanceSchema()
+	s.Required = false
+	s.Optional = true
+
+	attributes := s.Elem.(*schema.Resource).Schema
+	attributes["depends"] = orchestrationDependsSchema()
+	return s
+}
+
+func orchestrationDependsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+		Set:      schema.HashString,
+	}
+}
+
+func resourceOPCOrchestrationCreate(d *schema.ResourceData, meta interface{}) error {
+	log.Print("[DEBUG] Creating Orchestration")
+
+	input := compute.CreateOrchestrationInput{
+		Name:         d.Get("name").(string),
+		DesiredState: compute.OrchestrationDesiredState(d.Get("desired_state").(string)),
+	}
+
+	if v, ok := d.GetOk("description"); ok {
+		input.Description = v.(string)
+	}
+
+	tags := expandTags(d, meta)
+	if len(tags) != 0 {
+		input.Tags = tags
+	}
+
+	objects, err := expandOrchestrationObjects(d, meta)
+	if err != nil {
+		return err
+	}
+	input.Objects = objects
+
+	if err := createOrchestration(d, meta, &input); err != nil {
+		return fmt.Errorf("Error creating Orchestration: %s", err)
+	}
+
+	return resourceOPCOrchestrationRead(d, meta)
+}
+
+func resourceOPCOrchestrationRead(d *schema.ResourceData, meta interface{}) error {
+	computeClient, err := meta.(*Client).getComputeClient()
+	if err != nil {
+		return err
+	}
+	resClient := computeClient.Orchestrations()
+
+	log.Printf("[DEBUG] Reading state of Orchestration %s", d.Id())
+	getInput := compute.GetOrchestrationInput{
+		Name: d.Id(),
+	}
+
+	result, err := resClient.GetOrchestration(&getInput)
+	if err != nil {
+		// Orchestration does not exist
+		if wasNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), newAPIError(err))
+	}
+
+	if result == nil {
+		d.SetId("")
+		return nil
+	}
+
+	log.Printf("[DEBUG] Read state of Orchestration %s: %#v", d.Id(), result)
+	d.Set("name", result.Name)
+	d.Set("version", result.Version)
+	d.Set("description", result.Description)
+	d.Set("desired_state", result.DesiredState)
+
+	if err := flattenTags(d, meta, result.Tags); err != nil {
+		return err
+	}
+
+	return flattenOrchestrationObjects(d, meta, result)
+}
+
+func resourceOPCOrchestrationUpdate(d *schema.ResourceData, meta interface{}) error {
+	log.Print("[DEBUG] Updating Orchestration")
+
+	computeClient, err := meta.(*Client).getComputeClient()
+	if err != nil {
+		return err
+	}
+	resClient := computeClient.Orchestrations()
+
+	// The orchestration is updated with the objects it holds, along with the
+	// changes to the object blocks
+	getInput := compute.GetOrchestrationInput{
+		Name: d.Id(),
+	}
+
+	result, err := resClient.GetOrchestration(&getInput)
+	if err != nil {
+		// An orchestration which no longer exists is only removed from the
+		// state by a refresh, so the update fails
+		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), newAPIError(err))
+	}
+
+	input := compute.UpdateOrchestrationInput{
+		Name:         d.Get("name").(string),
+		DesiredState: compute.OrchestrationDesiredState(d.Get("desired_state").(string)),
+		Version:      d.Get("version").(int),
+	}
+
+	if v, ok := d.GetOk("description"); ok {
+		input.Description = v.(string)
+	}
+
+	tags := expandTags(d, meta)
+	if len(tags) != 0 {
+		input.Tags = tags
+	}
+
+	objects, err := updateOrchestrationObjects(d, meta, result.Objects)
+	if err != nil {
+		return err
+	}
+	input.Objects = objects
+
+	if err := updateOrchestration(d, meta, &input); err != nil {
+		return err
+	}
+
+	return resourceOPCOrchestrationRead(d, meta)
+}
+
+func resourceOPCOrchestrationDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] Deleting Orchestration %s", d.Id())
+
+	if err := deleteOrchestration(meta, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return fmt.Errorf("Error deleting Orchestration %s: %s", d.Id(), err)
+	}
+
+	return nil
+}
+
+// createOrchestration creates an orchestration, and waits for it to reach its
+// desired state. The id is set once the orchestration is created, so that one
+// which fails to reach its desired state is tainted rather than left behind.
+func createOrchestration(d *schema.ResourceData, meta interface{}, input *compute.CreateOrchestrationInput) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	qualified := *input
+	qualified.Name = client.qualify(input.Name)
+	qualified.Objects = qualifyOrchestrationObjects(client, input.Objects)
+
+	if err := client.do("POST", orchestrationRootPath+"/", &qualified, nil); err != nil {
+		return newAPIError(err)
+	}
+
+	d.SetId(input.Name)
+	return waitForOrchestration(meta, d.Id(), input.DesiredState, d.Timeout(schema.TimeoutCreate))
+}
+
+// updateOrchestration updates an orchestration, and waits for it to reach its
+// desired state. The service rejects the update when the orchestration has
+// changed since the version in the input was read.
+func updateOrchestration(d *schema.ResourceData, meta interface{}, input *compute.UpdateOrchestrationInput) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	qualified := *input
+	qualified.Name = client.qualify(input.Name)
+	qualified.Objects = qualifyOrchestrationObjects(client, input.Objects)
+
+	if err := client.do("PUT", orchestrationRootPath+qualified.Name, &qualified, nil); err != nil {
+		if wasConflictError(err) {
+			return fmt.Errorf("Error updating Orchestration %s, which has changed since version %d was read. Refresh it and try again: %s", d.Id(), input.Version, newAPIError(err))
+		}
+		return fmt.Errorf("Error updating Orchestration: %s", newAPIError(err))
+	}
+
+	if err := waitForOrchestration(meta, d.Id(), input.DesiredState, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return fmt.Errorf("Error updating Orchestration: %s", err)
+	}
+	return nil
+}
+
+// qualifyOrchestrationObjects qualifies the names in the instance templates of
+// objects, as the Orchestrations client does. The templates of new instances
+// are inputs, and those of the instances read from the service are maps.
+func qualifyOrchestrationObjects(client *computeCollectionClient, objects []compute.Object) []compute.Object {
+	qualified := make([]compute.Object, len(objects))
+	for i, object := range objects {
+		if object.Type == compute.OrchestrationTypeInstance {
+			switch template := object.Template.(type) {
+			case *compute.CreateInstanceInput:
+				object.Template = qualifyInstanceInput(client, template)
+			case map[string]interface{}:
+				instance := make(map[string]interface{}, len(template))
+				for k, v := range template {
+					instance[k] = v
+				}
+				if name, ok := template["name"].(string); ok {
+					instance["name"] = client.qualify(name)
+				}
+				object.Template = instance
+			}
+		}
+		qualified[i] = object
+	}
+	return qualified
+}
+
+// The names of the objects must be unique, as they are also their labels,
+// and an object can only depend on the other objects of the orchestration.
+func resourceOPCOrchestrationCustomizeDiff(diff *schema.ResourceDiff, v interface{}) error {
+	if err := customizeDiffTagsAll(diff, v); err != nil {
+		return err
+	}
+	if err := customizeDiffOrchestrationInstances(diff); err != nil {
+		return err
+	}
+
+	keys := orchestrationObjectKeys()
+	if err := customizeDiffOrchestrationObjectTypes(diff, keys); err != nil {
+		return err
+	}
+
+	names := make(map[string]string)
+	for _, key := range keys {
+		for i := 0; i < diff.Get(fmt.Sprintf("%s.#", key)).(int); i++ {
+			prefix := fmt.Sprintf("%s.%d", key, i)
+			if !diff.NewValueKnown(fmt.Sprintf("%s.name", prefix)) {
+				// The dependencies can't be checked until every name is known
+				return nil
+			}
+			name := diff.Get(fmt.Sprintf("%s.name", prefix)).(string)
+			if other, ok := names[name]; ok {
+				return fmt.Errorf("%s %s: the name is already used by a %s, object names must be unique within an orchestration", key, name, other)
+			}
+			names[name] = key
+		}
+	}
+
+	for _, key := range keys {
+		for i := 0; i < diff.Get(fmt.Sprintf("%s.#", key)).(int); i++ {
+			prefix := fmt.Sprintf("%s.%d", key, i)
+			if !diff.NewValueKnown(fmt.Sprintf("%s.depends", prefix)) {
+				continue
+			}
+			name := diff.Get(fmt.Sprintf("%s.name", prefix)).(string)
+			for _, target := range diff.Get(fmt.Sprintf("%s.depends", prefix)).(*schema.Set).List() {
+				if target == name {
+					return fmt.Errorf("%s %s: an object can't depend on itself", key, name)
+				}
+				if _, ok := names[target.(string)]; !ok {
+					return fmt.Errorf("%s %s: depends on %q, which is not an object of the orchestration", key, name, target)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// customizeDiffOrchestrationObjectTypes replaces the orchestration when an
+// object moves to a block of another type, as the type of an object can't be
+// changed.
+func customizeDiffOrchestrationObjectTypes(diff *schema.ResourceDiff, keys []string) error {
+	if diff.Id() == "" {
+		return nil
+	}
+
+	types := make(map[string]string)
+	for _, key := range keys {
+		o, _ := diff.GetChange(key)
+		for name := range orchestrationObjectsByName(o.([]interface{})) {
+			types[name] = key
+		}
+	}
+
+	for _, key := range keys {
+		if !diff.HasChange(key) {
+			continue
+		}
+		for i := 0; i < diff.Get(fmt.Sprintf("%s.#", key)).(int); i++ {
+			prefix := fmt.Sprintf("%s.%d", key, i)
+			if !diff.NewValueKnown(fmt.Sprintf("%s.name", prefix)) {
+				continue
+			}
+			name := diff.Get(fmt.Sprintf("%s.name", prefix)).(string)
+			if previous, ok := types[name]; ok && previous != key {
+				log.Printf("[DEBUG] Orchestration %s: %s %s was a %s", diff.Id(), key, name, previous)
+				return diff.ForceNew(key)
+			}
+		}
+	}
+	return nil
+}
+
+// orchestrationObjectKeys returns the keys of the object blocks.
+func orchestrationObjectKeys() []string {
+	keys := []string{"instance"}
+	for _, t := range orchestrationObjectTypes {
+		keys = append(keys, t.key)
+	}
+	return keys
+}
+
+func expandOrchestrationObjects(d *schema.ResourceData, meta interface{}) ([]compute.Object, error) {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]compute.Object, 0)
+	for i := 0; i < d.Get("instance.#").(int); i++ {
+		prefix := fmt.Sprintf("instance.%d", i)
+		template, err := expandCreateInstanceInput(prefix, d)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, expandOrchestrationObject(d, prefix, compute.OrchestrationTypeInstance, template))
+	}
+
+	for _, t := range orchestrationObjectTypes {
+		for i := 0; i < d.Get(fmt.Sprintf("%s.#", t.key)).(int); i++ {
+			prefix := fmt.Sprintf("%s.%d", t.key, i)
+			template, err := t.expand(d, prefix, collectionClient)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %s", t.key, d.Get(fmt.Sprintf("%s.name", prefix)), err)
+			}
+			objects = append(objects, expandOrchestrationObject(d, prefix, t.objectType, template))
+		}
+	}
+
+	return objects, nil
+}
+
+func expandOrchestrationObject(d *schema.ResourceData, prefix string, objectType compute.OrchestrationType, template interface{}) compute.Object {
+	object := compute.Object{
+		Label:         d.Get(fmt.Sprintf("%s.name", prefix)).(string),
+		Orchestration: d.Get("name").(string),
+		Type:          objectType,
+		Template:      template,
+		Persistent:    d.Get(fmt.Sprintf("%s.persistent", prefix)).(bool),
+	}
+
+	object.Relationships = expandOrchestrationRelationships(d, prefix)
+
+	return object
+}
+
+func expandOrchestrationRelationships(d *schema.ResourceData, prefix string) []compute.Relationship {
+	depends := getStringSet(d, fmt.Sprintf("%s.depends", prefix))
+	if len(depends) == 0 {
+		return nil
+	}
+	return []compute.Relationship{{
+		Type:    compute.OrchestrationRelationshipTypeDepends,
+		Targets: depends,
+	}}
+}
+
+// updateOrchestrationObjects returns the objects of the orchestration with
+// the changes to the object blocks applied. Instances are updated as they
+// are for opc_compute_orchestrated_instance, while the objects of the other
+// types which changed are expanded again from their blocks. The objects of
+// unchanged blocks are returned as read, so that the service leaves them as
+// they are.
+func updateOrchestrationObjects(d *schema.ResourceData, meta interface{}, objects []compute.Object) ([]compute.Object, error) {
+	if d.HasChange("instance") {
+		var err error
+		if objects, err = updateOrchestrationInstances(d, meta, objects); err != nil {
+			return nil, err
+		}
+	}
+
+	// The objects the instances depend on are updated in place
+	instances := make(map[string]string)
+	for i := 0; i < d.Get("instance.#").(int); i++ {
+		prefix := fmt.Sprintf("instance.%d", i)
+		instances[d.Get(fmt.Sprintf("%s.name", prefix)).(string)] = prefix
+	}
+
+	changes := make(map[string]string)
+	for _, t := range orchestrationObjectTypes {
+		if !d.HasChange(t.key) {
+			continue
+		}
+		attributes := resourceOPCOrchestration().Schema[t.key].Elem.(*schema.Resource).Schema
+		o, n := d.GetChange(t.key)
+		for name, change := range orchestrationObjectChanges(attributes, o.([]interface{}), n.([]interface{})) {
+			log.Printf("[DEBUG] Orchestration %s: %s %s %s", d.Id(), change, t.key, name)
+			changes[name] = change
+		}
+	}
+
+	result := make([]compute.Object, 0, len(objects))
+	for _, object := range objects {
+		if object.Type == compute.OrchestrationTypeInstance {
+			if prefix, ok := instances[object.Label]; ok {
+				object.Relationships = expandOrchestrationRelationships(d, prefix)
+			}
+		} else if changes[object.Label] != "" {
+			// Changed objects are added from their blocks below
+			continue
+		}
+		result = append(result, object)
+	}
+
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range orchestrationObjectTypes {
+		for i := 0; i < d.Get(fmt.Sprintf("%s.#", t.key)).(int); i++ {
+			prefix := fmt.Sprintf("%s.%d", t.key, i)
+			name := d.Get(fmt.Sprintf("%s.name", prefix)).(string)
+			if changes[name] == "" || changes[name] == orchestrationObjectDelete {
+				continue
+			}
+			template, err := t.expand(d, prefix, collectionClient)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %s", t.key, name, err)
+			}
+			result = append(result, expandOrchestrationObject(d, prefix, t.objectType, template))
+		}
+	}
+
+	return result, nil
+}
+
+// flattenOrchestrationObjects sets the object blocks from the objects of the
+// orchestration. Objects are matched to the blocks by their label, and any
+// object which isn't in the configuration is added after them.
+func flattenOrchestrationObjects(d *schema.ResourceData, meta interface{}, orchestration *compute.Orchestration) error {
+	collectionClient, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	// Instances can only be read while the orchestration is active, and not
+	// while it's still starting or has failed to start
+	if orchestration.DesiredState == compute.OrchestrationDesiredStateActive && orchestration.Status == compute.OrchestrationStatusActive {
+		objects, indexes := orderOrchestrationObjects(d, "instance", compute.OrchestrationTypeInstance, orchestration.Objects)
+		instances := make([]interface{}, len(objects))
+		for i, object := range objects {
+			v, err := flattenOrchestratedInstance(d, meta, fmt.Sprintf("instance.%d", indexes[i]), object.Label, object.Persistent)
+			if err != nil {
+				return err
+			}
+			v["depends"] = flattenOrchestrationRelationships(object.Relationships)
+			instances[i] = v
+		}
+		if err := d.Set("instance", instances); err != nil {
+			return fmt.Errorf("Error setting instance: %s", err)
+		}
+	}
+
+	for _, t := range orchestrationObjectTypes {
+		objects, _ := orderOrchestrationObjects(d, t.key, t.objectType, orchestration.Objects)
+		result := make([]interface{}, len(objects))
+		for i, object := range objects {
+			template, ok := object.Template.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("Unexpected template of %s %s: %#v", t.key, object.Label, object.Template)
+			}
+			v, err := t.flatten(template, collectionClient)
+			if err != nil {
+				return fmt.Errorf("Error reading %s %s: %s", t.key, object.Label, err)
+			}
+			v["name"] = object.Label
+			v["persistent"] = object.Persistent
+			v["depends"] = flattenOrchestrationRelationships(object.Relationships)
+			result[i] = v
+		}
+		if err := d.Set(t.key, result); err != nil {
+			return fmt.Errorf("Error setting %s: %s", t.key, err)
+		}
+	}
+
+	return nil
+}
+
+// orderOrchestrationObjects returns the objects of the given type in the
+// order of the key blocks, followed by the other objects of the type sorted
+// by label, along with the index of the block of each object. Objects which
+// aren't in the configuration get an index past the last block.
+func orderOrchestrationObjects(d *schema.ResourceData, key string, objectType compute.OrchestrationType, objects []compute.Object) ([]compute.Object, []int) {
+	byLabel := make(map[string]compute.Object)
+	labels := make([]string, 0)
+	for _, object := range objects {
+		if object.Type == objectType {
+			byLabel[object.Label] = object
+			labels = append(labels, object.Label)
+		}
+	}
+	sort.Strings(labels)
+
+	result := make([]compute.Object, 0, len(byLabel))
+	indexes := make([]int, 0, len(byLabel))
+	count := d.Get(fmt.Sprintf("%s.#", key)).(int)
+	for i := 0; i < count; i++ {
+		label := d.Get(fmt.Sprintf("%s.%d.name", key, i)).(string)
+		if object, ok := byLabel[label]; ok {
+			result = append(result, object)
+			indexes = append(indexes, i)
+			delete(byLabel, label)
+		}
+	}
+	for _, label := range labels {
+		if object, ok := byLabel[label]; ok {
+			result = append(result, object)
+			indexes = append(indexes, count)
+			delete(byLabel, label)
+		}
+	}
+
+	return result, indexes
+}
+
+func flattenOrchestrationRelationships(relationships []compute.Relationship) []string {
+	depends := make([]string, 0)
+	for _, relationship := range relationships {
+		if relationship.Type == compute.OrchestrationRelationshipTypeDepends {
+			depends = append(depends, relationship.Targets...)
+		}
+	}
+	sort.Strings(depends)
+	return depends
+}
+
+func expandOrchestrationStorageVolume(d *schema.ResourceData, prefix string, client *computeCollectionClient) (interface{}, error) {
+	input := &compute.CreateStorageVolumeInput{
+		Name:           client.qualify(d.Get(fmt.Sprintf("%s.name", prefix)).(string)),
+		Size:           fmt.Sprintf("%dG", d.Get(fmt.Sprintf("%s.size", prefix)).(int)),
+		Properties:     []string{d.Get(fmt.Sprintf("%s.storage_type", prefix)).(string)},
+		Bootable:       d.Get(fmt.Sprintf("%s.bootable", prefix)).(bool),
+		ImageList:      client.qualify(d.Get(fmt.Sprintf("%s.image_list", prefix)).(string)),
+		ImageListEntry: d.Get(fmt.Sprintf("%s.image_list_entry", prefix)).(int),
+		Description:    d.Get(fmt.Sprintf("%s.description", prefix)).(string),
+		Tags:           getStringList(d, fmt.Sprintf("%s.tags", prefix)),
+	}
+
+	if input.Bootable && input.ImageList == "" {
+		return nil, fmt.Errorf("image_list must be set for a bootable storage volume")
+	}
+
+	return input, nil
+}
+
+func flattenOrchestrationStorageVolume(template map[string]interface{}, client *computeCollectionClient) (map[string]interface{}, error) {
+	size, err := parseOrchestrationVolumeSize(fmt.Sprintf("%v", template["size"]))
+	if err != nil {
+		return nil, err
+	}
+
+	v := map[string]interface{}{
+		"size":             size,
+		"storage_type":     compute.StorageVolumeKindDefault,
+		"bootable":         orchestrationTemplateBool(template, "bootable"),
+		"image_list":       client.unqualify(orchestrationTemplateString(template, "imagelist")),
+		"image_list_entry": -1,
+		"description":      orchestrationTemplateString(template, "description"),
+		"tags":             orchestrationTemplateStringList(template, "tags"),
+	}
+	if properties := orchestrationTemplateStringList(template, "properties"); len(properties) > 0 {
+		v["storage_type"] = properties[0]
+	}
+	if entry, ok := template["imagelist_entry"]; ok {
+		if v["image_list_entry"], err = strconv.Atoi(fmt.Sprintf("%v", entry)); err != nil {
+			return nil, fmt.Errorf("Error parsing imagelist_entry %v: %s", entry, err)
+		}
+	}
+	return v, nil
+}
+
+// parseOrchestrationVolumeSize returns the size in GB of a storage volume
+// template, which the service may report in bytes rather than in the units
+// it was created with.
+func parseOrchestrationVolumeSize(size string) (int, error) {
+	if strings.HasSuffix(strings.ToUpper(size), "G") {
+		return strconv.Atoi(size[:len(size)-1])
+	}
+	return sizeInGigaBytes(size)
+}
+
+func expandOrchestrationIPNetwork(d *schema.ResourceData, prefix string, client *computeCollectionClient) (interface{}, error) {
+	return &compute.CreateIPNetworkInput{
+		Name:              client.qualify(d.Get(fmt.Sprintf("%s.name", prefix)).(string)),
+		IPAddressPrefix:   d.Get(fmt.Sprintf("%s.ip_address_prefix", prefix)).(string),
+		IPNetworkExchange: client.qualify(d.Get(fmt.Sprintf("%s.ip_network_exchange", prefix)).(string)),
+		PublicNaptEnabled: d.Get(fmt.Sprintf("%s.public_napt_enabled", prefix)).(bool),
+		Description:       d.Get(fmt.Sprintf("%s.description", prefix)).(string),
+		Tags:              getStringList(d, fmt.Sprintf("%s.tags", prefix)),
+	}, nil
+}
+
+func flattenOrchestrationIPNetwork(template map[string]interface{}, client *computeCollectionClient) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"ip_address_prefix":   orchestrationTemplateString(template, "ipAddressPrefix"),
+		"ip_network_exchange": client.unqualify(orchestrationTemplateString(template, "ipNetworkExchange")),
+		"public_napt_enabled": orchestrationTemplateBool(template, "publicNaptEnabledFlag"),
+		"description":         orchestrationTemplateString(template, "description"),
+		"tags":                orchestrationTemplateStringList(template, "tags"),
+	}, nil
+}
+
+func expandOrchestrationIPReservation(d *schema.ResourceData, prefix string, client *computeCollectionClient) (interface{}, error) {
+	return &compute.CreateIPReservationInput{
+		Name:       client.qualify(d.Get(fmt.Sprintf("%s.name", prefix)).(string)),
+		ParentPool: compute.IPReservationPool(d.Get(fmt.Sprintf("%s.parent_pool", prefix)).(string)),
+		Permanent:  d.Get(fmt.Sprintf("%s.permanent", prefix)).(bool),
+		Tags:       getStringList(d, fmt.Sprintf("%s.tags", prefix)),
+	}, nil
+}
+
+func flattenOrchestrationIPReservation(template map[string]interface{}, client *computeCollectionClient) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"parent_pool": orchestrationTemplateString(template, "parentpool"),
+		"permanent":   orchestrationTemplateBool(template, "permanent"),
+		"tags":        orchestrationTemplateStringList(template, "tags"),
+	}, nil
+}
+
+func expandOrchestrationSecurityList(d *schema.ResourceData, prefix string, client *computeCollectionClient) (interface{}, error) {
+	return &compute.CreateSecurityListInput{
+		Name:               client.qualify(d.Get(fmt.Sprintf("%s.name", prefix)).(string)),
+		Policy:             compute.SecurityListPolicy(strings.ToUpper(d.Get(fmt.Sprintf("%s.policy", prefix)).(string))),
+		OutboundCIDRPolicy: compute.SecurityListPolicy(strings.ToUpper(d.Get(fmt.Sprintf("%s.outbound_cidr_policy", prefix)).(string))),
+		Description:        d.Get(fmt.Sprintf("%s.description", prefix)).(string),
+	}, nil
+}
+
+func flattenOrchestrationSecurityList(template map[string]interface{}, client *computeCollectionClient) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"policy":               orchestrationTemplateString(template, "policy"),
+		"outbound_cidr_policy": orchestrationTemplateString(template, "outbound_cidr_policy"),
+		"description":          orchestrationTemplateString(template, "description"),
+	}, nil
+}
+
+func expandOrchestrationSecRule(d *schema.ResourceData, prefix string, client *computeCollectionClient) (interface{}, error) {
+	return &compute.CreateSecRuleInput{
+		Name:            client.qualify(d.Get(fmt.Sprintf("%s.name", prefix)).(string)),
+		SourceList:      client.qualifyListName(d.Get(fmt.Sprintf("%s.source_list", prefix)).(string)),
+		DestinationList: client.qualifyListName(d.Get(fmt.Sprintf("%s.destination_list", prefix)).(string)),
+		Application:     client.qualify(d.Get(fmt.Sprintf("%s.application", prefix)).(string)),
+		Action:          d.Get(fmt.Sprintf("%s.action", prefix)).(string),
+		Disabled:        d.Get(fmt.Sprintf("%s.disabled", prefix)).(bool),
+		Description:     d.Get(fmt.Sprintf("%s.description", prefix)).(string),
+	}, nil
+}
+
+func flattenOrchestrationSecRule(template map[string]interface{}, client *computeCollectionClient) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"source_list":      client.unqualifyListName(orchestrationTemplateString(template, "src_list")),
+		"destination_list": client.unqualifyListName(orchestrationTemplateString(template, "dst_list")),
+		"application":      client.unqualify(orchestrationTemplateString(template, "application")),
+		"action":           orchestrationTemplateString(template, "action"),
+		"disabled":         orchestrationTemplateBool(template, "disabled"),
+		"description":      orchestrationTemplateString(template, "description"),
+	}, nil
+}
+
+func orchestrationTemplateString(template map[string]interface{}, key string) string {
+	if v, ok := template[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func orchestrationTemplateBool(template map[string]interface{}, key string) bool {
+	switch v := template[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+func orchestrationTemplateStringList(template map[string]interface{}, key string) []string {
+	values, _ := template[key].([]interface{})
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
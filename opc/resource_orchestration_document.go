@@ -0,0 +1,544 @@
+package opc
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const orchestrationRootPath = "/platform/v1/orchestration"
+
+// The attributes of an orchestration, and of its objects, which the service
+// maintains. They are left out of the document read from the service.
+var (
+	orchestrationServerAttributes = []string{"id", "uri", "status", "user", "version", "time_audited", "time_created", "time_updated"}
+	orchestrationObjectAttributes = []string{"id", "uri", "health", "user", "version", "time_audited", "time_created", "time_updated"}
+)
+
+func resourceOPCOrchestrationDocument() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceOPCOrchestrationDocumentCreate,
+		Read:   resourceOPCOrchestrationDocumentRead,
+		Update: resourceOPCOrchestrationDocumentUpdate,
+		Delete: resourceOPCOrchestrationDocumentDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: resourceOPCOrchestrationDocumentCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(20 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"document": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateOrchestrationDocument,
+				DiffSuppressFunc: suppressOrchestrationDocumentDiff,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"desired_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceOPCOrchestrationDocumentCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	document := d.Get("document").(string)
+	input, err := expandOrchestrationDocument(document)
+	if err != nil {
+		return err
+	}
+
+	// The document is sent as is, as the Orchestrations client rewrites the
+	// templates of the objects it creates
+	log.Printf("[DEBUG] Creating Orchestration %s from its document", input.Name)
+	if err := client.do("POST", orchestrationRootPath+"/", json.RawMessage(document), nil); err != nil {
+		return fmt.Errorf("Error creating Orchestration %s: %s", input.Name, newAPIError(err))
+	}
+
+	d.SetId(client.unqualify(input.Name))
+
+	if err := waitForOrchestration(meta, d.Id(), input.DesiredState, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return fmt.Errorf("Error waiting for Orchestration %s: %s", d.Id(), err)
+	}
+
+	return resourceOPCOrchestrationDocumentRead(d, meta)
+}
+
+func resourceOPCOrchestrationDocumentRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Reading state of Orchestration %s", d.Id())
+	result, err := getOrchestrationDocument(client, d.Id())
+	if err != nil {
+		// Orchestration does not exist
+		if wasNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), newAPIError(err))
+	}
+
+	var info compute.Orchestration
+	if err := decodeOrchestrationDocument(result, &info); err != nil {
+		return fmt.Errorf("Error reading Orchestration %s: %s", d.Id(), err)
+	}
+
+	d.Set("name", client.unqualify(info.FQDN))
+	d.Set("desired_state", info.DesiredState)
+	d.Set("status", info.Status)
+	d.Set("version", info.Version)
+
+	// The configured document is kept for as long as the service holds
+	// everything it sets, so that it isn't rewritten on every read
+	server := flattenOrchestrationDocument(result)
+	if current, err := parseOrchestrationDocument(d.Get("document").(string)); err == nil && orchestrationDocumentContains(server, current) {
+		return nil
+	}
+
+	document, err := json.MarshalIndent(server, "", "  ")
+	if err != nil {
+		return err
+	}
+	d.Set("document", string(document))
+
+	return nil
+}
+
+func resourceOPCOrchestrationDocumentUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	document := d.Get("document").(string)
+	input, err := expandOrchestrationDocument(document)
+	if err != nil {
+		return err
+	}
+
+	// The service rejects the update when the orchestration has changed since
+	// the version which was read, e.g. when it has been edited in the console
+	body, err := parseOrchestrationDocument(document)
+	if err != nil {
+		return err
+	}
+	body.(map[string]interface{})["version"] = d.Get("version").(int)
+
+	log.Printf("[DEBUG] Updating Orchestration %s from its document", d.Id())
+	if err := client.do("PUT", orchestrationRootPath+client.qualify(d.Id()), body, nil); err != nil {
+		if wasConflictError(err) {
+			return fmt.Errorf("Error updating Orchestration %s, which has changed since version %d was read. Refresh it and try again: %s", d.Id(), d.Get("version").(int), newAPIError(err))
+		}
+		return fmt.Errorf("Error updating Orchestration %s: %s", d.Id(), newAPIError(err))
+	}
+
+	if err := waitForOrchestration(meta, d.Id(), input.DesiredState, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return fmt.Errorf("Error waiting for Orchestration %s: %s", d.Id(), err)
+	}
+
+	return resourceOPCOrchestrationDocumentRead(d, meta)
+}
+
+func resourceOPCOrchestrationDocumentDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] Deleting Orchestration %s", d.Id())
+
+	if err := deleteOrchestration(meta, d.Id(), d.Timeout(schema.TimeoutDelete)); err != nil {
+		return fmt.Errorf("Error deleting Orchestration %s: %s", d.Id(), err)
+	}
+
+	return nil
+}
+
+// A document for another orchestration replaces it.
+func resourceOPCOrchestrationDocumentCustomizeDiff(diff *schema.ResourceDiff, v interface{}) error {
+	if diff.Id() == "" || !diff.HasChange("document") {
+		return nil
+	}
+
+	o, n := diff.GetChange("document")
+	oldInput, err := expandOrchestrationDocument(o.(string))
+	if err != nil {
+		return nil
+	}
+	newInput, err := expandOrchestrationDocument(n.(string))
+	if err != nil {
+		return nil
+	}
+	if !equalOrchestrationNames(oldInput.Name, newInput.Name) {
+		return diff.ForceNew("document")
+	}
+	return nil
+}
+
+// orchestrationStatuses are the statuses an orchestration passes through,
+// which are pending while waiting for any other status.
+var orchestrationStatuses = []compute.OrchestrationStatus{
+	compute.OrchestrationStatusActive,
+	compute.OrchestrationStatusInactive,
+	compute.OrchestrationStatusSuspended,
+	compute.OrchestrationStatusActivating,
+	compute.OrchestrationStatusStarting,
+	compute.OrchestrationStatusStopping,
+	compute.OrchestrationStatusSuspending,
+	compute.OrchestrationStatusDeactivating,
+	compute.OrchestrationStatusDeleting,
+}
+
+// waitForOrchestration waits for an orchestration to reach its desired state.
+// The Orchestrations client can't be used to read an orchestration whose
+// instance templates it doesn't recognize, and waits for the orchestration
+// at a fixed interval which can't be cancelled.
+func waitForOrchestration(meta interface{}, name string, desiredState compute.OrchestrationDesiredState, timeout time.Duration) error {
+	target := string(desiredState)
+	if desiredState == compute.OrchestrationDesiredStateSuspend {
+		target = string(compute.OrchestrationStatusSuspended)
+	}
+	return waitForOrchestrationStatus(meta, name, target, timeout)
+}
+
+// deleteOrchestration deletes an orchestration, along with the objects it
+// created, and waits for it to be gone.
+func deleteOrchestration(meta interface{}, name string, timeout time.Duration) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	if err := client.do("DELETE", orchestrationRootPath+client.qualify(name)+"?terminate=True", nil, nil); err != nil {
+		return newAPIError(err)
+	}
+	return waitForOrchestrationStatus(meta, name, waitStateDeleted, timeout)
+}
+
+func waitForOrchestrationStatus(meta interface{}, name, target string, timeout time.Duration) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return err
+	}
+
+	pending := make([]string, 0, len(orchestrationStatuses))
+	for _, status := range orchestrationStatuses {
+		if string(status) != target {
+			pending = append(pending, string(status))
+		}
+	}
+
+	conf := waitConf{
+		Description:     fmt.Sprintf("Orchestration %s to be %s", name, target),
+		Pending:         pending,
+		Target:          []string{target},
+		Refresh:         orchestrationStatus(client, name),
+		Timeout:         timeout,
+		MinPollInterval: 2 * time.Second,
+	}
+	if _, err := waitForState(meta.(*Client).stopContext, conf); err != nil {
+		return newAPIError(err)
+	}
+	return nil
+}
+
+// orchestrationStatus reports the status of an orchestration, or
+// waitStateDeleted once it no longer exists. An orchestration in the error
+// state fails the wait with the health of the objects which failed.
+func orchestrationStatus(client *computeCollectionClient, name string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		result, err := getOrchestrationDocument(client, name)
+		if err != nil {
+			if wasNotFoundError(err) {
+				return nil, waitStateDeleted, nil
+			}
+			return nil, "", err
+		}
+
+		var info compute.Orchestration
+		if err := decodeOrchestrationDocument(result, &info); err != nil {
+			return nil, "", err
+		}
+
+		if info.Status == compute.OrchestrationStatusError {
+			for _, object := range info.Objects {
+				if object.Health.Status == compute.OrchestrationStatusError {
+					return nil, "", fmt.Errorf("Error in object %s of Orchestration %s: %+v", object.Label, name, object.Health)
+				}
+			}
+			return nil, "", fmt.Errorf("Orchestration %s is in the %s state", name, info.Status)
+		}
+		return &info, string(info.Status), nil
+	}
+}
+
+func getOrchestrationDocument(client *computeCollectionClient, name string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := client.do("GET", orchestrationRootPath+client.qualify(name), nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func decodeOrchestrationDocument(document interface{}, result interface{}) error {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, result)
+}
+
+// flattenOrchestrationDocument returns the document of an orchestration read
+// from the service, without the attributes the service maintains.
+func flattenOrchestrationDocument(result map[string]interface{}) map[string]interface{} {
+	document := make(map[string]interface{})
+	for k, v := range result {
+		document[k] = v
+	}
+	for _, k := range orchestrationServerAttributes {
+		delete(document, k)
+	}
+
+	if objects, ok := document["objects"].([]interface{}); ok {
+		flattened := make([]interface{}, len(objects))
+		for i, o := range objects {
+			object, ok := o.(map[string]interface{})
+			if !ok {
+				flattened[i] = o
+				continue
+			}
+			f := make(map[string]interface{})
+			for k, v := range object {
+				f[k] = v
+			}
+			for _, k := range orchestrationObjectAttributes {
+				delete(f, k)
+			}
+			flattened[i] = f
+		}
+		document["objects"] = flattened
+	}
+
+	return document
+}
+
+// expandOrchestrationDocument checks that an orchestration document can be
+// decoded into a CreateOrchestrationInput, and that its objects and their
+// relationships are complete.
+func expandOrchestrationDocument(document string) (*compute.CreateOrchestrationInput, error) {
+	var input compute.CreateOrchestrationInput
+	if err := json.Unmarshal([]byte(document), &input); err != nil {
+		return nil, fmt.Errorf("Error parsing orchestration document: %s", err)
+	}
+
+	if input.Name == "" {
+		return nil, fmt.Errorf("The orchestration document must have a name")
+	}
+	switch input.DesiredState {
+	case compute.OrchestrationDesiredStateActive, compute.OrchestrationDesiredStateInactive, compute.OrchestrationDesiredStateSuspend:
+	default:
+		return nil, fmt.Errorf("The desired_state of the orchestration document must be active, inactive or suspend, got %q", input.DesiredState)
+	}
+	if len(input.Objects) == 0 || len(input.Objects) > 100 {
+		return nil, fmt.Errorf("An orchestration must contain between 1 and 100 objects, got %d", len(input.Objects))
+	}
+
+	labels := make(map[string]bool)
+	for i, object := range input.Objects {
+		if object.Label == "" {
+			return nil, fmt.Errorf("Object %d of the orchestration document must have a label", i)
+		}
+		if labels[object.Label] {
+			return nil, fmt.Errorf("Object %s: the label is already used, labels must be unique within an orchestration", object.Label)
+		}
+		labels[object.Label] = true
+		if object.Type == "" {
+			return nil, fmt.Errorf("Object %s must have a type", object.Label)
+		}
+		if object.Template == nil {
+			return nil, fmt.Errorf("Object %s must have a template", object.Label)
+		}
+	}
+
+	for _, object := range input.Objects {
+		for _, relationship := range object.Relationships {
+			if relationship.Type != compute.OrchestrationRelationshipTypeDepends {
+				return nil, fmt.Errorf("Object %s: unsupported relationship type %q, the only type is %q", object.Label, relationship.Type, compute.OrchestrationRelationshipTypeDepends)
+			}
+			for _, target := range relationship.Targets {
+				if target == object.Label || !labels[target] {
+					return nil, fmt.Errorf("Object %s: depends on %q, which is not another object of the orchestration", object.Label, target)
+				}
+			}
+		}
+	}
+
+	return &input, nil
+}
+
+func validateOrchestrationDocument(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := expandOrchestrationDocument(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %s", k, err))
+	}
+	return
+}
+
+// parseOrchestrationDocument decodes a document for comparison, leaving out
+// the versions of the orchestration and of its objects.
+func parseOrchestrationDocument(document string) (interface{}, error) {
+	var result interface{}
+	if err := json.Unmarshal([]byte(document), &result); err != nil {
+		return nil, err
+	}
+	if m, ok := result.(map[string]interface{}); ok {
+		delete(m, "version")
+		if objects, ok := m["objects"].([]interface{}); ok {
+			for _, o := range objects {
+				if object, ok := o.(map[string]interface{}); ok {
+					delete(object, "version")
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// The configured document doesn't change when it holds the same attributes as
+// the document in the state. The service adds defaults to the documents read
+// from it, so attributes which are only in the state are ignored.
+func suppressOrchestrationDocumentDiff(k, old, new string, d *schema.ResourceData) bool {
+	o, err := parseOrchestrationDocument(old)
+	if err != nil {
+		return false
+	}
+	n, err := parseOrchestrationDocument(new)
+	if err != nil {
+		return false
+	}
+	return orchestrationDocumentContains(o, n)
+}
+
+// orchestrationDocumentContains reports whether every attribute set in want
+// has the same value in got. Lists must be the same length, and objects are
+// matched by their labels. Object names are equal whether or not they are
+// qualified with the user's container.
+func orchestrationDocumentContains(got, want interface{}) bool {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, wv := range w {
+			gv, ok := g[k]
+			if !ok {
+				if wv == nil {
+					continue
+				}
+				return false
+			}
+			if !orchestrationDocumentContains(gv, wv) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		if gotLabels, wantLabels := orchestrationDocumentLabels(g), orchestrationDocumentLabels(w); gotLabels != nil && wantLabels != nil {
+			for label, wv := range wantLabels {
+				if !orchestrationDocumentContains(gotLabels[label], wv) {
+					return false
+				}
+			}
+			return true
+		}
+		for i := range w {
+			if !orchestrationDocumentContains(g[i], w[i]) {
+				return false
+			}
+		}
+		return true
+
+	case string:
+		if g, ok := got.(string); ok {
+			return equalOrchestrationNames(g, w)
+		}
+		return fmt.Sprintf("%v", got) == w
+
+	default:
+		return reflect.DeepEqual(got, want) || fmt.Sprintf("%v", got) == fmt.Sprintf("%v", want)
+	}
+}
+
+// orchestrationDocumentLabels indexes a list of orchestration objects by
+// label, or returns nil if the list holds anything else.
+func orchestrationDocumentLabels(list []interface{}) map[string]interface{} {
+	labels := make(map[string]interface{})
+	for _, v := range list {
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		label, ok := object["label"].(string)
+		if !ok {
+			return nil
+		}
+		labels[label] = object
+	}
+	return labels
+}
+
+// equalOrchestrationNames reports whether two names are the same, when one of
+// them is qualified with a user's container, e.g. /Compute-acme/jdoe/web and
+// web, and when they are prefixed with their type, e.g. seclist:web.
+func equalOrchestrationNames(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if i, j := strings.Index(a, ":"), strings.Index(b, ":"); i > 0 && i == j && a[:i] == b[:j] && !strings.HasPrefix(a, "/") {
+		return equalOrchestrationNames(a[i+1:], b[j+1:])
+	}
+
+	qualified, name := a, b
+	if len(b) > len(a) {
+		qualified, name = b, a
+	}
+	if !strings.HasPrefix(qualified, "/Compute-") || strings.HasPrefix(name, "/") {
+		return false
+	}
+	parts := strings.SplitN(qualified, "/", 4)
+	return len(parts) == 4 && parts[3] == name
+}
@@ -0,0 +1,276 @@
+package opc
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccOPCOrchestrationDocument_Basic(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skip(fmt.Sprintf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar))
+	}
+	// The document holds qualified names, so the user must be known before
+	// the configuration is written
+	testAccPreCheck(t)
+
+	resName := "opc_compute_orchestration_document.test"
+	ri := acctest.RandInt()
+	name := fmt.Sprintf("test-orchestration-%d", ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckOrchestrationDocumentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOrchestrationDocument(ri, "active"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationObjects(name, map[string]compute.OrchestrationType{
+						fmt.Sprintf("test-instance-%d", ri): compute.OrchestrationTypeInstance,
+						fmt.Sprintf("test-list-%d", ri):     orchestrationTypeSecurityList,
+					}),
+					resource.TestCheckResourceAttr(resName, "name", name),
+					resource.TestCheckResourceAttr(resName, "desired_state", "active"),
+					resource.TestCheckResourceAttr(resName, "status", "active"),
+					resource.TestCheckResourceAttr(resName, "version", "1"),
+				),
+			},
+			{
+				Config: testAccOrchestrationDocument(ri, "suspend"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resName, "desired_state", "suspend"),
+					resource.TestCheckResourceAttr(resName, "status", "suspended"),
+					resource.TestCheckResourceAttr(resName, "version", "2"),
+				),
+			},
+			{
+				// An edit in the console is reverted to the document
+				PreConfig: testAccEditOrchestrationDocument(t, name, "edited in the console"),
+				Config:    testAccOrchestrationDocument(ri, "suspend"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationDescription(name, "Managed by Terraform"),
+					resource.TestCheckResourceAttr(resName, "version", "4"),
+				),
+			},
+			{
+				ResourceName:            resName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"document"},
+			},
+		},
+	})
+}
+
+func TestAccOPCOrchestrationDocument_invalid(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "opc_compute_orchestration_document" "test" {
+  document = <<EOF
+{
+  "name": "test-orchestration",
+  "desired_state": "active",
+  "objects": [{"label": "web", "type": "SecList", "template": {"name": "web"}, "relationships": [{"type": "depends", "targets": ["db"]}]}]
+}
+EOF
+}
+`,
+				ExpectError: regexp.MustCompile(`depends on "db", which is not another object of the orchestration`),
+			},
+		},
+	})
+}
+
+func TestExpandOrchestrationDocument(t *testing.T) {
+	cases := []struct {
+		document string
+		expected string
+	}{
+		{`{"name": "test", "desired_state": "active", "objects": [{"label": "web", "type": "SecList", "template": {}}]}`, ""},
+		{`{"name": "test", "desired_state": "active", "objects": {}}`, "Error parsing orchestration document"},
+		{`{"desired_state": "active", "objects": [{"label": "web", "type": "SecList", "template": {}}]}`, "must have a name"},
+		{`{"name": "test", "desired_state": "running", "objects": [{"label": "web", "type": "SecList", "template": {}}]}`, "must be active, inactive or suspend"},
+		{`{"name": "test", "desired_state": "active", "objects": []}`, "between 1 and 100 objects"},
+		{`{"name": "test", "desired_state": "active", "objects": [{"type": "SecList", "template": {}}]}`, "must have a label"},
+		{`{"name": "test", "desired_state": "active", "objects": [{"label": "web", "type": "SecList", "template": {}}, {"label": "web", "type": "SecList", "template": {}}]}`, "labels must be unique"},
+		{`{"name": "test", "desired_state": "active", "objects": [{"label": "web", "template": {}}]}`, "must have a type"},
+		{`{"name": "test", "desired_state": "active", "objects": [{"label": "web", "type": "SecList"}]}`, "must have a template"},
+		{`{"name": "test", "desired_state": "active", "objects": [{"label": "web", "type": "SecList", "template": {}, "relationships": [{"type": "needs", "targets": []}]}]}`, "unsupported relationship type"},
+		{`{"name": "test", "desired_state": "active", "objects": [{"label": "web", "type": "SecList", "template": {}, "relationships": [{"type": "depends", "targets": ["web"]}]}]}`, `depends on "web"`},
+	}
+
+	for _, c := range cases {
+		_, err := expandOrchestrationDocument(c.document)
+		if c.expected == "" {
+			if err != nil {
+				t.Fatalf("Expected %s to be valid, got %s", c.document, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected an error containing %q for %s, got %v", c.expected, c.document, err)
+		}
+	}
+}
+
+func TestOrchestrationDocumentContains(t *testing.T) {
+	server := `{
+  "name": "/Compute-acme/jdoe/test",
+  "desired_state": "active",
+  "account": "/Compute-acme/default",
+  "objects": [
+    {"label": "web", "type": "SecList", "persistent": false, "template": {"name": "/Compute-acme/jdoe/web", "policy": "DENY"}},
+    {"label": "ssh", "type": "SecRule", "template": {"name": "/Compute-acme/jdoe/ssh", "src_list": "seclist:/Compute-acme/jdoe/web", "size": 10}}
+  ]
+}`
+
+	cases := []struct {
+		document string
+		expected bool
+	}{
+		// Qualified names, objects in another order and server defaults
+		{`{"name": "test", "desired_state": "active", "version": 3, "objects": [
+			{"label": "ssh", "type": "SecRule", "template": {"name": "ssh", "src_list": "seclist:web", "size": "10"}},
+			{"label": "web", "type": "SecList", "template": {"name": "web", "policy": "DENY"}}]}`, true},
+		{`{"name": "test", "desired_state": "suspend", "objects": [
+			{"label": "ssh", "type": "SecRule", "template": {"name": "ssh"}},
+			{"label": "web", "type": "SecList", "template": {"name": "web"}}]}`, false},
+		{`{"name": "test", "desired_state": "active", "objects": [
+			{"label": "web", "type": "SecList", "template": {"name": "web"}}]}`, false},
+		{`{"name": "test", "desired_state": "active", "objects": [
+			{"label": "ssh", "type": "SecRule", "template": {"name": "ssh", "src_list": "seclist:db"}},
+			{"label": "web", "type": "SecList", "template": {"name": "web"}}]}`, false},
+		{`{"name": "other", "desired_state": "active"}`, false},
+	}
+
+	got, err := parseOrchestrationDocument(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		want, err := parseOrchestrationDocument(c.document)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if orchestrationDocumentContains(got, want) != c.expected {
+			t.Fatalf("Expected the server document to contain %s: %t", c.document, c.expected)
+		}
+	}
+}
+
+func TestEqualOrchestrationNames(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"web", "web", true},
+		{"/Compute-acme/jdoe/web", "web", true},
+		{"web", "/Compute-acme/jdoe/web", true},
+		{"/Compute-acme/jdoe/web", "/Compute-acme/admin/web", false},
+		{"seclist:/Compute-acme/jdoe/web", "seclist:web", true},
+		{"seciplist:/Compute-acme/jdoe/web", "seclist:web", false},
+		{"/oracle/public/ssh", "ssh", false},
+		{"/Compute-acme/jdoe/web", "db", false},
+	}
+
+	for _, c := range cases {
+		if equalOrchestrationNames(c.a, c.b) != c.expected {
+			t.Fatalf("Expected %q and %q to be equal: %t", c.a, c.b, c.expected)
+		}
+	}
+}
+
+func testAccEditOrchestrationDocument(t *testing.T, name, description string) func() {
+	return func() {
+		client := testAccProvider.Meta().(*Client).computeCollectionClient
+		document, err := getOrchestrationDocument(client, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		document["description"] = description
+		if err := client.do("PUT", orchestrationRootPath+client.qualify(name), document, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testAccCheckOrchestrationDescription(name, description string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client).computeCollectionClient
+		document, err := getOrchestrationDocument(client, name)
+		if err != nil {
+			return err
+		}
+		if document["description"] != description {
+			return fmt.Errorf("Expected the description of Orchestration %s to be %q, got %q", name, description, document["description"])
+		}
+		return nil
+	}
+}
+
+func testAccCheckOrchestrationDocumentDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client).computeCollectionClient
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "opc_compute_orchestration_document" {
+			continue
+		}
+
+		if info, err := getOrchestrationDocument(client, rs.Primary.ID); err == nil {
+			return fmt.Errorf("Orchestration %s still exists: %#v", rs.Primary.ID, info)
+		}
+	}
+
+	return nil
+}
+
+func testAccOrchestrationDocument(rInt int, desiredState string) string {
+	user := fmt.Sprintf("/Compute-%s/%s", os.Getenv("OPC_IDENTITY_DOMAIN"), os.Getenv("OPC_USERNAME"))
+	return fmt.Sprintf(`
+resource "opc_compute_orchestration_document" "test" {
+  document = <<EOF
+{
+  "name": "%[1]s/test-orchestration-%[2]d",
+  "description": "Managed by Terraform",
+  "desired_state": "%[3]s",
+  "objects": [
+    {
+      "label": "test-list-%[2]d",
+      "type": "SecList",
+      "orchestration": "%[1]s/test-orchestration-%[2]d",
+      "persistent": true,
+      "template": {
+        "name": "%[1]s/test-list-%[2]d",
+        "policy": "DENY",
+        "outbound_cidr_policy": "PERMIT"
+      }
+    },
+    {
+      "label": "test-instance-%[2]d",
+      "type": "Instance",
+      "orchestration": "%[1]s/test-orchestration-%[2]d",
+      "relationships": [{"type": "depends", "targets": ["test-list-%[2]d"]}],
+      "template": {
+        "name": "%[1]s/test-instance-%[2]d",
+        "shape": "oc3",
+        "imagelist": "/oracle/public/OL_7.2_UEKR4_x86_64"
+      }
+    }
+  ]
+}
+EOF
+}
+`, user, rInt, desiredState)
+}
@@ -0,0 +1,275 @@
+package opc
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/acctest"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccOPCOrchestration_Basic(t *testing.T) {
+	resName := "opc_compute_orchestration.test"
+	ri := acctest.RandInt()
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckOPCOrchestrationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOrchestrationObjects(ri, "active"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationExists,
+					testAccCheckOrchestrationObjects(fmt.Sprintf("test-orchestration-%d", ri), map[string]compute.OrchestrationType{
+						fmt.Sprintf("test-instance-%d", ri): compute.OrchestrationTypeInstance,
+						fmt.Sprintf("test-volume-%d", ri):   orchestrationTypeStorageVolume,
+						fmt.Sprintf("test-network-%d", ri):  orchestrationTypeIPNetwork,
+						fmt.Sprintf("test-ip-%d", ri):       orchestrationTypeIPReservation,
+						fmt.Sprintf("test-list-%d", ri):     orchestrationTypeSecurityList,
+						fmt.Sprintf("test-rule-%d", ri):     orchestrationTypeSecRule,
+					}),
+					resource.TestCheckResourceAttrSet(resName, "instance.0.id"),
+					resource.TestCheckResourceAttr(resName, "instance.0.depends.#", "2"),
+					resource.TestCheckResourceAttr(resName, "storage_volume.0.size", "10"),
+					resource.TestCheckResourceAttr(resName, "storage_volume.0.persistent", "true"),
+					resource.TestCheckResourceAttr(resName, "ip_network.0.ip_address_prefix", "192.168.10.0/24"),
+					resource.TestCheckResourceAttr(resName, "ip_reservation.0.parent_pool", "/oracle/public/ippool"),
+					resource.TestCheckResourceAttr(resName, "security_list.0.policy", "DENY"),
+					resource.TestCheckResourceAttr(resName, "sec_rule.0.source_list", fmt.Sprintf("seclist:test-list-%d", ri)),
+					resource.TestCheckResourceAttr(resName, "sec_rule.0.depends.#", "1"),
+				),
+			},
+			{
+				Config: testAccOrchestrationObjects(ri, "suspend"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestrationExists,
+					resource.TestCheckResourceAttr(resName, "desired_state", "suspend"),
+					resource.TestCheckResourceAttr(resName, "storage_volume.0.name", fmt.Sprintf("test-volume-%d", ri)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCOrchestration_updateObjects(t *testing.T) {
+	resName := "opc_compute_orchestration.test"
+	ri := acctest.RandInt()
+	var instanceID string
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckOPCOrchestrationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOrchestrationUpdateObjects(ri, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestratedInstanceID(resName, "instance.0.id", &instanceID, false),
+					resource.TestCheckResourceAttr(resName, "version", "1"),
+				),
+			},
+			{
+				// The orchestration and its instance are updated in place
+				Config: testAccOrchestrationUpdateObjects(ri, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckOrchestratedInstanceID(resName, "instance.0.id", &instanceID, false),
+					testAccCheckOrchestrationObjects(fmt.Sprintf("test-orchestration-%d", ri), map[string]compute.OrchestrationType{
+						fmt.Sprintf("test-instance-%d", ri): compute.OrchestrationTypeInstance,
+						fmt.Sprintf("test-volume-%d", ri):   orchestrationTypeStorageVolume,
+						fmt.Sprintf("test-list-%d", ri):     orchestrationTypeSecurityList,
+					}),
+					resource.TestCheckResourceAttr(resName, "version", "2"),
+					resource.TestCheckResourceAttr(resName, "instance.0.depends.#", "1"),
+					resource.TestCheckResourceAttr(resName, "storage_volume.0.size", "20"),
+					resource.TestCheckResourceAttr(resName, "storage_volume.0.persistent", "true"),
+					resource.TestCheckResourceAttr(resName, "security_list.0.description", "updated"),
+					resource.TestCheckResourceAttr(resName, "ip_reservation.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCOrchestration_unknownDependency(t *testing.T) {
+	ri := acctest.RandInt()
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckOPCOrchestrationDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccOrchestrationUnknownDependency(ri),
+				ExpectError: regexp.MustCompile(`depends on "missing", which is not an object of the orchestration`),
+			},
+		},
+	})
+}
+
+func TestOrchestrationObjectChanges(t *testing.T) {
+	volume := func(name string, size int, depends ...interface{}) interface{} {
+		return map[string]interface{}{
+			"name":       name,
+			"persistent": false,
+			"size":       size,
+			"depends":    schema.NewSet(schema.HashString, depends),
+		}
+	}
+	o := []interface{}{
+		volume("data", 10),
+		volume("logs", 10, "data"),
+		volume("backup", 10),
+	}
+	n := []interface{}{
+		volume("data", 20),
+		volume("logs", 10),
+		volume("backup", 10),
+		volume("scratch", 10),
+	}
+
+	expected := map[string]string{
+		"data":    orchestrationObjectReplace,
+		"logs":    orchestrationObjectUpdate,
+		"scratch": orchestrationObjectCreate,
+	}
+	attributes := resourceOPCOrchestration().Schema["storage_volume"].Elem.(*schema.Resource).Schema
+	if changes := orchestrationObjectChanges(attributes, o, n); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected changes %#v, got %#v", expected, changes)
+	}
+}
+
+func testAccCheckOrchestrationObjects(name string, expected map[string]compute.OrchestrationType) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client).computeClient.Orchestrations()
+
+		info, err := client.GetOrchestration(&compute.GetOrchestrationInput{Name: name})
+		if err != nil {
+			return fmt.Errorf("Error retrieving state of Orchestration %s: %s", name, err)
+		}
+		if len(info.Objects) != len(expected) {
+			return fmt.Errorf("Expected %d objects in Orchestration %s, got %d", len(expected), name, len(info.Objects))
+		}
+		for _, object := range info.Objects {
+			if expected[object.Label] != object.Type {
+				return fmt.Errorf("Expected object %s to be a %q, got %q", object.Label, expected[object.Label], object.Type)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccCheckOPCOrchestrationDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client).computeClient.Orchestrations()
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "opc_compute_orchestration" {
+			continue
+		}
+
+		input := compute.GetOrchestrationInput{
+			Name: rs.Primary.Attributes["name"],
+		}
+		if info, err := client.GetOrchestration(&input); err == nil {
+			return fmt.Errorf("Orchestration %s still exists: %#v", input.Name, info)
+		}
+	}
+
+	return nil
+}
+
+func testAccOrchestrationObjects(rInt int, desiredState string) string {
+	return fmt.Sprintf(`
+resource "opc_compute_orchestration" "test" {
+  name          = "test-orchestration-%d"
+  desired_state = "%s"
+
+  instance {
+    name       = "test-instance-%d"
+    shape      = "oc3"
+    image_list = "/oracle/public/OL_7.2_UEKR4_x86_64"
+    depends    = ["test-volume-%d", "test-network-%d"]
+  }
+
+  storage_volume {
+    name       = "test-volume-%d"
+    size       = 10
+    persistent = true
+  }
+
+  ip_network {
+    name              = "test-network-%d"
+    ip_address_prefix = "192.168.10.0/24"
+  }
+
+  ip_reservation {
+    name      = "test-ip-%d"
+    permanent = true
+  }
+
+  security_list {
+    name = "test-list-%d"
+  }
+
+  sec_rule {
+    name             = "test-rule-%d"
+    source_list      = "seclist:test-list-%d"
+    destination_list = "seciplist:/oracle/public/public-internet"
+    application      = "/oracle/public/ssh"
+    depends          = ["test-list-%d"]
+  }
+}
+`, rInt, desiredState, rInt, rInt, rInt, rInt, rInt, rInt, rInt, rInt, rInt, rInt)
+}
+
+func testAccOrchestrationUnknownDependency(rInt int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_orchestration" "test" {
+  name          = "test-orchestration-%d"
+  desired_state = "active"
+
+  security_list {
+    name    = "test-list-%d"
+    depends = ["missing"]
+  }
+}
+`, rInt, rInt)
+}
+
+func testAccOrchestrationUpdateObjects(rInt int, updated bool) string {
+	depends, size, description, reservation := fmt.Sprintf(`"test-volume-%d", "test-ip-%d"`, rInt, rInt), 10, "", fmt.Sprintf(`
+  ip_reservation {
+    name      = "test-ip-%d"
+    permanent = true
+  }
+`, rInt)
+	if updated {
+		depends, size, description, reservation = fmt.Sprintf(`"test-volume-%d"`, rInt), 20, "updated", ""
+	}
+	return fmt.Sprintf(`
+resource "opc_compute_orchestration" "test" {
+  name          = "test-orchestration-%d"
+  desired_state = "active"
+
+  instance {
+    name       = "test-instance-%d"
+    shape      = "oc3"
+    image_list = "/oracle/public/OL_7.2_UEKR4_x86_64"
+    depends    = [%s]
+  }
+
+  storage_volume {
+    name       = "test-volume-%d"
+    size       = %d
+    persistent = %t
+  }
+
+  security_list {
+    name        = "test-list-%d"
+    description = "%s"
+  }
+%s}
+`, rInt, rInt, depends, rInt, size, updated, rInt, description, reservation)
+}
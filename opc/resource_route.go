@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -18,6 +17,8 @@ func resourceOPCRoute() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -46,7 +47,9 @@ func resourceOPCRoute() *schema.Resource {
 				Required: true,
 			},
 
-			"tags": tagsOptionalSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 		},
 	}
 }
@@ -81,7 +84,7 @@ func resourceOPCRouteCreate(d *schema.ResourceData, meta interface{}) error {
 		input.AdminDistance = dist.(int)
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -89,7 +92,7 @@ func resourceOPCRouteCreate(d *schema.ResourceData, meta interface{}) error {
 	// Create Route
 	info, err := resClient.CreateRoute(input)
 	if err != nil {
-		return fmt.Errorf("Error creating route '%s': %v", name, err)
+		return fmt.Errorf("Error creating route '%s': %v", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -111,11 +114,11 @@ func resourceOPCRouteRead(d *schema.ResourceData, meta interface{}) error {
 
 	result, err := resClient.GetRoute(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading route '%s': %v", name, err)
+		return fmt.Errorf("Error reading route '%s': %v", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -128,7 +131,7 @@ func resourceOPCRouteRead(d *schema.ResourceData, meta interface{}) error {
 	d.Set("ip_address_prefix", result.IPAddressPrefix)
 	d.Set("next_hop_vnic_set", result.NextHopVnicSet)
 	d.Set("description", result.Description)
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -164,7 +167,7 @@ func resourceOPCRouteUpdate(d *schema.ResourceData, meta interface{}) error {
 		input.AdminDistance = dist.(int)
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -172,7 +175,7 @@ func resourceOPCRouteUpdate(d *schema.ResourceData, meta interface{}) error {
 	// Create Route
 	info, err := resClient.UpdateRoute(input)
 	if err != nil {
-		return fmt.Errorf("Error creating route '%s': %v", name, err)
+		return fmt.Errorf("Error creating route '%s': %v", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -192,7 +195,7 @@ func resourceOPCRouteDelete(d *schema.ResourceData, meta interface{}) error {
 		Name: name,
 	}
 	if err := resClient.DeleteRoute(input); err != nil {
-		return fmt.Errorf("Error deleting route '%s': %v", name, err)
+		return fmt.Errorf("Error deleting route '%s': %v", name, newAPIError(err))
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -85,7 +84,7 @@ func resourceOPCSecRuleCreate(d *schema.ResourceData, meta interface{}) error {
 
 	info, err := resClient.CreateSecRule(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating sec rule %s: %s", name, err)
+		return fmt.Errorf("Error creating sec rule %s: %s", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -109,11 +108,11 @@ func resourceOPCSecRuleRead(d *schema.ResourceData, meta interface{}) error {
 	result, err := resClient.GetSecRule(&input)
 	if err != nil {
 		// Sec Rule does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading sec list %s: %s", name, err)
+		return fmt.Errorf("Error reading sec list %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -161,7 +160,7 @@ func resourceOPCSecRuleUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	_, err = resClient.UpdateSecRule(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating sec rule %s: %s", name, err)
+		return fmt.Errorf("Error updating sec rule %s: %s", name, newAPIError(err))
 	}
 
 	return resourceOPCSecRuleRead(d, meta)
@@ -179,7 +178,7 @@ func resourceOPCSecRuleDelete(d *schema.ResourceData, meta interface{}) error {
 		Name: name,
 	}
 	if err := resClient.DeleteSecRule(&input); err != nil {
-		return fmt.Errorf("Error deleting sec rule %s: %s", name, err)
+		return fmt.Errorf("Error deleting sec rule %s: %s", name, newAPIError(err))
 	}
 
 	return nil
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -98,7 +97,7 @@ func resourceOPCSecurityApplicationCreate(d *schema.ResourceData, meta interface
 	}
 	info, err := resClient.CreateSecurityApplication(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating security application %s: %s", name, err)
+		return fmt.Errorf("Error creating security application %s: %s", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -120,11 +119,11 @@ func resourceOPCSecurityApplicationRead(d *schema.ResourceData, meta interface{}
 
 	result, err := resClient.GetSecurityApplication(&input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading security application %s: %s", name, err)
+		return fmt.Errorf("Error reading security application %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -154,7 +153,7 @@ func resourceOPCSecurityApplicationDelete(d *schema.ResourceData, meta interface
 		Name: name,
 	}
 	if err := resClient.DeleteSecurityApplication(&input); err != nil {
-		return fmt.Errorf("Error deleting security application '%s': %s", name, err)
+		return fmt.Errorf("Error deleting security application '%s': %s", name, newAPIError(err))
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -58,7 +57,7 @@ func resourceOPCSecurityAssociationCreate(d *schema.ResourceData, meta interface
 	}
 	info, err := resClient.CreateSecurityAssociation(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating security association between vcable %s and security list %s: %s", vcable, seclist, err)
+		return fmt.Errorf("Error creating security association between vcable %s and security list %s: %s", vcable, seclist, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -82,11 +81,11 @@ func resourceOPCSecurityAssociationRead(d *schema.ResourceData, meta interface{}
 	result, err := resClient.GetSecurityAssociation(&input)
 	if err != nil {
 		// Security Association does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading security association %s: %s", name, err)
+		return fmt.Errorf("Error reading security association %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -114,7 +113,7 @@ func resourceOPCSecurityAssociationDelete(d *schema.ResourceData, meta interface
 		Name: name,
 	}
 	if err := resClient.DeleteSecurityAssociation(&input); err != nil {
-		return fmt.Errorf("Error deleting Security Association '%s': %v", name, err)
+		return fmt.Errorf("Error deleting Security Association '%s': %v", name, newAPIError(err))
 	}
 	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -63,7 +62,7 @@ func resourceOPCSecurityIPListCreate(d *schema.ResourceData, meta interface{}) e
 	log.Printf("[DEBUG] Creating security IP list with %+v", input)
 	info, err := resClient.CreateSecurityIPList(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating security IP list %s: %s", input.Name, err)
+		return fmt.Errorf("Error creating security IP list %s: %s", input.Name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -87,11 +86,11 @@ func resourceOPCSecurityIPListRead(d *schema.ResourceData, meta interface{}) err
 	result, err := resClient.GetSecurityIPList(&input)
 	if err != nil {
 		// Security IP List does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading security IP list %s: %s", name, err)
+		return fmt.Errorf("Error reading security IP list %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -131,7 +130,7 @@ func resourceOPCSecurityIPListUpdate(d *schema.ResourceData, meta interface{}) e
 	log.Printf("[DEBUG] Updating security IP list with %+v", input)
 	info, err := resClient.UpdateSecurityIPList(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating security IP list %s: %s", input.Name, err)
+		return fmt.Errorf("Error updating security IP list %s: %s", input.Name, newAPIError(err))
 	}
 	d.SetId(info.Name)
 
@@ -152,7 +151,7 @@ func resourceOPCSecurityIPListDelete(d *schema.ResourceData, meta interface{}) e
 		Name: name,
 	}
 	if err := resClient.DeleteSecurityIPList(&input); err != nil {
-		return fmt.Errorf("Error deleting security IP list %s: %s", name, err)
+		return fmt.Errorf("Error deleting security IP list %s: %s", name, newAPIError(err))
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
@@ -78,7 +77,7 @@ func resourceOPCSecurityListCreate(d *schema.ResourceData, meta interface{}) err
 	}
 	info, err := resClient.CreateSecurityList(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating security list %s: %s", name, err)
+		return fmt.Errorf("Error creating security list %s: %s", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -106,7 +105,7 @@ func resourceOPCSecurityListUpdate(d *schema.ResourceData, meta interface{}) err
 	}
 	_, err = resClient.UpdateSecurityList(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating security list %s: %s", name, err)
+		return fmt.Errorf("Error updating security list %s: %s", name, newAPIError(err))
 	}
 
 	return resourceOPCSecurityListRead(d, meta)
@@ -128,11 +127,11 @@ func resourceOPCSecurityListRead(d *schema.ResourceData, meta interface{}) error
 	result, err := resClient.GetSecurityList(&input)
 	if err != nil {
 		// Security List does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading security list %s: %s", name, err)
+		return fmt.Errorf("Error reading security list %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -160,7 +159,7 @@ func resourceOPCSecurityListDelete(d *schema.ResourceData, meta interface{}) err
 		Name: name,
 	}
 	if err := resClient.DeleteSecurityList(&input); err != nil {
-		return fmt.Errorf("Error deleting security list %s: %s", name, err)
+		return fmt.Errorf("Error deleting security list %s: %s", name, newAPIError(err))
 	}
 
 	return nil
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -18,6 +17,8 @@ func resourceOPCSecurityProtocol() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsForceNew,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -45,7 +46,9 @@ func resourceOPCSecurityProtocol() *schema.Resource {
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
-			"tags": tagsForceNewSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"uri": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -73,7 +76,7 @@ func resourceOPCSecurityProtocolCreate(d *schema.ResourceData, meta interface{})
 	if len(srcPorts) != 0 {
 		input.SrcPortSet = srcPorts
 	}
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -84,7 +87,7 @@ func resourceOPCSecurityProtocolCreate(d *schema.ResourceData, meta interface{})
 
 	info, err := resClient.CreateSecurityProtocol(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating Security Protocol: %s", err)
+		return fmt.Errorf("Error creating Security Protocol: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -105,11 +108,11 @@ func resourceOPCSecurityProtocolRead(d *schema.ResourceData, meta interface{}) e
 	result, err := resClient.GetSecurityProtocol(&input)
 	if err != nil {
 		// Security Protocol does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading security protocol %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading security protocol %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -126,7 +129,7 @@ func resourceOPCSecurityProtocolRead(d *schema.ResourceData, meta interface{}) e
 	if err := setStringList(d, "src_ports", result.SrcPortSet); err != nil {
 		return err
 	}
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -151,7 +154,7 @@ func resourceOPCSecurityProtocolUpdate(d *schema.ResourceData, meta interface{})
 	if len(srcPorts) != 0 {
 		input.SrcPortSet = srcPorts
 	}
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -161,7 +164,7 @@ func resourceOPCSecurityProtocolUpdate(d *schema.ResourceData, meta interface{})
 
 	info, err := resClient.UpdateSecurityProtocol(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating Security Protocol: %s", err)
+		return fmt.Errorf("Error updating Security Protocol: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -180,7 +183,7 @@ func resourceOPCSecurityProtocolDelete(d *schema.ResourceData, meta interface{})
 		Name: name,
 	}
 	if err := resClient.DeleteSecurityProtocol(&input); err != nil {
-		return fmt.Errorf("Error deleting Security Protocol: %s", err)
+		return fmt.Errorf("Error deleting Security Protocol: %s", newAPIError(err))
 	}
 	return nil
 }
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -18,6 +17,8 @@ func resourceOPCSecurityRule() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -64,7 +65,9 @@ func resourceOPCSecurityRule() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
-			"tags": tagsOptionalSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"uri": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -113,7 +116,7 @@ func resourceOPCSecurityRuleCreate(d *schema.ResourceData, meta interface{}) err
 		input.DstIPAddressPrefixSets = dstIPAddressPrefixes
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -124,7 +127,7 @@ func resourceOPCSecurityRuleCreate(d *schema.ResourceData, meta interface{}) err
 
 	info, err := resClient.CreateSecurityRule(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating Security Rule: %s", err)
+		return fmt.Errorf("Error creating Security Rule: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -145,11 +148,11 @@ func resourceOPCSecurityRuleRead(d *schema.ResourceData, meta interface{}) error
 	result, err := resClient.GetSecurityRule(&input)
 	if err != nil {
 		// SecurityRule does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading security rule %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading security rule %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -175,7 +178,7 @@ func resourceOPCSecurityRuleRead(d *schema.ResourceData, meta interface{}) error
 	if err := setStringList(d, "src_ip_address_prefixes", result.SrcIPAddressPrefixSets); err != nil {
 		return err
 	}
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -221,7 +224,7 @@ func resourceOPCSecurityRuleUpdate(d *schema.ResourceData, meta interface{}) err
 		input.DstIPAddressPrefixSets = dstIPAddressPrefixes
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -231,7 +234,7 @@ func resourceOPCSecurityRuleUpdate(d *schema.ResourceData, meta interface{}) err
 	}
 	info, err := resClient.UpdateSecurityRule(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating Security Rule: %s", err)
+		return fmt.Errorf("Error updating Security Rule: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -250,7 +253,7 @@ func resourceOPCSecurityRuleDelete(d *schema.ResourceData, meta interface{}) err
 		Name: name,
 	}
 	if err := resClient.DeleteSecurityRule(&input); err != nil {
-		return fmt.Errorf("Error deleting Security Rule: %s", err)
+		return fmt.Errorf("Error deleting Security Rule: %s", newAPIError(err))
 	}
 	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -80,7 +79,7 @@ func resourceOPCSnapshotCreate(d *schema.ResourceData, meta interface{}) error {
 
 	info, err := resClient.CreateSnapshot(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating snapshot %s: %s", instance, err)
+		return fmt.Errorf("Error creating snapshot %s: %s", instance, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -103,11 +102,11 @@ func resourceOPCSnapshotRead(d *schema.ResourceData, meta interface{}) error {
 	result, err := resClient.GetSnapshot(&input)
 	if err != nil {
 		// Sec Rule does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading snapshot %s: %s", name, err)
+		return fmt.Errorf("Error reading snapshot %s: %s", name, newAPIError(err))
 	}
 
 	d.Set("name", result.Name)
@@ -135,11 +134,11 @@ func resourceOPCSnapshotDelete(d *schema.ResourceData, meta interface{}) error {
 	result, err := snapshotClient.GetSnapshot(&getInput)
 	if err != nil {
 		// Snapshot does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading snapshot %s: %s", name, err)
+		return fmt.Errorf("Error reading snapshot %s: %s", name, newAPIError(err))
 	}
 
 	input := compute.DeleteSnapshotInput{
@@ -148,7 +147,7 @@ func resourceOPCSnapshotDelete(d *schema.ResourceData, meta interface{}) error {
 		Timeout:      d.Timeout(schema.TimeoutDelete),
 	}
 	if err := snapshotClient.DeleteSnapshot(machineImageClient, &input); err != nil {
-		return fmt.Errorf("Error deleting snapshot %s: %s", name, err)
+		return fmt.Errorf("Error deleting snapshot %s: %s", name, newAPIError(err))
 	}
 
 	return nil
@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -58,7 +57,7 @@ func resourceOPCSSHKeyCreate(d *schema.ResourceData, meta interface{}) error {
 
 	info, err := resClient.CreateSSHKey(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating ssh key %s: %s", name, err)
+		return fmt.Errorf("Error creating ssh key %s: %s", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -85,7 +84,7 @@ func resourceOPCSSHKeyUpdate(d *schema.ResourceData, meta interface{}) error {
 
 	_, err = resClient.UpdateSSHKey(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating ssh key %s: %s", name, err)
+		return fmt.Errorf("Error updating ssh key %s: %s", name, newAPIError(err))
 	}
 
 	return resourceOPCSSHKeyRead(d, meta)
@@ -105,11 +104,11 @@ func resourceOPCSSHKeyRead(d *schema.ResourceData, meta interface{}) error {
 
 	result, err := resClient.GetSSHKey(&input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading ssh key %s: %s", name, err)
+		return fmt.Errorf("Error reading ssh key %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -136,7 +135,7 @@ func resourceOPCSSHKeyDelete(d *schema.ResourceData, meta interface{}) error {
 		Name: name,
 	}
 	if err := resClient.DeleteSSHKey(&input); err != nil {
-		return fmt.Errorf("Error deleting ssh key %s: %s", name, err)
+		return fmt.Errorf("Error deleting ssh key %s: %s", name, newAPIError(err))
 	}
 
 	return nil
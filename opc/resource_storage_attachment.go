@@ -6,8 +6,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 )
@@ -43,6 +43,10 @@ func resourceOPCStorageAttachment() *schema.Resource {
 				Required: true,
 				ForceNew: true,
 			},
+			"storage_volume_size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -63,10 +67,10 @@ func resourceOPCStorageAttachmentCreate(d *schema.ResourceData, meta interface{}
 	}
 	storageVolume, err := resClient.GetStorageVolume(&getVolumeInput)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			return fmt.Errorf("Unable to find storage volume: %s", volumeName)
 		}
-		return fmt.Errorf("Error reading storage volume %s: %s", volumeName, err)
+		return fmt.Errorf("Error reading storage volume %s: %s", volumeName, newAPIError(err))
 	}
 	if storageVolume == nil {
 		// Volume doesn't exist
@@ -91,20 +95,20 @@ func resourceOPCStorageAttachmentCreate(d *schema.ResourceData, meta interface{}
 		return fmt.Errorf("Storage index %d is already in use on instance %s", volumeIndex, instanceName)
 	}
 
-	storageAttachmentClient := meta.(*Client).computeClient.StorageAttachments()
 	input := compute.CreateStorageAttachmentInput{
 		StorageVolumeName: storageVolume.Name,
 		InstanceName:      fmt.Sprintf("%s/%s", instance.Name, instance.ID),
 		Index:             volumeIndex,
-		Timeout:           d.Timeout(schema.TimeoutCreate),
 	}
 
-	info, err := storageAttachmentClient.CreateStorageAttachment(&input)
+	name, err := attachStorageVolume(meta, &input, d.Timeout(schema.TimeoutCreate))
+	if name != "" {
+		d.SetId(name)
+	}
 	if err != nil {
 		return fmt.Errorf("Error creating StorageAttachment: %s", err)
 	}
 
-	d.SetId(info.Name)
 	return resourceOPCStorageAttachmentRead(d, meta)
 }
 
@@ -135,11 +139,22 @@ func resourceOPCStorageAttachmentRead(d *schema.ResourceData, meta interface{})
 	result, err := resClient.GetStorageAttachment(&getInput)
 	if err != nil {
 		// StorageAttachment does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
+			// A reshaped instance is relaunched with new attachments of the
+			// same volumes at the same indexes
+			name, err := findStorageAttachment(meta.(*Client), d.Get("storage_volume").(string), d.Get("instance").(string), d.Get("index").(int))
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				log.Printf("[DEBUG] Storage attachment %s was replaced by %s", d.Id(), name)
+				d.SetId(name)
+				return resourceOPCStorageAttachmentRead(d, meta)
+			}
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading storage_attachment %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading storage_attachment %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -151,26 +166,159 @@ func resourceOPCStorageAttachmentRead(d *schema.ResourceData, meta interface{})
 	d.Set("index", result.Index)
 	d.Set("instance", strings.Split(result.InstanceName, "/")[0])
 	d.Set("storage_volume", result.StorageVolumeName)
+
+	size, err := getStorageVolumeSize(computeClient.StorageVolumes(), result.StorageVolumeName)
+	if err != nil {
+		return fmt.Errorf("Error reading size of storage volume %s: %s", result.StorageVolumeName, newAPIError(err))
+	}
+	d.Set("storage_volume_size", size)
 	return nil
 }
 
-func resourceOPCStorageAttachmentDelete(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[DEBUG] Resource state: %#v", d.State())
-	computeClient, err := meta.(*Client).getComputeClient()
+// getStorageVolumeAttachments returns the storage attachments of the named
+// storage volume.
+func getStorageVolumeAttachments(meta *Client, volumeName string) ([]compute.StorageAttachmentInfo, error) {
+	collectionClient, err := meta.getComputeCollectionClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []compute.StorageAttachmentInfo
+	if err := collectionClient.list("/storage/attachment", &attachments); err != nil {
+		return nil, fmt.Errorf("Error listing storage attachments of storage volume %s: %s", volumeName, newAPIError(err))
+	}
+
+	result := make([]compute.StorageAttachmentInfo, 0, len(attachments))
+	for _, attachment := range attachments {
+		if collectionClient.unqualify(attachment.StorageVolumeName) != collectionClient.unqualify(volumeName) {
+			continue
+		}
+		attachment.Name = collectionClient.unqualify(attachment.FQDN)
+		result = append(result, attachment)
+	}
+	return result, nil
+}
+
+// findStorageAttachment returns the name of the attachment of a storage
+// volume to the named instance at the given index, or "" if there is none.
+func findStorageAttachment(meta *Client, volumeName, instanceName string, index int) (string, error) {
+	if volumeName == "" || instanceName == "" {
+		return "", nil
+	}
+	attachments, err := getStorageVolumeAttachments(meta, volumeName)
+	if err != nil {
+		return "", err
+	}
+	collectionClient, err := meta.getComputeCollectionClient()
+	if err != nil {
+		return "", err
+	}
+	for _, attachment := range attachments {
+		instance := strings.Split(collectionClient.unqualify(attachment.InstanceName), "/")[0]
+		if instance == instanceName && attachment.Index == index {
+			return attachment.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// attachStorageVolume attaches a storage volume to an instance, and waits for
+// it to be attached. The name of the attachment is returned once it has been
+// created, even when the wait fails.
+func attachStorageVolume(meta interface{}, input *compute.CreateStorageAttachmentInput, timeout time.Duration) (string, error) {
+	client, err := meta.(*Client).getComputeCollectionClient()
+	if err != nil {
+		return "", err
+	}
+
+	// The attachment is created by the provider, as the StorageAttachments
+	// client waits for it at a fixed interval which can't be cancelled
+	body := *input
+	body.InstanceName = client.qualify(input.InstanceName)
+	body.StorageVolumeName = client.qualify(input.StorageVolumeName)
+	var result struct {
+		Name string `json:"name"`
+	}
+	if err := client.do("POST", "/storage/attachment/", body, &result); err != nil {
+		return "", newAPIError(err)
+	}
+	name := client.unqualify(result.Name)
+
+	conf := waitConf{
+		Description: fmt.Sprintf("storage volume %s to be attached to instance %s", input.StorageVolumeName, input.InstanceName),
+		Pending:     []string{string(compute.Attaching), string(compute.Unknown)},
+		Target:      []string{string(compute.Attached)},
+		Refresh:     storageAttachmentState(meta.(*Client).computeClient.StorageAttachments(), name),
+		Timeout:     timeout,
+	}
+	if _, err := waitForState(meta.(*Client).stopContext, conf); err != nil {
+		return name, newAPIError(err)
+	}
+	return name, nil
+}
+
+// detachStorageVolume deletes a storage attachment, and waits for it to be
+// gone.
+func detachStorageVolume(meta interface{}, name string, timeout time.Duration) error {
+	client, err := meta.(*Client).getComputeCollectionClient()
 	if err != nil {
 		return err
 	}
-	resClient := computeClient.StorageAttachments()
+
+	if err := client.do("DELETE", "/storage/attachment"+client.qualify(name), nil, nil); err != nil {
+		return newAPIError(err)
+	}
+
+	conf := waitConf{
+		Description: fmt.Sprintf("storage attachment %s to be detached", name),
+		Pending: []string{
+			string(compute.Attaching),
+			string(compute.Attached),
+			string(compute.Detaching),
+			string(compute.Unknown),
+		},
+		Target:  []string{waitStateDeleted},
+		Refresh: storageAttachmentState(meta.(*Client).computeClient.StorageAttachments(), name),
+		Timeout: timeout,
+	}
+	if _, err := waitForState(meta.(*Client).stopContext, conf); err != nil {
+		return newAPIError(err)
+	}
+	return nil
+}
+
+// storageAttachmentState reports the state of a storage attachment, or
+// waitStateDeleted once it no longer exists.
+func storageAttachmentState(resClient *compute.StorageAttachmentsClient, name string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		input := compute.GetStorageAttachmentInput{
+			Name: name,
+		}
+		info, err := resClient.GetStorageAttachment(&input)
+		if err != nil {
+			if wasNotFoundError(err) {
+				return nil, waitStateDeleted, nil
+			}
+			return nil, "", err
+		}
+		if info == nil {
+			return nil, "", fmt.Errorf("Storage attachment %s no longer exists", name)
+		}
+		if info.State == compute.Unavailable {
+			return info, string(info.State), fmt.Errorf("Storage attachment %s is unavailable", name)
+		}
+		return info, string(info.State), nil
+	}
+}
+
+func resourceOPCStorageAttachmentDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Printf("[DEBUG] Resource state: %#v", d.State())
 	name := d.Id()
 
 	log.Printf("[DEBUG] Deleting StorageAttachment: %v", name)
 
-	input := compute.DeleteStorageAttachmentInput{
-		Name:    name,
-		Timeout: d.Timeout(schema.TimeoutDelete),
-	}
-	if err := resClient.DeleteStorageAttachment(&input); err != nil {
-		return fmt.Errorf("Error deleting StorageAttachment")
+	if err := detachStorageVolume(meta, name, d.Timeout(schema.TimeoutDelete)); err != nil {
+		return fmt.Errorf("Error deleting StorageAttachment %s: %s", name, err)
 	}
 	return nil
 }
@@ -63,6 +63,39 @@ func TestAccOPCStorageAttachment_BootAndAttached(t *testing.T) {
 	})
 }
 
+func TestAccOPCStorageAttachment_ResizeVolume(t *testing.T) {
+	ri := acctest.RandInt()
+	resourceName := "opc_compute_storage_attachment.test"
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageAttachmentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStorageAttachmentResizeVolume(ri, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageAttachmentExists,
+					resource.TestCheckResourceAttr(resourceName, "storage_volume_size", "1"),
+				),
+			},
+			{
+				Config: testAccStorageAttachmentResizeVolume(ri, 2),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("opc_compute_storage_volume.foo", "size", "2"),
+				),
+			},
+			{
+				// The attachment reports the new size once refreshed
+				Config: testAccStorageAttachmentResizeVolume(ri, 2),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageAttachmentExists,
+					resource.TestCheckResourceAttr(resourceName, "storage_volume_size", "2"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckStorageAttachmentExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.StorageAttachments()
 
@@ -123,6 +156,28 @@ resource "opc_compute_storage_attachment" "test" {
 `, rInt, rInt, TestImageList)
 }
 
+func testAccStorageAttachmentResizeVolume(rInt, size int) string {
+	return fmt.Sprintf(`
+resource "opc_compute_storage_volume" "foo" {
+  name = "acc-test-storage-attachment-%d"
+  size = %d
+}
+
+resource "opc_compute_instance" "test" {
+  name       = "acc-test-storage-attachment-%d"
+  label      = "TestAccOPCStorageAttachment_ResizeVolume"
+  shape      = "oc3"
+  image_list = "%s"
+}
+
+resource "opc_compute_storage_attachment" "test" {
+  instance       = "${opc_compute_instance.test.name}"
+  storage_volume = "${opc_compute_storage_volume.foo.name}"
+  index          = 1
+}
+`, rInt, size, rInt, TestImageList)
+}
+
 func testAccStorageAttachmentInvalidIndex(rInt int) string {
 	return fmt.Sprintf(`
 resource "opc_compute_storage_volume" "foo" {
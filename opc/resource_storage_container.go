@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/storage"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -48,12 +47,14 @@ func resourceOPCStorageContainer() *schema.Resource {
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
 			"primary_key": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
 			},
 			"secondary_key": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
 			},
 			"max_age": {
 				Type:     schema.TypeInt,
@@ -129,7 +130,7 @@ func resourceOPCStorageContainerCreate(d *schema.ResourceData, meta interface{})
 
 	info, err := storageClient.CreateContainer(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating Storage Container: %s", err)
+		return fmt.Errorf("Error creating Storage Container: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -151,11 +152,11 @@ func resourceOPCStorageContainerRead(d *schema.ResourceData, meta interface{}) e
 	result, err := storageClient.GetContainer(&input)
 	if err != nil {
 		// Storage Container does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Storage Container '%s': %s", name, err)
+		return fmt.Errorf("Error reading Storage Container '%s': %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -198,7 +199,7 @@ func resourceOPCStorageContainerDelete(d *schema.ResourceData, meta interface{})
 		Name: name,
 	}
 	if err := storageClient.DeleteContainer(&input); err != nil {
-		return fmt.Errorf("Error deleting Storage Container '%s': %s", name, err)
+		return fmt.Errorf("Error deleting Storage Container '%s': %s", name, newAPIError(err))
 	}
 
 	return nil
@@ -264,7 +265,7 @@ func resourceOPCStorageContainerUpdate(d *schema.ResourceData, meta interface{})
 
 	info, err := storageClient.UpdateContainer(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating Storage Container: %s", err)
+		return fmt.Errorf("Error updating Storage Container: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
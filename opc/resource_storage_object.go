@@ -2,24 +2,43 @@ package opc
 
 import (
 	"bytes"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"strings"
 
 	"github.com/hashicorp/go-oracle-terraform/storage"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/mitchellh/go-homedir"
 )
 
+const (
+	storageLargeObjectTypeDynamic = "dynamic"
+	storageLargeObjectTypeStatic  = "static"
+
+	// storageObjectMaxSize is the largest object which can be uploaded with a
+	// single request. Larger files are uploaded in segments of
+	// storageObjectDefaultSegmentSize when no segment_size is set.
+	storageObjectMaxSize            = 5 * 1024 * 1024 * 1024
+	storageObjectDefaultSegmentSize = 1024 * 1024 * 1024
+)
+
 func resourceOPCStorageObject() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceOPCStorageObjectCreate,
 		Read:   resourceOPCStorageObjectRead,
+		Update: resourceOPCStorageObjectUpdate,
 		Delete: resourceOPCStorageObjectDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceOPCStorageObjectCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -36,21 +55,18 @@ func resourceOPCStorageObject() *schema.Resource {
 			"content": {
 				Type:          schema.TypeString,
 				Optional:      true,
-				ForceNew:      true,
 				Description:   "Raw content in string-form of the data",
 				ConflictsWith: []string{"copy_from", "file"},
 			},
 			"file": {
 				Type:          schema.TypeString,
 				Optional:      true,
-				ForceNew:      true,
 				Description:   "File path for the content to use for data",
 				ConflictsWith: []string{"copy_from", "content"},
 			},
 			"content_disposition": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    true,
 				Description: "Overrides the behavior of the browser",
 			},
 			"content_encoding": {
@@ -63,7 +79,6 @@ func resourceOPCStorageObject() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
 				Description: "Set the MIME type for the object",
 			},
 			"copy_from": {
@@ -85,23 +100,43 @@ func resourceOPCStorageObject() *schema.Resource {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
 				Description: "The date and time in UNIX Epoch time stamp format when the system removes the object",
 			},
 			"etag": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
 				Description: "MD5 checksum value of the request body. Unquoted. Strongly Recommended",
 			},
 			"metadata": {
 				Type:        schema.TypeMap,
 				Optional:    true,
 				Computed:    true,
-				ForceNew:    true,
 				Description: "The object metadata",
 			},
+			"segment_size": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ValidateFunc:  validation.IntAtLeast(1),
+				ConflictsWith: []string{"content", "copy_from", "etag"},
+				Description:   "Size in bytes of the segments a file larger than it is uploaded in",
+			},
+			"segment_container": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"content", "copy_from"},
+				Description:   "Name of the container the segments of a large object are uploaded to",
+			},
+			"large_object_type": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"content", "copy_from"},
+				ValidateFunc: validation.StringInSlice([]string{
+					storageLargeObjectTypeDynamic,
+					storageLargeObjectTypeStatic,
+				}, false),
+				Description: "Whether a segmented file is uploaded as a dynamic or static large object",
+			},
 			"transfer_encoding": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -159,6 +194,222 @@ func resourceOPCStorageObjectCreate(d *schema.ResourceData, meta interface{}) er
 	}
 	resClient := storageClient.Objects()
 
+	input, err := expandStorageObjectInput(d)
+	if err != nil {
+		return err
+	}
+	if file, ok := input.Body.(*os.File); ok {
+		defer file.Close()
+	}
+
+	segmented, err := uploadStorageObjectSegments(d, meta, input)
+	if err != nil {
+		return fmt.Errorf("Error creating Object: %s", newAPIError(err))
+	}
+
+	if !segmented {
+		if v, ok := d.GetOk("etag"); ok {
+			input.ETag = v.(string)
+		}
+
+		if _, err := resClient.CreateObject(input); err != nil {
+			return fmt.Errorf("Error creating Object: %s", newAPIError(err))
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", input.Container, input.Name))
+	return resourceOPCStorageObjectRead(d, meta)
+}
+
+// uploadStorageObjectSegments uploads a `file` larger than the segment size as
+// a large object, returning false for objects which are uploaded with a single
+// PUT instead.
+func uploadStorageObjectSegments(d *schema.ResourceData, meta interface{}, input *storage.CreateObjectInput) (bool, error) {
+	file, ok := input.Body.(*os.File)
+	if !ok {
+		return false, nil
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	segmentSize := getStorageObjectSegmentSize(d.Get("segment_size").(int), info.Size())
+	if segmentSize == 0 {
+		return false, nil
+	}
+
+	objectClient, err := meta.(*Client).getStorageObjectClient()
+	if err != nil {
+		return false, err
+	}
+
+	segmentContainer := d.Get("segment_container").(string)
+	if segmentContainer == "" {
+		segmentContainer = input.Container + "_segments"
+	}
+
+	log.Printf("[DEBUG] Uploading %s/%s in segments of %d bytes to %s", input.Container, input.Name, segmentSize, segmentContainer)
+	err = objectClient.uploadLargeObject(meta.(*Client).stopContext, &largeObjectUploadInput{
+		Container:        input.Container,
+		Name:             input.Name,
+		Headers:          getStorageObjectInputHeaders(input),
+		SegmentContainer: segmentContainer,
+		SegmentSize:      segmentSize,
+		Static:           d.Get("large_object_type").(string) == storageLargeObjectTypeStatic,
+		File:             file,
+		Size:             info.Size(),
+	})
+	return true, err
+}
+
+// getStorageObjectSegmentSize returns the size of the segments a file is
+// uploaded in, or 0 when it is uploaded with a single PUT.
+func getStorageObjectSegmentSize(segmentSize int, fileSize int64) int64 {
+	if segmentSize == 0 && fileSize > storageObjectMaxSize {
+		segmentSize = storageObjectDefaultSegmentSize
+	}
+	if segmentSize == 0 || fileSize <= int64(segmentSize) {
+		return 0
+	}
+	return int64(segmentSize)
+}
+
+func resourceOPCStorageObjectUpdate(d *schema.ResourceData, meta interface{}) error {
+	storageClient, err := meta.(*Client).getStorageClient()
+	if err != nil {
+		return err
+	}
+	resClient := storageClient.Objects()
+
+	// Re-PUT the object under the same name when its content changed, which
+	// replaces the content and metadata in place.
+	changed, err := storageObjectContentChanged(d)
+	if err != nil {
+		return err
+	}
+	if changed {
+		input, err := expandStorageObjectInput(d)
+		if err != nil {
+			return err
+		}
+		if file, ok := input.Body.(*os.File); ok {
+			defer file.Close()
+		}
+
+		// The segments of the object being replaced are removed once the
+		// new content has been uploaded
+		objectClient, err := meta.(*Client).getStorageObjectClient()
+		if err != nil {
+			return err
+		}
+		segments, err := objectClient.getLargeObjectSegments(input.Container, input.Name)
+		if err != nil {
+			return fmt.Errorf("Error reading segments of Storage Container Object (%s): %s", d.Id(), newAPIError(err))
+		}
+
+		segmented, err := uploadStorageObjectSegments(d, meta, input)
+		if err != nil {
+			return fmt.Errorf("Error updating Storage Container Object (%s): %s", d.Id(), newAPIError(err))
+		}
+		if !segmented {
+			if v, ok := d.GetOk("etag"); ok && d.HasChange("etag") {
+				input.ETag = v.(string)
+			}
+
+			if _, err := resClient.CreateObject(input); err != nil {
+				return fmt.Errorf("Error updating Storage Container Object (%s): %s", d.Id(), newAPIError(err))
+			}
+		}
+
+		if err := objectClient.deleteSegments(segments); err != nil {
+			return fmt.Errorf("Error deleting previous segments of Storage Container Object (%s): %s", d.Id(), newAPIError(err))
+		}
+		return resourceOPCStorageObjectRead(d, meta)
+	}
+
+	if d.HasChange("metadata") || d.HasChange("content_type") || d.HasChange("content_disposition") || d.HasChange("delete_at") {
+		objectClient, err := meta.(*Client).getStorageObjectClient()
+		if err != nil {
+			return err
+		}
+
+		container := d.Get("container").(string)
+		name := d.Get("name").(string)
+		if err := objectClient.updateObjectMetadata(container, name, getStorageObjectMetadataHeaders(d)); err != nil {
+			return fmt.Errorf("Error updating metadata of Storage Container Object (%s): %s", d.Id(), newAPIError(err))
+		}
+	}
+
+	return resourceOPCStorageObjectRead(d, meta)
+}
+
+// storageObjectContentChanged reports whether the object needs to be uploaded
+// again. The etag planned by CustomizeDiff is not visible during the apply, so
+// the contents of `file` are hashed again and compared to the stored etag.
+func storageObjectContentChanged(d *schema.ResourceData) (bool, error) {
+	if d.HasChange("content") || d.HasChange("etag") {
+		return true, nil
+	}
+
+	v, ok := d.GetOk("file")
+	if !ok {
+		return false, nil
+	}
+	if d.HasChange("segment_container") || d.HasChange("large_object_type") {
+		return true, nil
+	}
+	etag, err := getStorageObjectFileETag(v.(string), d.Get("segment_size").(int))
+	if err != nil {
+		return false, err
+	}
+	return etag != strings.Trim(d.Get("etag").(string), "\""), nil
+}
+
+// Only the object metadata can be changed without uploading the content again.
+// Changes to `content`, and to the contents of `file`, are flagged by marking
+// the etag as changed. The path of the file itself is not compared, so moving
+// a file without changing its contents does not upload it again.
+func resourceOPCStorageObjectCustomizeDiff(diff *schema.ResourceDiff, v interface{}) error {
+	if diff.Id() == "" || diff.HasChange("etag") {
+		return nil
+	}
+
+	changed := diff.HasChange("content")
+	if v, ok := diff.GetOk("file"); ok && !changed {
+		if !diff.NewValueKnown("file") || !diff.NewValueKnown("segment_size") {
+			changed = true
+		} else if diff.HasChange("segment_container") || diff.HasChange("large_object_type") {
+			// The segments are uploaded again to the new container or
+			// under the new kind of manifest
+			changed = true
+		} else {
+			etag, err := getStorageObjectFileETag(v.(string), diff.Get("segment_size").(int))
+			if err != nil {
+				if !os.IsNotExist(err) {
+					return err
+				}
+				// The file may be created during the apply
+				changed = diff.HasChange("file")
+			} else {
+				o, _ := diff.GetChange("etag")
+				changed = etag != strings.Trim(o.(string), "\"")
+			}
+		}
+	}
+
+	if changed {
+		for _, k := range []string{"etag", "content_length", "last_modified"} {
+			if err := diff.SetNewComputed(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expandStorageObjectInput builds the PUT request for the object from the
+// content source and metadata, excluding the etag.
+func expandStorageObjectInput(d *schema.ResourceData) (*storage.CreateObjectInput, error) {
 	// Populate required attr
 	input := &storage.CreateObjectInput{
 		Name:      d.Get("name").(string),
@@ -175,18 +426,18 @@ func resourceOPCStorageObjectCreate(d *schema.ResourceData, meta interface{}) er
 		source := v.(string)
 		path, err := homedir.Expand(source)
 		if err != nil {
-			return fmt.Errorf("Error expanding homedir in file (%s): %s", source, err)
+			return nil, fmt.Errorf("Error expanding homedir in file (%s): %s", source, err)
 		}
 		file, err := os.Open(path)
 		if err != nil {
-			return fmt.Errorf("Error opening Storage Object file (%s): %s", source, err)
+			return nil, fmt.Errorf("Error opening Storage Object file (%s): %s", source, err)
 		}
 		input.Body = file
 	} else if v, ok := d.GetOk("copy_from"); ok {
 		input.CopyFrom = v.(string)
 	} else {
 		// One of the three attributes are required
-		return fmt.Errorf("Must specify `file`, `copy_from`, or `content` field")
+		return nil, fmt.Errorf("Must specify `file`, `copy_from`, or `content` field")
 	}
 
 	if v, ok := d.GetOk("content_disposition"); ok {
@@ -205,10 +456,6 @@ func resourceOPCStorageObjectCreate(d *schema.ResourceData, meta interface{}) er
 		input.DeleteAt = v.(int)
 	}
 
-	if v, ok := d.GetOk("etag"); ok {
-		input.ETag = v.(string)
-	}
-
 	if v, ok := d.GetOk("metadata"); ok {
 		metadata := make(map[string]string)
 		for name, value := range v.(map[string]interface{}) {
@@ -221,13 +468,110 @@ func resourceOPCStorageObjectCreate(d *schema.ResourceData, meta interface{}) er
 		input.TransferEncoding = v.(string)
 	}
 
-	result, err := resClient.CreateObject(input)
+	return input, nil
+}
+
+// getStorageObjectMetadataHeaders returns the headers of a metadata POST. The
+// POST replaces all of the object's metadata, so every header is sent, along
+// with removals for the metadata keys which are no longer configured.
+func getStorageObjectMetadataHeaders(d *schema.ResourceData) map[string]string {
+	headers := make(map[string]string)
+
+	if v, ok := d.GetOk("content_type"); ok {
+		headers["Content-Type"] = v.(string)
+	}
+	if v, ok := d.GetOk("content_disposition"); ok {
+		headers["Content-Disposition"] = v.(string)
+	}
+	if v, ok := d.GetOk("content_encoding"); ok {
+		headers["Content-Encoding"] = v.(string)
+	}
+	if v, ok := d.GetOk("delete_at"); ok {
+		headers["X-Delete-At"] = fmt.Sprintf("%d", v.(int))
+	}
+	if v, ok := d.GetOk("object_manifest"); ok {
+		headers["X-Object-Manifest"] = v.(string)
+	}
+
+	o, n := d.GetChange("metadata")
+	metadata := n.(map[string]interface{})
+	for name, value := range metadata {
+		headers["X-Object-Meta-"+name] = value.(string)
+	}
+	for name := range o.(map[string]interface{}) {
+		if _, ok := metadata[name]; !ok {
+			headers["X-Remove-Object-Meta-"+name] = "x"
+		}
+	}
+
+	return headers
+}
+
+// getStorageObjectFileETag returns the etag the storage service reports for an
+// object uploaded from a file: the MD5 checksum of the file, or for a file
+// uploaded in segments, the MD5 checksum of the checksums of the segments.
+// The etag of a segmented object is returned without quotes.
+func getStorageObjectFileETag(source string, segmentSize int) (string, error) {
+	path, err := homedir.Expand(source)
 	if err != nil {
-		return fmt.Errorf("Error creating Object: %s", err)
+		return "", fmt.Errorf("Error expanding homedir in file (%s): %s", source, err)
 	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
 
-	d.SetId(result.ID)
-	return resourceOPCStorageObjectRead(d, meta)
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := getStorageObjectSegmentSize(segmentSize, info.Size())
+	if size == 0 {
+		size = info.Size()
+	}
+
+	var etags []string
+	for {
+		hash := md5.New()
+		n, err := io.CopyN(hash, file, size)
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("Error reading Storage Object file (%s): %s", source, err)
+		}
+		if n > 0 || len(etags) == 0 {
+			etags = append(etags, hex.EncodeToString(hash.Sum(nil)))
+		}
+		if n < size || size == 0 {
+			break
+		}
+	}
+
+	if len(etags) == 1 {
+		return etags[0], nil
+	}
+	return strings.Trim(getLargeObjectETag(etags), "\""), nil
+}
+
+// getStorageObjectInputHeaders returns the headers CreateObject would send for
+// the metadata of an object.
+func getStorageObjectInputHeaders(input *storage.CreateObjectInput) map[string]string {
+	headers := make(map[string]string)
+	if input.ContentDisposition != "" {
+		headers["Content-Disposition"] = input.ContentDisposition
+	}
+	if input.ContentEncoding != "" {
+		headers["Content-Encoding"] = input.ContentEncoding
+	}
+	if input.ContentType != "" {
+		headers["Content-Type"] = input.ContentType
+	}
+	if input.DeleteAt != 0 {
+		headers["X-Delete-At"] = fmt.Sprintf("%d", input.DeleteAt)
+	}
+	for name, value := range input.ObjectMetadata {
+		headers["X-Object-Meta-"+name] = value
+	}
+	return headers
 }
 
 func resourceOPCStorageObjectRead(d *schema.ResourceData, meta interface{}) error {
@@ -243,7 +587,7 @@ func resourceOPCStorageObjectRead(d *schema.ResourceData, meta interface{}) erro
 
 	result, err := resClient.GetObject(input)
 	if err != nil {
-		return fmt.Errorf("Error reading Storage Container Object (%s): %s", d.Id(), err)
+		return fmt.Errorf("Error reading Storage Container Object (%s): %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -276,11 +620,24 @@ func resourceOPCStorageObjectDelete(d *schema.ResourceData, meta interface{}) er
 	}
 	resClient := storageClient.Objects()
 
+	objectClient, err := meta.(*Client).getStorageObjectClient()
+	if err != nil {
+		return err
+	}
+	segments, err := objectClient.getLargeObjectSegments(d.Get("container").(string), d.Get("name").(string))
+	if err != nil {
+		return fmt.Errorf("Error reading segments of Storage Container Object (%s): %s", d.Id(), newAPIError(err))
+	}
+
 	input := &storage.DeleteObjectInput{
 		ID: d.Id(),
 	}
 	if err := resClient.DeleteObject(input); err != nil {
-		return fmt.Errorf("Error deleting Storage Container Object (%s): %s", d.Id(), err)
+		return fmt.Errorf("Error deleting Storage Container Object (%s): %s", d.Id(), newAPIError(err))
+	}
+
+	if err := objectClient.deleteSegments(segments); err != nil {
+		return fmt.Errorf("Error deleting segments of Storage Container Object (%s): %s", d.Id(), newAPIError(err))
 	}
 
 	return nil
@@ -2,6 +2,11 @@ package opc
 
 import (
 	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/hashicorp/go-oracle-terraform/storage"
@@ -125,6 +130,204 @@ func TestAccOPCStorageObject_objectMetadata(t *testing.T) {
 	})
 }
 
+func TestAccOPCStorageObject_updateMetadata(t *testing.T) {
+	resName := "opc_storage_object.test"
+	rInt := acctest.RandInt()
+
+	body := _SourceInput
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_objectMetadata(rInt, body),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "metadata.%", "2"),
+				),
+			},
+			{
+				Config: testAccOPCStorageObject_objectMetadataUpdated(rInt, body),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "metadata.%", "1"),
+					resource.TestCheckResourceAttr(resName, "metadata.Foo", "baz"),
+					resource.TestCheckResourceAttr(resName, "content_type", "text/html;charset=UTF-8"),
+					resource.TestCheckResourceAttr(resName, "content_disposition", "attachment; filename=lorem.txt"),
+					resource.TestCheckResourceAttr(resName, "delete_at", "4102444800"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCStorageObject_updateContent(t *testing.T) {
+	resName := "opc_storage_object.test"
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_contentSource(rInt, "initial"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "content_length", "8"),
+				),
+			},
+			{
+				Config: testAccOPCStorageObject_contentSource(rInt, "updated content"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "content_length", "16"),
+					resource.TestCheckResourceAttr(resName, "content_type", "text/plain;charset=UTF-8"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCStorageObject_updateFile(t *testing.T) {
+	resName := "opc_storage_object.test"
+	rInt := acctest.RandInt()
+
+	dir, err := ioutil.TempDir("", "opc-storage-object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "source.txt")
+	if err := ioutil.WriteFile(path, []byte("initial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_fileSource(rInt, path),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "content_length", "7"),
+				),
+			},
+			{
+				// The path is unchanged, but the contents of the file are not
+				PreConfig: func() {
+					if err := ioutil.WriteFile(path, []byte("updated content"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccOPCStorageObject_fileSource(rInt, path),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "content_length", "15"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCStorageObject_dynamicLargeObject(t *testing.T) {
+	testAccOPCStorageObjectSegmented(t, storageLargeObjectTypeDynamic)
+}
+
+func TestAccOPCStorageObject_staticLargeObject(t *testing.T) {
+	testAccOPCStorageObjectSegmented(t, storageLargeObjectTypeStatic)
+}
+
+func testAccOPCStorageObjectSegmented(t *testing.T, largeObjectType string) {
+	resName := "opc_storage_object.test"
+	rInt := acctest.RandInt()
+	segmentSize := 1024 * 1024
+
+	dir, err := ioutil.TempDir("", "opc-storage-object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "large.bin")
+	if err := testAccWriteStorageObjectFile(path, 5*segmentSize/2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckStorageObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOPCStorageObject_segmented(rInt, path, segmentSize, largeObjectType),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "content_length", strconv.Itoa(5*segmentSize/2)),
+					testAccCheckStorageObjectFileETag(resName, path, segmentSize),
+					testAccCheckStorageObjectSegments(fmt.Sprintf("acc-test-%d-segments", rInt), fmt.Sprintf("test-acc-%d/", rInt), 3),
+				),
+			},
+			{
+				// The object is uploaded again in four segments, and the
+				// three segments of the first upload are removed
+				PreConfig: func() {
+					if err := testAccWriteStorageObjectFile(path, 7*segmentSize/2, 2); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccOPCStorageObject_segmented(rInt, path, segmentSize, largeObjectType),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckStorageObjectExists,
+					resource.TestCheckResourceAttr(resName, "content_length", strconv.Itoa(7*segmentSize/2)),
+					testAccCheckStorageObjectFileETag(resName, path, segmentSize),
+					testAccCheckStorageObjectSegments(fmt.Sprintf("acc-test-%d-segments", rInt), fmt.Sprintf("test-acc-%d/", rInt), 4),
+				),
+			},
+		},
+	})
+}
+
+// testAccWriteStorageObjectFile writes size bytes of pseudo-random content,
+// which differs for each seed, to path.
+func testAccWriteStorageObjectFile(path string, size int, seed int64) error {
+	content := make([]byte, size)
+	if _, err := rand.New(rand.NewSource(seed)).Read(content); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+func testAccCheckStorageObjectFileETag(resName, path string, segmentSize int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		etag, err := getStorageObjectFileETag(path, segmentSize)
+		if err != nil {
+			return err
+		}
+		return resource.TestCheckResourceAttr(resName, "etag", fmt.Sprintf("%q", etag))(s)
+	}
+}
+
+func testAccCheckStorageObjectSegments(container, prefix string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := testAccProvider.Meta().(*Client).getStorageObjectClient()
+		if err != nil {
+			return err
+		}
+		names, err := client.listObjects(container, prefix)
+		if err != nil {
+			return err
+		}
+		if len(names) != count {
+			return fmt.Errorf("Expected %d segments in %s, got %d: %v", count, container, len(names), names)
+		}
+		return nil
+	}
+}
+
 func testAccCheckStorageObjectExists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).storageClient.Objects()
 
@@ -193,6 +396,28 @@ EOF
 		body)
 }
 
+func testAccOPCStorageObject_objectMetadataUpdated(rInt int, body string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "opc_storage_object" "test" {
+  name = "test-acc-%d"
+  container = "${opc_storage_container.foo.name}"
+	metadata = {
+		Foo = "baz"
+	}
+  content_type = "text/html;charset=UTF-8"
+  content_disposition = "attachment; filename=lorem.txt"
+  delete_at = 4102444800
+  content = <<EOF
+%s
+EOF
+}`,
+		testAccOPCStorageObject_testContainer(rInt),
+		rInt,
+		body)
+}
+
 func testAccOPCStorageObject_contentSource(rInt int, body string) string {
 	return fmt.Sprintf(`
 %s
@@ -241,6 +466,27 @@ resource "opc_storage_object" "test" {
 		path)
 }
 
+func testAccOPCStorageObject_segmented(rInt int, path string, segmentSize int, largeObjectType string) string {
+	return fmt.Sprintf(`
+%s
+
+resource "opc_storage_container" "segments" {
+  name = "acc-test-%d-segments"
+}
+
+resource "opc_storage_object" "test" {
+  name = "test-acc-%d"
+  container = "${opc_storage_container.foo.name}"
+  content_type = "application/octet-stream"
+  file = "%s"
+  segment_size = %d
+  segment_container = "${opc_storage_container.segments.name}"
+  large_object_type = "%s"
+}`,
+		testAccOPCStorageObject_testContainer(rInt),
+		rInt, rInt, path, segmentSize, largeObjectType)
+}
+
 const _SourceInput = `
 Lorem ipsum dolor sit amet, consectetur adipiscing elit. Morbi auctor nisi id sem gravida, quis sollicitudin dolor
 maximus. Sed est lectus, mollis sit amet neque eu, pulvinar aliquet turpis. Aenean in euismod erat. Proin pulvinar
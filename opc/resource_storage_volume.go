@@ -2,15 +2,25 @@ package opc
 
 import (
 	"fmt"
+	"log"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 )
 
+// Statuses reported by the storage volume API
+const (
+	storageVolumeStatusInitializing = "Initializing"
+	storageVolumeStatusOnline       = "Online"
+	storageVolumeStatusUpdating     = "Updating"
+	storageVolumeStatusError        = "Error"
+)
+
 func resourceOPCStorageVolume() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceOPCStorageVolumeCreate,
@@ -21,6 +31,8 @@ func resourceOPCStorageVolume() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: resourceOPCStorageVolumeCustomizeDiff,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(30 * time.Minute),
 			Update: schema.DefaultTimeout(30 * time.Minute),
@@ -90,7 +102,9 @@ func resourceOPCStorageVolume() *schema.Resource {
 				Default:  -1,
 			},
 
-			"tags": tagsOptionalSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 
 			// Computed fields
 			"hypervisor": {
@@ -160,7 +174,7 @@ func resourceOPCStorageVolumeCreate(d *schema.ResourceData, meta interface{}) er
 		Bootable:       bootable,
 		ImageList:      imageList,
 		ImageListEntry: imageListEntry,
-		Tags:           getStringList(d, "tags"),
+		Tags:           expandTags(d, meta),
 		Timeout:        d.Timeout(schema.TimeoutCreate),
 	}
 
@@ -176,7 +190,7 @@ func resourceOPCStorageVolumeCreate(d *schema.ResourceData, meta interface{}) er
 
 	info, err := resClient.CreateStorageVolume(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating storage volume %s: %s", name, err)
+		return fmt.Errorf("Error creating storage volume %s: %s", name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -204,17 +218,125 @@ func resourceOPCStorageVolumeUpdate(d *schema.ResourceData, meta interface{}) er
 		Properties:     []string{storageType},
 		ImageList:      imageList,
 		ImageListEntry: imageListEntry,
-		Tags:           getStringList(d, "tags"),
+		Tags:           expandTags(d, meta),
 		Timeout:        d.Timeout(schema.TimeoutUpdate),
 	}
 	_, err = resClient.UpdateStorageVolume(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating storage volume %s: %s", name, err)
+		return fmt.Errorf("Error updating storage volume %s: %s", name, newAPIError(err))
+	}
+
+	if d.HasChange("size") {
+		if err := waitForStorageVolumeResize(meta.(*Client), name, size, d.Timeout(schema.TimeoutUpdate)); err != nil {
+			return err
+		}
 	}
 
 	return resourceOPCStorageVolumeRead(d, meta)
 }
 
+// Storage volumes can only be grown in place, so a smaller size is rejected
+// at plan time rather than failing part way through the apply. Its tags_all
+// are planned by customizeDiffTagsAll.
+func resourceOPCStorageVolumeCustomizeDiff(diff *schema.ResourceDiff, v interface{}) error {
+	if err := customizeDiffTagsAll(diff, v); err != nil {
+		return err
+	}
+
+	if diff.Id() == "" || !diff.HasChange("size") || !diff.NewValueKnown("size") {
+		return nil
+	}
+
+	o, n := diff.GetChange("size")
+	if n.(int) < o.(int) {
+		return fmt.Errorf("Storage volume %s cannot be shrunk from %d GB to %d GB. Only increasing the size of a storage volume is supported, create a new volume to reduce its size.", diff.Id(), o.(int), n.(int))
+	}
+	return nil
+}
+
+// waitForStorageVolumeResize waits until the storage volume is Online at the
+// requested size, and then until every attachment of the volume is attached
+// again, so that resources which depend on the volume see the new size.
+func waitForStorageVolumeResize(meta *Client, name string, size int, timeout time.Duration) error {
+	resClient := meta.computeClient.StorageVolumes()
+
+	conf := waitConf{
+		Description: fmt.Sprintf("storage volume %s to be resized to %d GB", name, size),
+		Pending:     []string{storageVolumeStatusInitializing, storageVolumeStatusUpdating},
+		Target:      []string{storageVolumeStatusOnline},
+		Refresh:     storageVolumeStatus(resClient, name, size),
+		Timeout:     timeout,
+	}
+	if _, err := waitForState(meta.stopContext, conf); err != nil {
+		return fmt.Errorf("Error waiting for storage volume %s to be resized: %s", name, newAPIError(err))
+	}
+
+	attachments, err := getStorageVolumeAttachments(meta, name)
+	if err != nil {
+		return err
+	}
+	attachmentsClient := meta.computeClient.StorageAttachments()
+	for _, attachment := range attachments {
+		conf := waitConf{
+			Description: fmt.Sprintf("storage attachment %s of resized volume %s", attachment.Name, name),
+			Pending:     []string{string(compute.Attaching), string(compute.Unknown)},
+			Target:      []string{string(compute.Attached)},
+			Refresh:     storageAttachmentState(attachmentsClient, attachment.Name),
+			Timeout:     timeout,
+		}
+		if _, err := waitForState(meta.stopContext, conf); err != nil {
+			return fmt.Errorf("Error waiting for storage attachment %s of resized volume %s: %s", attachment.Name, name, newAPIError(err))
+		}
+	}
+
+	return nil
+}
+
+// storageVolumeStatus reports the status of a storage volume. A volume which is
+// Online but does not report the requested size yet is still Updating.
+func storageVolumeStatus(resClient *compute.StorageVolumeClient, name string, size int) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		input := compute.GetStorageVolumeInput{
+			Name: name,
+		}
+		info, err := resClient.GetStorageVolume(&input)
+		if err != nil {
+			return nil, "", err
+		}
+		if info == nil {
+			return nil, "", fmt.Errorf("Storage volume %s no longer exists", name)
+		}
+
+		if strings.EqualFold(info.Status, storageVolumeStatusError) {
+			return info, info.Status, fmt.Errorf("Storage volume %s is in an error state: %s", name, info.StatusDetail)
+		}
+
+		currentSize, err := strconv.Atoi(info.Size)
+		if err != nil {
+			return nil, "", err
+		}
+		if strings.EqualFold(info.Status, storageVolumeStatusOnline) && currentSize < size {
+			return info, storageVolumeStatusUpdating, nil
+		}
+		return info, info.Status, nil
+	}
+}
+
+// getStorageVolumeSize returns the size in GB of the named storage volume.
+func getStorageVolumeSize(resClient *compute.StorageVolumeClient, name string) (int, error) {
+	input := compute.GetStorageVolumeInput{
+		Name: name,
+	}
+	info, err := resClient.GetStorageVolume(&input)
+	if err != nil {
+		return 0, err
+	}
+	if info == nil {
+		return 0, fmt.Errorf("Unable to find storage volume: %s", name)
+	}
+	return strconv.Atoi(info.Size)
+}
+
 func resourceOPCStorageVolumeRead(d *schema.ResourceData, meta interface{}) error {
 	computeClient, err := meta.(*Client).getComputeClient()
 	if err != nil {
@@ -230,11 +352,11 @@ func resourceOPCStorageVolumeRead(d *schema.ResourceData, meta interface{}) erro
 	result, err := resClient.GetStorageVolume(&input)
 	if err != nil {
 		// Volume doesn't exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading storage volume %s: %s", name, err)
+		return fmt.Errorf("Error reading storage volume %s: %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -259,7 +381,7 @@ func resourceOPCStorageVolumeRead(d *schema.ResourceData, meta interface{}) erro
 	d.Set("snapshot_id", result.SnapshotID)
 	d.Set("snapshot_account", result.SnapshotAccount)
 
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 
@@ -282,12 +404,40 @@ func resourceOPCStorageVolumeDelete(d *schema.ResourceData, meta interface{}) er
 	}
 	err = resClient.DeleteStorageVolume(&input)
 	if err != nil {
-		return fmt.Errorf("Error deleting storage volume %s: %s", name, err)
+		if wasConflictError(err) {
+			if instances := getStorageVolumeInstances(meta.(*Client), name); len(instances) > 0 {
+				return fmt.Errorf("Error deleting storage volume %s: it is still attached to instance %s. "+
+					"Remove its opc_compute_storage_attachment, or the storage block of the instance, first: %s",
+					name, strings.Join(instances, ", "), newAPIError(err))
+			}
+		}
+		return fmt.Errorf("Error deleting storage volume %s: %s", name, newAPIError(err))
 	}
 
 	return nil
 }
 
+// getStorageVolumeInstances returns the names of the instances a storage
+// volume is attached to, to explain why it can't be deleted. Any error
+// listing the attachments is logged rather than hiding the original error.
+func getStorageVolumeInstances(meta *Client, volumeName string) []string {
+	attachments, err := getStorageVolumeAttachments(meta, volumeName)
+	if err != nil {
+		log.Printf("[WARN] Unable to find the instances storage volume %s is attached to: %s", volumeName, err)
+		return nil
+	}
+	collectionClient, err := meta.getComputeCollectionClient()
+	if err != nil {
+		return nil
+	}
+
+	instances := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		instances = append(instances, collectionClient.unqualify(attachment.InstanceName))
+	}
+	return instances
+}
+
 func flattenOPCStorageVolumeComputedFields(d *schema.ResourceData, result *compute.StorageVolumeInfo) {
 	d.Set("hypervisor", result.Hypervisor)
 	d.Set("machine_image", result.MachineImage)
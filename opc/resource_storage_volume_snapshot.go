@@ -5,7 +5,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -14,11 +13,15 @@ func resourceOPCStorageVolumeSnapshot() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceOPCStorageVolumeSnapshotCreate,
 		Read:   resourceOPCStorageVolumeSnapshotRead,
+		// Only moving tags between tags and tags_map updates it in place
+		Update: resourceOPCStorageVolumeSnapshotRead,
 		Delete: resourceOPCStorageVolumeSnapshotDelete,
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsForceNew,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(60 * time.Minute),
 			Delete: schema.DefaultTimeout(30 * time.Minute),
@@ -61,7 +64,9 @@ func resourceOPCStorageVolumeSnapshot() *schema.Resource {
 				ForceNew: true,
 			},
 
-			"tags": tagsForceNewSchema(),
+			"tags":     tagsOptionalSchema(),
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 
 			// Computed Attributes
 			"account": {
@@ -159,14 +164,14 @@ func resourceOPCStorageVolumeSnapshotCreate(d *schema.ResourceData, meta interfa
 		input.Property = compute.SnapshotPropertyCollocated
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) > 0 {
 		input.Tags = tags
 	}
 
 	info, err := resClient.CreateStorageVolumeSnapshot(input)
 	if err != nil {
-		return fmt.Errorf("Error creating snapshot '%s': %v", input.Name, err)
+		return fmt.Errorf("Error creating snapshot '%s': %v", input.Name, newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -187,11 +192,11 @@ func resourceOPCStorageVolumeSnapshotRead(d *schema.ResourceData, meta interface
 
 	result, err := resClient.GetStorageVolumeSnapshot(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading storage volume snapshot '%s': %v", name, err)
+		return fmt.Errorf("Error reading storage volume snapshot '%s': %v", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -228,7 +233,7 @@ func resourceOPCStorageVolumeSnapshotRead(d *schema.ResourceData, meta interface
 		d.Set("collocated", true)
 	}
 
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 
@@ -250,7 +255,7 @@ func resourceOPCStorageVolumeSnapshotDelete(d *schema.ResourceData, meta interfa
 	}
 
 	if err := resClient.DeleteStorageVolumeSnapshot(input); err != nil {
-		return fmt.Errorf("Error deleting storage volume snapshot '%s': %v", name, err)
+		return fmt.Errorf("Error deleting storage volume snapshot '%s': %v", name, newAPIError(err))
 	}
 
 	return nil
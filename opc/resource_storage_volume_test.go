@@ -2,6 +2,7 @@ package opc
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/go-oracle-terraform/compute"
@@ -96,6 +97,61 @@ func TestAccOPCStorageVolume_Update(t *testing.T) {
 	})
 }
 
+func TestAccOPCStorageVolume_Resize(t *testing.T) {
+	volumeResourceName := "opc_compute_storage_volume.test"
+	ri := acctest.RandInt()
+	config := fmt.Sprintf(testAccStorageVolumeComplete, ri)
+	resizedConfig := fmt.Sprintf(testAccStorageVolumeResized, ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: opcResourceCheck(volumeResourceName, testAccCheckStorageVolumeDestroyed),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					opcResourceCheck(volumeResourceName, testAccCheckStorageVolumeExists),
+					resource.TestCheckResourceAttr(volumeResourceName, "size", "2"),
+				),
+			},
+			{
+				Config: resizedConfig,
+				Check: resource.ComposeTestCheckFunc(
+					opcResourceCheck(volumeResourceName, testAccCheckStorageVolumeExists),
+					resource.TestCheckResourceAttr(volumeResourceName, "size", "4"),
+					resource.TestCheckResourceAttr(volumeResourceName, "status", "Online"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccOPCStorageVolume_Shrink(t *testing.T) {
+	volumeResourceName := "opc_compute_storage_volume.test"
+	ri := acctest.RandInt()
+	config := fmt.Sprintf(testAccStorageVolumeComplete, ri)
+	shrunkConfig := fmt.Sprintf(testAccStorageVolumeShrunk, ri)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: opcResourceCheck(volumeResourceName, testAccCheckStorageVolumeDestroyed),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					opcResourceCheck(volumeResourceName, testAccCheckStorageVolumeExists),
+				),
+			},
+			{
+				Config:      shrunkConfig,
+				ExpectError: regexp.MustCompile("cannot be shrunk from 2 GB to 1 GB"),
+			},
+		},
+	})
+}
+
 func TestAccOPCStorageVolume_Bootable(t *testing.T) {
 	volumeResourceName := "opc_compute_storage_volume.test"
 	ri := acctest.RandInt()
@@ -286,6 +342,24 @@ resource "opc_compute_storage_volume" "test" {
 }
 `
 
+const testAccStorageVolumeResized = `
+resource "opc_compute_storage_volume" "test" {
+  name        = "test-acc-stor-vol-%d"
+  description = "Provider Acceptance Tests Storage Volume Initial"
+  size        = 4
+  tags        = ["foo"]
+}
+`
+
+const testAccStorageVolumeShrunk = `
+resource "opc_compute_storage_volume" "test" {
+  name        = "test-acc-stor-vol-%d"
+  description = "Provider Acceptance Tests Storage Volume Initial"
+  size        = 1
+  tags        = ["foo"]
+}
+`
+
 func testAccStorageVolumeBootable(rInt int) string {
 	return fmt.Sprintf(`
 	resource "opc_compute_image_list" "test" {
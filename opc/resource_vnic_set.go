@@ -3,7 +3,6 @@ package opc
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -18,6 +17,8 @@ func resourceOPCVNICSet() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -44,6 +45,9 @@ func resourceOPCVNICSet() *schema.Resource {
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 		},
 	}
 }
@@ -76,14 +80,14 @@ func resourceOPCVNICSetCreate(d *schema.ResourceData, meta interface{}) error {
 		input.VirtualNICs = vnics
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
 
 	vnicSet, err := resClient.CreateVirtualNICSet(input)
 	if err != nil {
-		return fmt.Errorf("Error creating Virtual NIC Set: %s", err)
+		return fmt.Errorf("Error creating Virtual NIC Set: %s", newAPIError(err))
 	}
 
 	d.SetId(vnicSet.Name)
@@ -105,11 +109,11 @@ func resourceOPCVNICSetRead(d *schema.ResourceData, meta interface{}) error {
 
 	result, err := resClient.GetVirtualNICSet(input)
 	if err != nil {
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading Virtual NIC Set '%s': %s", name, err)
+		return fmt.Errorf("Error reading Virtual NIC Set '%s': %s", name, newAPIError(err))
 	}
 
 	if result == nil {
@@ -125,7 +129,7 @@ func resourceOPCVNICSetRead(d *schema.ResourceData, meta interface{}) error {
 	if err := setStringList(d, "virtual_nics", result.VirtualNICs); err != nil {
 		return err
 	}
-	if err := setStringList(d, "tags", result.Tags); err != nil {
+	if err := flattenTags(d, meta, result.Tags); err != nil {
 		return err
 	}
 	return nil
@@ -159,14 +163,14 @@ func resourceOPCVNICSetUpdate(d *schema.ResourceData, meta interface{}) error {
 		input.VirtualNICs = vnics
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
 
 	info, err := resClient.UpdateVirtualNICSet(input)
 	if err != nil {
-		return fmt.Errorf("Error updating Virtual NIC Set: %s", err)
+		return fmt.Errorf("Error updating Virtual NIC Set: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
@@ -186,7 +190,7 @@ func resourceOPCVNICSetDelete(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	if err := resClient.DeleteVirtualNICSet(input); err != nil {
-		return fmt.Errorf("Error deleting Virtual NIC Set '%s': %s", name, err)
+		return fmt.Errorf("Error deleting Virtual NIC Set '%s': %s", name, newAPIError(err))
 	}
 	return nil
 }
@@ -5,7 +5,6 @@ import (
 	"log"
 	"time"
 
-	"github.com/hashicorp/go-oracle-terraform/client"
 	"github.com/hashicorp/go-oracle-terraform/compute"
 	"github.com/hashicorp/terraform/helper/schema"
 )
@@ -20,6 +19,8 @@ func resourceOPCVPNEndpointV2() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		CustomizeDiff: customizeDiffTagsAll,
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(60 * time.Minute),
 			Update: schema.DefaultTimeout(60 * time.Minute),
@@ -109,8 +110,9 @@ func resourceOPCVPNEndpointV2() *schema.Resource {
 				},
 			},
 			"pre_shared_key": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
 			},
 			"reachable_routes": {
 				Type:     schema.TypeList,
@@ -123,6 +125,8 @@ func resourceOPCVPNEndpointV2() *schema.Resource {
 				Optional: true,
 				Elem:     &schema.Schema{Type: schema.TypeString},
 			},
+			"tags_map": tagsMapSchema(),
+			"tags_all": tagsAllSchema(),
 			"vnic_sets": {
 				Type:     schema.TypeList,
 				Required: true,
@@ -149,7 +153,7 @@ func resourceOPCVPNEndpointV2() *schema.Resource {
 }
 
 func resourceOPCVPNEndpointV2Create(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[DEBUG] Resource state: %#v", d.State())
+	log.Printf("[DEBUG] Resource state: %s", redactLogString(fmt.Sprintf("%#v", d.State())))
 
 	log.Print("[DEBUG] Creating VPNEndpointV2")
 
@@ -169,7 +173,7 @@ func resourceOPCVPNEndpointV2Create(d *schema.ResourceData, meta interface{}) er
 		Timeout:            d.Timeout(schema.TimeoutCreate),
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -196,15 +200,18 @@ func resourceOPCVPNEndpointV2Create(d *schema.ResourceData, meta interface{}) er
 
 	info, err := resClient.CreateVPNEndpointV2(&input)
 	if err != nil {
-		return fmt.Errorf("Error creating VPNEndpointV2: %s", err)
+		return fmt.Errorf("Error creating VPNEndpointV2: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
+	if err := setStringList(d, "tags_all", tags); err != nil {
+		return err
+	}
 	return resourceOPCVPNEndpointV2Read(d, meta)
 }
 
 func resourceOPCVPNEndpointV2Read(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[DEBUG] Resource state: %#v", d.State())
+	log.Printf("[DEBUG] Resource state: %s", redactLogString(fmt.Sprintf("%#v", d.State())))
 	computeClient, err := meta.(*Client).getComputeClient()
 	if err != nil {
 		return err
@@ -219,11 +226,11 @@ func resourceOPCVPNEndpointV2Read(d *schema.ResourceData, meta interface{}) erro
 	result, err := resClient.GetVPNEndpointV2(&getInput)
 	if err != nil {
 		// VPNEndpointV2 does not exist
-		if client.WasNotFoundError(err) {
+		if wasNotFoundError(err) {
 			d.SetId("")
 			return nil
 		}
-		return fmt.Errorf("Error reading VPNEndpointV2 %s: %s", d.Id(), err)
+		return fmt.Errorf("Error reading VPNEndpointV2 %s: %s", d.Id(), newAPIError(err))
 	}
 
 	if result == nil {
@@ -231,7 +238,7 @@ func resourceOPCVPNEndpointV2Read(d *schema.ResourceData, meta interface{}) erro
 		return nil
 	}
 
-	log.Printf("[DEBUG] Read state of VPNEndpointV2 %s: %#v", d.Id(), result)
+	log.Printf("[DEBUG] Read state of VPNEndpointV2 %s: %s", d.Id(), redactLogString(fmt.Sprintf("%#v", result)))
 	d.Set("name", result.Name)
 	d.Set("customer_vpn_endpoint", result.CustomerVPNGateway)
 	d.Set("enabled", result.Enabled)
@@ -243,6 +250,14 @@ func resourceOPCVPNEndpointV2Read(d *schema.ResourceData, meta interface{}) erro
 	d.Set("local_gateway_private_ip_address", string(result.LocalGatewayPrivateIPAddress))
 	d.Set("tunnel_status", string(result.TunnelStatus))
 
+	// The tags of a VPN endpoint aren't returned by the service, so the tags
+	// it was last created or updated with are kept
+	if _, ok := d.GetOk("tags_all"); !ok {
+		if err := setStringList(d, "tags_all", expandTags(d, meta)); err != nil {
+			return err
+		}
+	}
+
 	if err := setStringList(d, "reachable_routes", result.ReachableRoutes); err != nil {
 		return err
 	}
@@ -270,7 +285,7 @@ func resourceOPCVPNEndpointV2Read(d *schema.ResourceData, meta interface{}) erro
 }
 
 func resourceOPCVPNEndpointV2Update(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[DEBUG] Resource state: %#v", d.State())
+	log.Printf("[DEBUG] Resource state: %s", redactLogString(fmt.Sprintf("%#v", d.State())))
 
 	log.Print("[DEBUG] Creating VPNEndpointV2")
 
@@ -285,11 +300,12 @@ func resourceOPCVPNEndpointV2Update(d *schema.ResourceData, meta interface{}) er
 		CustomerVPNGateway: d.Get("customer_vpn_gateway").(string),
 		IPNetwork:          d.Get("ip_network").(string),
 		PSK:                d.Get("pre_shared_key").(string),
+		ReachableRoutes:    getStringList(d, "reachable_routes"),
 		VNICSets:           getStringList(d, "vnic_sets"),
 		Timeout:            d.Timeout(schema.TimeoutUpdate),
 	}
 
-	tags := getStringList(d, "tags")
+	tags := expandTags(d, meta)
 	if len(tags) != 0 {
 		input.Tags = tags
 	}
@@ -320,15 +336,18 @@ func resourceOPCVPNEndpointV2Update(d *schema.ResourceData, meta interface{}) er
 
 	info, err := resClient.UpdateVPNEndpointV2(&input)
 	if err != nil {
-		return fmt.Errorf("Error updating VPNEndpointV2: %s", err)
+		return fmt.Errorf("Error updating VPNEndpointV2: %s", newAPIError(err))
 	}
 
 	d.SetId(info.Name)
+	if err := setStringList(d, "tags_all", tags); err != nil {
+		return err
+	}
 	return resourceOPCVPNEndpointV2Read(d, meta)
 }
 
 func resourceOPCVPNEndpointV2Delete(d *schema.ResourceData, meta interface{}) error {
-	log.Printf("[DEBUG] Resource state: %#v", d.State())
+	log.Printf("[DEBUG] Resource state: %s", redactLogString(fmt.Sprintf("%#v", d.State())))
 	computeClient, err := meta.(*Client).getComputeClient()
 	if err != nil {
 		return err
@@ -62,6 +62,36 @@ func TestAccOPCVPNEndpointV2_Update(t *testing.T) {
 	})
 }
 
+func TestAccOPCVPNEndpointV2_UpdateReachableRoutes(t *testing.T) {
+	resourceName := "opc_compute_vpn_endpoint_v2.test"
+	ri := acctest.RandInt()
+	config := testAccVPNEndpointV2Basic(ri)
+	config2 := testAccVPNEndpointV2ReachableRoutes(ri)
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckVPNEndpointV2Destroy,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVPNEndpointV2Exists,
+					resource.TestCheckResourceAttr(resourceName, "reachable_routes.#", "1"),
+				),
+			},
+			{
+				Config: config2,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckVPNEndpointV2Exists,
+					resource.TestCheckResourceAttr(resourceName, "reachable_routes.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "reachable_routes.0", "10.10.0.0/16"),
+					resource.TestCheckResourceAttr(resourceName, "reachable_routes.1", "127.0.0.1/24"),
+				),
+			},
+		},
+	})
+}
+
 func testAccCheckVPNEndpointV2Exists(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client).computeClient.VPNEndpointV2s()
 
@@ -164,3 +194,35 @@ func testAccVPNEndpointV2Update(rInt int) string {
 	}
 	`, rInt, rInt, rInt)
 }
+
+func testAccVPNEndpointV2ReachableRoutes(rInt int) string {
+	return fmt.Sprintf(`
+	resource "opc_compute_ip_network" "test" {
+		name = "testing-ip-network-%d"
+		ip_address_prefix = "10.0.12.0/24"
+	}
+
+	resource "opc_compute_vnic_set" "test" {
+		name = "testing-vnic-set-%d"
+
+		lifecycle {
+			ignore_changes = [ "applied_acls" ]
+		}
+	}
+
+	resource "opc_compute_vpn_endpoint_v2" "test" {
+	  name        = "test_vpn_endpoint_v2-%d"
+	  customer_vpn_gateway = "127.0.0.1"
+	  ip_network = "${opc_compute_ip_network.test.name}"
+	  pre_shared_key = "asdfasdf"
+	  reachable_routes = ["10.10.0.0/16", "127.0.0.1/24"]
+	  vnic_sets = ["${opc_compute_vnic_set.test.name}"]
+
+		timeouts {
+			create = "2h"
+			update = "2h"
+			delete = "2h"
+		}
+	}
+	`, rInt, rInt, rInt)
+}
@@ -0,0 +1,223 @@
+package opc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryMaxElapsedTime = 5 * time.Minute
+	defaultRetryMaxBackoff     = 30 * time.Second
+	retryInitialBackoff        = 1 * time.Second
+)
+
+// defaultRetryableStatusCodes are the responses of the OPC APIs which are
+// worth retrying: rate limiting, and the service being briefly unavailable.
+var defaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy decides which failed requests are retried, and how long to wait
+// between attempts. Zero values are replaced with the defaults.
+type RetryPolicy struct {
+	// StatusCodes are the HTTP status codes of the responses which are retried
+	StatusCodes []int
+	// MaxElapsedTime is the longest time to spend on a request, including
+	// the waits between attempts
+	MaxElapsedTime time.Duration
+	// MaxBackoff caps the exponential backoff between attempts
+	MaxBackoff time.Duration
+}
+
+// retryTransport retries requests which fail with a retryable status code or
+// a network error, up to a number of attempts. It backs off exponentially
+// between attempts, unless the response says how long to wait with a
+// Retry-After header.
+//
+// The go-oracle-terraform clients retry every failed response, including
+// validation errors and conflicts, and none of the network errors, so the
+// provider makes a single attempt through them and retries here instead.
+type retryTransport struct {
+	transport   http.RoundTripper
+	attempts    int
+	statusCodes map[int]bool
+	maxElapsed  time.Duration
+	maxBackoff  time.Duration
+}
+
+func newRetryTransport(transport http.RoundTripper, attempts int, policy RetryPolicy) *retryTransport {
+	t := &retryTransport{
+		transport:   transport,
+		attempts:    attempts,
+		statusCodes: make(map[int]bool),
+		maxElapsed:  policy.MaxElapsedTime,
+		maxBackoff:  policy.MaxBackoff,
+	}
+
+	statusCodes := policy.StatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = defaultRetryableStatusCodes
+	}
+	for _, code := range statusCodes {
+		t.statusCodes[code] = true
+	}
+	if t.maxElapsed == 0 {
+		t.maxElapsed = defaultRetryMaxElapsedTime
+	}
+	if t.maxBackoff == 0 {
+		t.maxBackoff = defaultRetryMaxBackoff
+	}
+	return t
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.attempts <= 1 {
+		return t.transport.RoundTrip(req)
+	}
+
+	if !isRewindable(req) {
+		log.Printf("[DEBUG] Not retrying %s %s: its body can't be sent again", req.Method, req.URL)
+		return t.transport.RoundTrip(req)
+	}
+
+	start := time.Now()
+	backoff := retryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.transport.RoundTrip(req)
+		reason, retryable := t.retryReason(req, resp, err)
+		if reason == "" {
+			return resp, err
+		}
+		if !retryable {
+			log.Printf("[DEBUG] Not retrying %s %s: %s", req.Method, req.URL, reason)
+			return resp, err
+		}
+		if attempt >= t.attempts {
+			log.Printf("[DEBUG] Not retrying %s %s: %s, after %d attempts", req.Method, req.URL, reason, attempt)
+			return resp, err
+		}
+
+		wait := backoff + time.Duration(rand.Int63n(int64(backoff)))/2
+		if wait > t.maxBackoff {
+			wait = t.maxBackoff
+		}
+		if retryAfter, ok := getRetryAfter(resp); ok {
+			wait = retryAfter
+		}
+		if time.Since(start)+wait > t.maxElapsed {
+			log.Printf("[DEBUG] Not retrying %s %s: %s, waiting %s would take longer than %s", req.Method, req.URL, reason, wait, t.maxElapsed)
+			return resp, err
+		}
+
+		log.Printf("[DEBUG] Retrying %s %s in %s, attempt %d of %d: %s", req.Method, req.URL, wait, attempt+1, t.attempts, reason)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if backoff < t.maxBackoff {
+			backoff *= 2
+		}
+	}
+}
+
+// retryReason describes why a request failed, and whether it is retried. The
+// reason is empty for requests which succeeded.
+//
+// The service refuses requests which are rate limited, and requests it is too
+// busy for when it says when to retry them, so those are retried whatever
+// their method. Other failures are ambiguous: a request which isn't
+// idempotent may have been applied before the connection broke or the
+// server failed, and sending it again could create a duplicate.
+func (t *retryTransport) retryReason(req *http.Request, resp *http.Response, err error) (string, bool) {
+	var reason string
+	if err != nil {
+		reason = fmt.Sprintf("network error: %s", err)
+	} else {
+		if resp.StatusCode < http.StatusBadRequest {
+			return "", false
+		}
+		reason = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		if !t.statusCodes[resp.StatusCode] {
+			return reason + " is not retryable", false
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return reason, true
+		}
+		if _, ok := getRetryAfter(resp); ok && resp.StatusCode == http.StatusServiceUnavailable {
+			return reason + " with Retry-After", true
+		}
+	}
+
+	if !isIdempotent(req) {
+		return fmt.Sprintf("%s, and %s requests are not idempotent", reason, req.Method), false
+	}
+	return reason, true
+}
+
+// getRetryAfter returns how long a response asks the client to wait before
+// retrying, given in seconds or as an HTTP date.
+func getRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// isRewindable returns whether the body of a request can be sent again. The
+// bodies which the client libraries build from a byte slice or string can be,
+// streamed bodies, such as the content of a file, can't.
+func isRewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// isIdempotent returns whether sending a request more than once has the same
+// effect as sending it once.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
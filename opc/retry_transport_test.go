@@ -0,0 +1,241 @@
+package opc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRetryServer fails the first n requests with the given status code, or
+// by closing the connection when the status code is 0.
+func testRetryServer(t *testing.T, n, statusCode int, header http.Header) (*httptest.Server, func() int) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		fail := requests <= n
+		mu.Unlock()
+
+		if !fail {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if statusCode == 0 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(statusCode)
+	}))
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestRetryTransport_statusCodes(t *testing.T) {
+	policy := RetryPolicy{
+		MaxBackoff: 10 * time.Millisecond,
+	}
+
+	cases := []struct {
+		statusCode int
+		attempts   int
+		requests   int
+		want       int
+	}{
+		// Retried until it succeeds
+		{http.StatusServiceUnavailable, 3, 3, http.StatusOK},
+		// Retried up to the number of attempts
+		{http.StatusServiceUnavailable, 2, 2, http.StatusServiceUnavailable},
+		// Not retried
+		{http.StatusConflict, 3, 1, http.StatusConflict},
+		{http.StatusBadRequest, 3, 1, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		server, requests := testRetryServer(t, 2, c.statusCode, nil)
+		client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, c.attempts, policy)}
+
+		req, err := http.NewRequest("PUT", server.URL, strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Error sending request: %s", err)
+		}
+		resp.Body.Close()
+		server.Close()
+
+		if resp.StatusCode != c.want {
+			t.Fatalf("Expected HTTP %d after HTTP %d, got HTTP %d", c.want, c.statusCode, resp.StatusCode)
+		}
+		if requests() != c.requests {
+			t.Fatalf("Expected %d requests after HTTP %d, got %d", c.requests, c.statusCode, requests())
+		}
+	}
+}
+
+func TestRetryTransport_retryAfter(t *testing.T) {
+	header := http.Header{"Retry-After": []string{"1"}}
+	server, requests := testRetryServer(t, 1, http.StatusTooManyRequests, header)
+	defer server.Close()
+
+	policy := RetryPolicy{
+		MaxBackoff: 10 * time.Millisecond,
+	}
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 2, policy)}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Error sending request: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || requests() != 2 {
+		t.Fatalf("Expected HTTP 200 after 2 requests, got HTTP %d after %d", resp.StatusCode, requests())
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Expected the retry to wait for the Retry-After header, waited %s", elapsed)
+	}
+
+	// Waiting longer than the max elapsed time gives up
+	server, requests = testRetryServer(t, 1, http.StatusTooManyRequests, header)
+	defer server.Close()
+	policy.MaxElapsedTime = 500 * time.Millisecond
+	client = &http.Client{Transport: newRetryTransport(http.DefaultTransport, 2, policy)}
+
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Error sending request: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests || requests() != 1 {
+		t.Fatalf("Expected HTTP 429 after 1 request, got HTTP %d after %d", resp.StatusCode, requests())
+	}
+}
+
+func TestRetryTransport_networkErrors(t *testing.T) {
+	policy := RetryPolicy{
+		MaxBackoff: 10 * time.Millisecond,
+	}
+
+	// Idempotent requests are retried
+	server, requests := testRetryServer(t, 1, 0, nil)
+	defer server.Close()
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 2, policy)}
+
+	req, err := http.NewRequest("DELETE", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected the DELETE to be retried, got %s", err)
+	}
+	resp.Body.Close()
+	if requests() != 2 {
+		t.Fatalf("Expected 2 requests, got %d", requests())
+	}
+
+	// Other requests may have been applied, and are not
+	server, requests = testRetryServer(t, 1, 0, nil)
+	defer server.Close()
+
+	if _, err := client.Post(server.URL, "text/plain", strings.NewReader("body")); err == nil {
+		t.Fatal("Expected the POST to fail")
+	}
+	if requests() != 1 {
+		t.Fatalf("Expected 1 request, got %d", requests())
+	}
+}
+
+func TestRetryTransport_refused(t *testing.T) {
+	policy := RetryPolicy{
+		MaxBackoff: 10 * time.Millisecond,
+	}
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 3, policy)}
+
+	// Requests which are rate limited, or which the service asks to send
+	// again later, weren't applied and are retried whatever their method
+	cases := []struct {
+		statusCode int
+		header     http.Header
+	}{
+		{http.StatusTooManyRequests, nil},
+		{http.StatusServiceUnavailable, http.Header{"Retry-After": []string{"0"}}},
+	}
+
+	for _, c := range cases {
+		server, requests := testRetryServer(t, 1, c.statusCode, c.header)
+
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatalf("Error sending request: %s", err)
+		}
+		resp.Body.Close()
+		server.Close()
+
+		if resp.StatusCode != http.StatusOK || requests() != 2 {
+			t.Fatalf("Expected HTTP 200 after HTTP %d and 2 requests, got HTTP %d after %d", c.statusCode, resp.StatusCode, requests())
+		}
+	}
+}
+
+func TestRetryTransport_notRetried(t *testing.T) {
+	policy := RetryPolicy{
+		MaxBackoff: 10 * time.Millisecond,
+	}
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 3, policy)}
+
+	// A POST may have been applied, and sending it again could create a
+	// duplicate
+	server, requests := testRetryServer(t, 1, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatalf("Error sending request: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || requests() != 1 {
+		t.Fatalf("Expected HTTP 503 after 1 request, got HTTP %d after %d", resp.StatusCode, requests())
+	}
+
+	// A streamed body can't be sent again, and isn't read into memory
+	server, requests = testRetryServer(t, 1, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	body, w := io.Pipe()
+	go func() {
+		w.Write([]byte("body"))
+		w.Close()
+	}()
+	req, err := http.NewRequest("PUT", server.URL, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Error sending request: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || requests() != 1 {
+		t.Fatalf("Expected HTTP 503 after 1 request, got HTTP %d after %d", resp.StatusCode, requests())
+	}
+}
@@ -0,0 +1,168 @@
+package opc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// apiSession is implemented by the clients which hold the session credentials
+// of an API, so that sessionTransport can share a single session between the
+// API's clients, and start a new one.
+type apiSession interface {
+	// credential returns the session credential a request is sent with, which
+	// is "" when it's left to the transport, and false for requests which
+	// aren't authenticated with the session, such as the authentication
+	// request itself.
+	credential(req *http.Request) (string, bool)
+	// setCredential replaces the session credential of a request.
+	setCredential(req *http.Request, credential string)
+	// startedCredential returns the credential of the session started by the
+	// response to an authentication request, or "" for any other response.
+	startedCredential(resp *http.Response) string
+	// authenticate starts a new session and returns its credential.
+	authenticate() (string, error)
+}
+
+// sessionTransport shares a single session between the clients of an API. It
+// starts a new session when a request fails with 401 Unauthorized, and
+// replays the request once with the new session.
+//
+// The go-oracle-terraform clients start their own session, and a new one once
+// it is 25 minutes old, and keep sending the credential they hold until then.
+// So the transport adopts the session started by any authentication response,
+// sends every request with the latest session, and sends requests without a
+// credential, such as those of the provider's own clients, with it too. Only
+// one new session is started for an expired credential, however many of the
+// provider's concurrent requests fail with it.
+type sessionTransport struct {
+	transport http.RoundTripper
+	session   apiSession
+
+	mu         sync.Mutex
+	current    string
+	refreshing *sessionRefresh
+}
+
+// sessionRefresh is a new session which is being started. Requests which fail
+// while it's started wait for it rather than authenticating themselves.
+type sessionRefresh struct {
+	done       chan struct{}
+	credential string
+	err        error
+}
+
+func newSessionTransport(transport http.RoundTripper, session apiSession) *sessionTransport {
+	return &sessionTransport{
+		transport: transport,
+		session:   session,
+	}
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sent, ok := t.session.credential(req)
+	if !ok {
+		resp, err := t.transport.RoundTrip(req)
+		if err == nil {
+			if started := t.session.startedCredential(resp); started != "" {
+				t.mu.Lock()
+				t.current = started
+				t.mu.Unlock()
+			}
+		}
+		return resp, err
+	}
+
+	credential := t.currentCredential(sent)
+	if credential == "" {
+		var err error
+		if credential, err = t.refresh(""); err != nil {
+			return nil, err
+		}
+	}
+	if credential != sent {
+		req = req.Clone(req.Context())
+		t.session.setCredential(req, credential)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	log.Printf("[DEBUG] %s %s failed with 401 Unauthorized, authenticating again", req.Method, req.URL)
+	fresh, err := t.refresh(credential)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// A request whose body can't be rewound is left to the caller to retry,
+	// later requests are sent with the new session
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	t.session.setCredential(retry, fresh)
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	return t.transport.RoundTrip(retry)
+}
+
+// currentCredential returns the credential of the latest session, or the
+// credential a request was sent with when no session has been started yet.
+func (t *sessionTransport) currentCredential(credential string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != "" {
+		return t.current
+	}
+	return credential
+}
+
+// refresh starts a new session to replace an expired credential, or the first
+// session when expired is "", unless another request has already replaced it.
+// Concurrent requests wait for the new session rather than authenticating
+// themselves, and other requests aren't held up while it's started.
+func (t *sessionTransport) refresh(expired string) (string, error) {
+	t.mu.Lock()
+	if t.current != "" && t.current != expired {
+		current := t.current
+		t.mu.Unlock()
+		return current, nil
+	}
+	if r := t.refreshing; r != nil {
+		t.mu.Unlock()
+		<-r.done
+		return r.credential, r.err
+	}
+	r := &sessionRefresh{done: make(chan struct{})}
+	t.refreshing = r
+	t.mu.Unlock()
+
+	r.credential, r.err = t.session.authenticate()
+	if r.err != nil && expired != "" {
+		r.err = fmt.Errorf("Error authenticating again after a request failed with 401 Unauthorized: %s", r.err)
+	}
+
+	t.mu.Lock()
+	t.refreshing = nil
+	if r.err == nil {
+		t.current = r.credential
+	}
+	t.mu.Unlock()
+	close(r.done)
+	return r.credential, r.err
+}
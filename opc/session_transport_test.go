@@ -0,0 +1,172 @@
+package opc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSession authenticates with the token "fresh" once release is closed.
+// Other clients authenticate with GET /auth.
+type testSession struct {
+	mu      sync.Mutex
+	tokens  int
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *testSession) credential(req *http.Request) (string, bool) {
+	return req.Header.Get("X-Token"), req.URL.Path != "/auth"
+}
+
+func (s *testSession) setCredential(req *http.Request, credential string) {
+	req.Header.Set("X-Token", credential)
+}
+
+func (s *testSession) startedCredential(resp *http.Response) string {
+	if resp.Request.URL.Path != "/auth" {
+		return ""
+	}
+	return resp.Header.Get("X-Token")
+}
+
+func (s *testSession) authenticate() (string, error) {
+	s.once.Do(func() { close(s.started) })
+	<-s.release
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens++
+	return "fresh", nil
+}
+
+func TestSessionTransport_refresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("X-Token") {
+		case "fresh", "other":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	session := &testSession{started: make(chan struct{}), release: make(chan struct{})}
+	client := &http.Client{Transport: newSessionTransport(http.DefaultTransport, session)}
+
+	send := func(token string) (int, error) {
+		req, err := http.NewRequest("GET", server.URL, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("X-Token", token)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// Concurrent requests with the expired token wait for a single new session
+	var wg sync.WaitGroup
+	codes := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			code, err := send("expired")
+			if err != nil {
+				t.Error(err)
+			}
+			codes <- code
+		}()
+	}
+
+	// Other requests aren't held up while the session is started
+	<-session.started
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if code, err := send("other"); err != nil || code != http.StatusOK {
+			t.Errorf("Expected 200 while authenticating, got %d: %v", code, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Request was blocked while authenticating")
+	}
+
+	close(session.release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("Expected 200 after authenticating again, got %d", code)
+		}
+	}
+	if session.tokens != 1 {
+		t.Fatalf("Expected 1 authentication, got %d", session.tokens)
+	}
+
+	// Later requests with the expired token are sent with the new session
+	if code, err := send("expired"); err != nil || code != http.StatusOK {
+		t.Fatalf("Expected 200 with the new session, got %d: %v", code, err)
+	}
+	if session.tokens != 1 {
+		t.Fatalf("Expected 1 authentication, got %d", session.tokens)
+	}
+}
+
+func TestSessionTransport_shared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/auth":
+			w.Header().Set("X-Token", "started")
+			w.WriteHeader(http.StatusOK)
+		case r.Header.Get("X-Token") == "started":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	session := &testSession{started: make(chan struct{}), release: make(chan struct{})}
+	client := &http.Client{Transport: newSessionTransport(http.DefaultTransport, session)}
+
+	send := func(path, token string) (int, error) {
+		req, err := http.NewRequest("GET", server.URL+path, nil)
+		if err != nil {
+			return 0, err
+		}
+		if token != "" {
+			req.Header.Set("X-Token", token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+
+	// The session started by another client is adopted
+	if code, err := send("/auth", ""); err != nil || code != http.StatusOK {
+		t.Fatalf("Expected 200 authenticating, got %d: %v", code, err)
+	}
+
+	// Requests with the other client's old token, or without one, are sent
+	// with the adopted session
+	for _, token := range []string{"old", ""} {
+		if code, err := send("/", token); err != nil || code != http.StatusOK {
+			t.Fatalf("Expected 200 with the adopted session for token %q, got %d: %v", token, code, err)
+		}
+	}
+	if session.tokens != 0 {
+		t.Fatalf("Expected no new sessions, got %d", session.tokens)
+	}
+}
@@ -0,0 +1,25 @@
+package opc
+
+import (
+	"context"
+	"net/http"
+)
+
+// stopTransport binds requests to the provider's stop context, so that they
+// are cancelled when Terraform is stopped. The client libraries build their
+// requests without a context. Requests which already have a cancellable
+// context are sent as is.
+type stopTransport struct {
+	transport   http.RoundTripper
+	stopContext context.Context
+}
+
+func (t *stopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.stopContext.Err(); err != nil {
+		return nil, err
+	}
+	if req.Context().Done() == nil {
+		req = req.WithContext(t.stopContext)
+	}
+	return t.transport.RoundTrip(req)
+}
@@ -0,0 +1,46 @@
+package opc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStopTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &http.Client{Transport: &stopTransport{transport: http.DefaultTransport, stopContext: ctx}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Requests in flight are cancelled when Terraform is stopped
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := client.Get(server.URL + "/slow"); err == nil {
+		t.Fatal("Expected the request to be cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Expected the request to be cancelled as soon as Terraform was stopped, took %s", elapsed)
+	}
+
+	// and later requests aren't sent
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("Expected requests after Terraform was stopped to fail")
+	}
+}
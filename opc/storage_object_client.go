@@ -0,0 +1,470 @@
+package opc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-oracle-terraform/client"
+	"github.com/hashicorp/go-oracle-terraform/opc"
+)
+
+const (
+	// storageObjectSegmentUploads is the number of segments of a large object
+	// which are uploaded concurrently.
+	storageObjectSegmentUploads = 4
+)
+
+// storageObjectClient makes the object requests which storage.ObjectClient
+// does not support, such as POSTing object metadata. It shares the provider's
+// HTTP configuration and authenticates with the same credentials.
+type storageObjectClient struct {
+	client *client.Client
+
+	mu          sync.Mutex
+	authToken   string
+	tokenIssued time.Time
+}
+
+func newStorageObjectClient(c *opc.Config) (*storageObjectClient, error) {
+	apiClient, err := client.NewClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return &storageObjectClient{client: apiClient}, nil
+}
+
+// objectPath returns the request path of an object, e.g.
+// /v1/Storage-acme/container/object
+func (c *storageObjectClient) objectPath(container, name string) string {
+	return fmt.Sprintf("/v1/Storage-%s/%s/%s", *c.client.IdentityDomain, container, name)
+}
+
+// updateObjectMetadata POSTs the given headers to an object. The POST replaces
+// all of the object's metadata, so headers must hold the complete set.
+func (c *storageObjectClient) updateObjectMetadata(container, name string, headers map[string]string) error {
+	_, err := c.executeRequest("POST", c.objectPath(container, name), headers, nil)
+	return err
+}
+
+// putObject PUTs an object with the given headers.
+func (c *storageObjectClient) putObject(container, name string, headers map[string]string, body io.ReadSeeker) error {
+	_, err := c.executeRequest("PUT", c.objectPath(container, name), headers, body)
+	return err
+}
+
+// deleteObject deletes an object, ignoring objects which no longer exist.
+func (c *storageObjectClient) deleteObject(container, name string) error {
+	_, err := c.executeRequest("DELETE", c.objectPath(container, name), nil, nil)
+	if err != nil && !wasNotFoundError(err) {
+		return err
+	}
+	return nil
+}
+
+// createContainer creates a container if it doesn't exist. An existing
+// container is left as it is.
+func (c *storageObjectClient) createContainer(name string) error {
+	path := fmt.Sprintf("/v1/Storage-%s/%s", *c.client.IdentityDomain, name)
+	_, err := c.executeRequest("PUT", path, nil, nil)
+	return err
+}
+
+// listObjects returns the names of the objects in a container which start
+// with prefix, following the listing markers past the per request limit.
+func (c *storageObjectClient) listObjects(container, prefix string) ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		query := url.Values{}
+		query.Set("prefix", prefix)
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		path := fmt.Sprintf("/v1/Storage-%s/%s?%s", *c.client.IdentityDomain, container, query.Encode())
+		resp, err := c.executeRequest("GET", path, nil, nil)
+		if err != nil {
+			if wasNotFoundError(err) {
+				return names, nil
+			}
+			return nil, err
+		}
+
+		count := 0
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if name := scanner.Text(); name != "" {
+				names = append(names, name)
+				marker = name
+				count++
+			}
+		}
+		resp.Body.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return names, nil
+		}
+	}
+}
+
+// getLargeObjectSegments returns the segments of a dynamic or static large
+// object, as container/object paths. Objects which aren't segmented have
+// none.
+func (c *storageObjectClient) getLargeObjectSegments(container, name string) ([]string, error) {
+	resp, err := c.executeRequest("HEAD", c.objectPath(container, name), nil, nil)
+	if err != nil {
+		if wasNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if manifest := resp.Header.Get("X-Object-Manifest"); manifest != "" {
+		parts := strings.SplitN(manifest, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid object manifest %q", manifest)
+		}
+		names, err := c.listObjects(parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+		segments := make([]string, len(names))
+		for i, name := range names {
+			segments[i] = fmt.Sprintf("%s/%s", parts[0], name)
+		}
+		return segments, nil
+	}
+
+	if !strings.EqualFold(resp.Header.Get("X-Static-Large-Object"), "true") {
+		return nil, nil
+	}
+
+	resp, err = c.executeRequest("GET", c.objectPath(container, name)+"?multipart-manifest=get", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var manifest []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("Error decoding manifest of static large object %s/%s: %s", container, name, err)
+	}
+	segments := make([]string, len(manifest))
+	for i, segment := range manifest {
+		segments[i] = strings.TrimPrefix(segment.Name, "/")
+	}
+	return segments, nil
+}
+
+// deleteSegments deletes the given container/object paths.
+func (c *storageObjectClient) deleteSegments(segments []string) error {
+	for _, segment := range segments {
+		parts := strings.SplitN(segment, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if err := c.deleteObject(parts[0], parts[1]); err != nil {
+			return fmt.Errorf("Error deleting segment %s: %s", segment, err)
+		}
+	}
+	return nil
+}
+
+// largeObjectUploadInput describes the upload of a file as the segments of a
+// dynamic or static large object.
+type largeObjectUploadInput struct {
+	Container        string
+	Name             string
+	Headers          map[string]string
+	SegmentContainer string
+	SegmentSize      int64
+	Static           bool
+
+	File io.ReaderAt
+	Size int64
+}
+
+// staticLargeObjectSegment is an entry of a static large object manifest.
+type staticLargeObjectSegment struct {
+	Path      string `json:"path"`
+	ETag      string `json:"etag"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// uploadLargeObject uploads a file in segments, storageObjectSegmentUploads at
+// a time, and then writes the manifest object which joins them together. The
+// segments are named <name>/<upload time>/<size>/<segment size>/<index>, so
+// the segments of an earlier upload of the same object are left untouched. If
+// a segment fails, or ctx is cancelled, the other uploads are cancelled and
+// the segments uploaded so far are deleted.
+func (c *storageObjectClient) uploadLargeObject(ctx context.Context, input *largeObjectUploadInput) error {
+	if err := c.createContainer(input.SegmentContainer); err != nil {
+		return fmt.Errorf("Error creating segment container %s: %s", input.SegmentContainer, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s/%d/%d/%d/", input.Name, time.Now().UnixNano(), input.Size, input.SegmentSize)
+	count := int((input.Size + input.SegmentSize - 1) / input.SegmentSize)
+	segments := make([]staticLargeObjectSegment, count)
+	uploaded := make([]bool, count)
+
+	var once sync.Once
+	var uploadErr error
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < storageObjectSegmentUploads; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				offset := int64(i) * input.SegmentSize
+				length := input.SegmentSize
+				if offset+length > input.Size {
+					length = input.Size - offset
+				}
+				name := fmt.Sprintf("%s%08d", prefix, i)
+				etag, err := c.uploadSegment(ctx, input.SegmentContainer, name, io.NewSectionReader(input.File, offset, length))
+				if err != nil {
+					once.Do(func() {
+						uploadErr = err
+						cancel()
+					})
+					continue
+				}
+				segments[i] = staticLargeObjectSegment{
+					Path:      fmt.Sprintf("/%s/%s", input.SegmentContainer, name),
+					ETag:      etag,
+					SizeBytes: length,
+				}
+				uploaded[i] = true
+			}
+		}()
+	}
+feed:
+	for i := 0; i < count; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if uploadErr == nil && ctx.Err() != nil {
+		uploadErr = ctx.Err()
+	}
+	if uploadErr == nil {
+		uploadErr = c.putLargeObjectManifest(input, prefix, segments)
+	}
+	if uploadErr != nil {
+		paths := make([]string, 0, count)
+		for i, segment := range segments {
+			if uploaded[i] {
+				paths = append(paths, strings.TrimPrefix(segment.Path, "/"))
+			}
+		}
+		if err := c.deleteSegments(paths); err != nil {
+			log.Printf("[WARN] Error removing the segments of failed upload %s/%s: %s", input.Container, input.Name, err)
+		}
+		return uploadErr
+	}
+	return nil
+}
+
+// uploadSegment PUTs a single segment and returns its etag. The MD5 checksum
+// is sent with the segment so corrupted uploads are rejected. Failed uploads
+// are retried by the provider's retry policy.
+func (c *storageObjectClient) uploadSegment(ctx context.Context, container, name string, body *io.SectionReader) (string, error) {
+	hash := md5.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return "", fmt.Errorf("Error reading segment %s: %s", name, err)
+	}
+	etag := hex.EncodeToString(hash.Sum(nil))
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	headers := map[string]string{
+		"Etag": etag,
+	}
+	if _, err := c.executeRequestWithContext(ctx, "PUT", c.objectPath(container, name), headers, body); err != nil {
+		return "", fmt.Errorf("Error uploading segment %s/%s: %s", container, name, err)
+	}
+	return etag, nil
+}
+
+// putLargeObjectManifest writes the manifest object of an uploaded large
+// object. Dynamic large objects reference their segments by prefix, static
+// large objects list each segment along with its etag and size.
+func (c *storageObjectClient) putLargeObjectManifest(input *largeObjectUploadInput, prefix string, segments []staticLargeObjectSegment) error {
+	headers := make(map[string]string)
+	for k, v := range input.Headers {
+		headers[k] = v
+	}
+
+	path := c.objectPath(input.Container, input.Name)
+	var body io.ReadSeeker
+	if input.Static {
+		manifest, err := json.Marshal(segments)
+		if err != nil {
+			return err
+		}
+		path += "?multipart-manifest=put"
+		body = bytes.NewReader(manifest)
+	} else {
+		headers["X-Object-Manifest"] = fmt.Sprintf("%s/%s", input.SegmentContainer, prefix)
+		body = bytes.NewReader([]byte{})
+	}
+
+	if _, err := c.executeRequest("PUT", path, headers, body); err != nil {
+		return fmt.Errorf("Error writing manifest of %s/%s: %s", input.Container, input.Name, err)
+	}
+	return nil
+}
+
+// getLargeObjectETag returns the etag of a large object from the MD5
+// checksums of its segments, which is the MD5 checksum of the concatenated
+// checksums, quoted.
+func getLargeObjectETag(segmentETags []string) string {
+	sum := md5.Sum([]byte(strings.Join(segmentETags, "")))
+	return fmt.Sprintf("%q", hex.EncodeToString(sum[:]))
+}
+
+func (c *storageObjectClient) executeRequest(method, path string, headers map[string]string, body io.ReadSeeker) (*http.Response, error) {
+	return c.executeRequestWithContext(context.Background(), method, path, headers, body)
+}
+
+// executeRequestWithContext sends a request which is cancelled along with
+// ctx. The body is rewound when the request is retried.
+func (c *storageObjectClient) executeRequestWithContext(ctx context.Context, method, path string, headers map[string]string, body io.ReadSeeker) (*http.Response, error) {
+	req, err := c.client.BuildNonJSONRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if body != nil && req.GetBody == nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			if _, err := body.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return ioutil.NopCloser(body), nil
+		}
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if strings.HasPrefix(path, "/auth/") {
+		return c.client.ExecuteRequest(req)
+	}
+
+	token, err := c.getAuthenticationToken()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Auth-Token", token)
+	c.client.DebugLogString(fmt.Sprintf("%s (%s) %s", req.Method, req.URL, req.Proto))
+
+	resp, err := c.client.ExecuteRequest(req)
+
+	// sessionTransport can't replay uploads from a file, but it has already
+	// started a new session, so rewind the file and send it once more
+	if oracleErr, ok := err.(*opc.OracleError); ok && oracleErr.StatusCode == http.StatusUnauthorized && body != nil {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return resp, oracleErr
+		}
+		req, err := c.client.BuildNonJSONRequest(method, path, body)
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		token, err := c.getAuthenticationToken()
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Auth-Token", token)
+		return c.client.ExecuteRequest(req)
+	}
+	return resp, err
+}
+
+// getAuthenticationToken returns the auth token, authenticating when there is
+// none or it is close to the 30 minute token expiry.
+func (c *storageObjectClient) getAuthenticationToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.authToken != "" && time.Since(c.tokenIssued).Minutes() <= 25 {
+		return c.authToken, nil
+	}
+	return c.newAuthenticationToken()
+}
+
+// newAuthenticationToken requests a new auth token. c.mu must be held.
+func (c *storageObjectClient) newAuthenticationToken() (string, error) {
+	headers := map[string]string{
+		"X-Storage-User": fmt.Sprintf("Storage-%s:%s", *c.client.IdentityDomain, *c.client.UserName),
+		"X-Storage-Pass": *c.client.Password,
+	}
+	resp, err := c.executeRequest("GET", "/auth/v1.0", headers, nil)
+	if err != nil {
+		return "", err
+	}
+
+	token := resp.Header.Get("X-Auth-Token")
+	if token == "" {
+		return "", fmt.Errorf("No authentication token found in response %#v", resp)
+	}
+
+	c.authToken = token
+	c.tokenIssued = time.Now()
+	return c.authToken, nil
+}
+
+// The storage clients authenticate every request with the auth token,
+// storageObjectClient holds the token for sessionTransport.
+
+func (c *storageObjectClient) credential(req *http.Request) (string, bool) {
+	token := req.Header.Get("X-Auth-Token")
+	return token, token != ""
+}
+
+func (c *storageObjectClient) setCredential(req *http.Request, credential string) {
+	req.Header.Set("X-Auth-Token", credential)
+}
+
+func (c *storageObjectClient) startedCredential(resp *http.Response) string {
+	if resp.StatusCode >= 300 {
+		return ""
+	}
+	return resp.Header.Get("X-Auth-Token")
+}
+
+func (c *storageObjectClient) authenticate() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.newAuthenticationToken()
+}
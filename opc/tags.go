@@ -1,6 +1,12 @@
 package opc
 
-import "github.com/hashicorp/terraform/helper/schema"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
 
 func tagsOptionalSchema() *schema.Schema {
 	return &schema.Schema{
@@ -26,3 +32,259 @@ func tagsComputedSchema() *schema.Schema {
 		Elem:     &schema.Schema{Type: schema.TypeString},
 	}
 }
+
+// tagsMapSchema holds tags as a map. Each entry is stored as a key=value tag,
+// as Compute Classic tags are plain strings.
+func tagsMapSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeMap,
+		Optional:     true,
+		Elem:         &schema.Schema{Type: schema.TypeString},
+		ValidateFunc: validateTagsMap,
+	}
+}
+
+// tagsAllSchema holds every tag of a resource, including the provider's
+// default_tags and any tags ignored by the provider's ignore_tags_prefixes.
+func tagsAllSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+// expandTags returns the tags to create or update a resource with: its own
+// tags and tags_map, the provider's default_tags, and the tags it already has
+// which are ignored, so that the tags added by other tooling are kept.
+func expandTags(d *schema.ResourceData, meta interface{}) []string {
+	tagsAll, _ := d.GetChange("tags_all")
+	return mergeTags(meta.(*Client), joinTags(d.Get("tags"), d.Get("tags_map")), getTagsValue(tagsAll))
+}
+
+// flattenTags sets the tags, tags_map and tags_all of a resource from the
+// tags it has. The tags exclude those which aren't part of its configuration,
+// but which come from the provider's default_tags, or which were added by the
+// provider before, or which are ignored. Default tags which are removed from
+// the provider configuration therefore don't show up as a change in the tags
+// of the resources which have them.
+//
+// A key=value tag is part of the tags_map, unless it is in the tags, and
+// every other tag is part of the tags. The tags_map only holds one value for
+// each key, so any further values are left in the tags.
+func flattenTags(d *schema.ResourceData, meta interface{}, tags []string) error {
+	client := meta.(*Client)
+
+	configured := make(map[string]bool)
+	for _, tag := range getTagsValue(d.Get("tags")) {
+		configured[tag] = true
+	}
+	configuredMap := make(map[string]bool)
+	for _, tag := range joinTags(nil, d.Get("tags_map")) {
+		configuredMap[tag] = true
+	}
+	managed := make(map[string]bool)
+	for _, tag := range getTagsValue(d.Get("tags_all")) {
+		managed[tag] = !configured[tag] && !configuredMap[tag]
+	}
+	for _, tag := range client.defaultTags {
+		managed[tag] = !configured[tag] && !configuredMap[tag]
+	}
+
+	result := make([]string, 0, len(tags))
+	resultMap := make(map[string]string)
+	// The values in the configuration take the keys of the tags_map first
+	for _, tag := range tags {
+		if key, value, ok := parseMapTag(tag); ok && configuredMap[tag] {
+			resultMap[key] = value
+		}
+	}
+	for _, tag := range tags {
+		if configuredMap[tag] && !configured[tag] {
+			continue
+		}
+		if !configured[tag] && (managed[tag] || client.isIgnoredTag(tag)) {
+			continue
+		}
+		key, value, ok := parseMapTag(tag)
+		if _, exists := resultMap[key]; ok && !exists && !configured[tag] {
+			resultMap[key] = value
+			continue
+		}
+		result = append(result, tag)
+	}
+
+	if err := setStringList(d, "tags", result); err != nil {
+		return err
+	}
+	if err := d.Set("tags_map", resultMap); err != nil {
+		return err
+	}
+	return setStringList(d, "tags_all", append([]string{}, tags...))
+}
+
+// customizeDiffTagsAll plans an update of tags_all when the tags of a
+// resource and the provider's default_tags add up to something different, so
+// that changes to the default_tags are applied to the existing resources.
+// It is only used by resources whose tags can be updated in place.
+func customizeDiffTagsAll(diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() == "" {
+		return nil
+	}
+	if !diff.NewValueKnown("tags") || !diff.NewValueKnown("tags_map") {
+		return diff.SetNewComputed("tags_all")
+	}
+
+	tagsAll, _ := diff.GetChange("tags_all")
+	current := getTagsValue(tagsAll)
+	desired := mergeTags(meta.(*Client), joinTags(diff.Get("tags"), diff.Get("tags_map")), current)
+	if !equalTags(current, desired) {
+		return diff.SetNew("tags_all", desired)
+	}
+	return nil
+}
+
+// customizeDiffTagsForceNew replaces a resource whose tags can't be updated
+// in place when its tags change, but not when tags are only moved between its
+// tags and tags_map, as the tags it has stay the same.
+func customizeDiffTagsForceNew(diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() == "" || (!diff.HasChange("tags") && !diff.HasChange("tags_map")) {
+		return nil
+	}
+
+	if diff.NewValueKnown("tags") && diff.NewValueKnown("tags_map") {
+		oldTags, newTags := diff.GetChange("tags")
+		oldTagsMap, newTagsMap := diff.GetChange("tags_map")
+		if equalTags(uniqueTags(joinTags(oldTags, oldTagsMap)), uniqueTags(joinTags(newTags, newTagsMap))) {
+			return nil
+		}
+	}
+
+	for _, key := range []string{"tags", "tags_map"} {
+		if diff.HasChange(key) {
+			if err := diff.ForceNew(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeTags returns the union of the tags, the provider's default_tags and
+// the ignored tags of a resource's current tags, sorted.
+func mergeTags(client *Client, tags, current []string) []string {
+	merged := make([]string, 0, len(tags)+len(client.defaultTags))
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+
+	for _, tag := range tags {
+		add(tag)
+	}
+	for _, tag := range client.defaultTags {
+		add(tag)
+	}
+	for _, tag := range current {
+		if client.isIgnoredTag(tag) {
+			add(tag)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// isIgnoredTag reports whether a tag starts with one of the provider's
+// ignore_tags_prefixes.
+func (c *Client) isIgnoredTag(tag string) bool {
+	for _, prefix := range c.ignoreTagsPrefixes {
+		if strings.HasPrefix(tag, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTagsValue converts the value of a tags list or set to strings.
+func getTagsValue(v interface{}) []string {
+	var values []interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		values = v
+	case *schema.Set:
+		values = v.List()
+	}
+
+	tags := make([]string, 0, len(values))
+	for _, value := range values {
+		if tag, ok := value.(string); ok && tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// joinTags returns the values of a tags list or set, and the entries of a
+// tags_map as key=value tags.
+func joinTags(tags, tagsMap interface{}) []string {
+	result := getTagsValue(tags)
+	if m, ok := tagsMap.(map[string]interface{}); ok {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			result = append(result, fmt.Sprintf("%s=%v", key, m[key]))
+		}
+	}
+	return result
+}
+
+// parseMapTag splits a key=value tag into its key and value.
+func parseMapTag(tag string) (string, string, bool) {
+	parts := strings.SplitN(tag, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func validateTagsMap(v interface{}, k string) (ws []string, errors []error) {
+	for key := range v.(map[string]interface{}) {
+		if key == "" || strings.Contains(key, "=") {
+			errors = append(errors, fmt.Errorf("%q keys must not be empty or contain '=', got %q", k, key))
+		}
+	}
+	return
+}
+
+func uniqueTags(tags []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
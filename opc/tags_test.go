@@ -0,0 +1,165 @@
+package opc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestMergeTags(t *testing.T) {
+	client := &Client{
+		defaultTags:        []string{"env:test", "team:web"},
+		ignoreTagsPrefixes: []string{"oracle:"},
+	}
+
+	cases := []struct {
+		tags, current, expected []string
+	}{
+		{nil, nil, []string{"env:test", "team:web"}},
+		{[]string{"app", "env:test"}, nil, []string{"app", "env:test", "team:web"}},
+		// Ignored tags are kept, tags which aren't ignored are replaced
+		{[]string{"app"}, []string{"app", "old", "oracle:backup"}, []string{"app", "env:test", "oracle:backup", "team:web"}},
+	}
+
+	for _, c := range cases {
+		if merged := mergeTags(client, c.tags, c.current); !reflect.DeepEqual(merged, c.expected) {
+			t.Fatalf("Expected %v merged with %v to be %v, got %v", c.tags, c.current, c.expected, merged)
+		}
+	}
+}
+
+func TestFlattenTags(t *testing.T) {
+	resourceSchema := map[string]*schema.Schema{
+		"tags":     tagsOptionalSchema(),
+		"tags_map": tagsMapSchema(),
+		"tags_all": tagsAllSchema(),
+	}
+	client := &Client{
+		defaultTags:        []string{"env:test"},
+		ignoreTagsPrefixes: []string{"oracle:"},
+	}
+
+	cases := []struct {
+		name         string
+		state        map[string]string
+		remote       []string
+		expectedTags []string
+		expectedMap  map[string]interface{}
+	}{
+		{
+			name:         "created",
+			state:        map[string]string{"tags.#": "1", "tags.0": "app"},
+			remote:       []string{"app", "env:test"},
+			expectedTags: []string{"app"},
+		},
+		{
+			name:         "ignored tag added",
+			state:        map[string]string{"tags.#": "1", "tags.0": "app", "tags_all.#": "2", "tags_all.0": "app", "tags_all.1": "env:test"},
+			remote:       []string{"app", "env:test", "oracle:backup"},
+			expectedTags: []string{"app"},
+		},
+		{
+			name:         "tag added",
+			state:        map[string]string{"tags.#": "1", "tags.0": "app", "tags_all.#": "2", "tags_all.0": "app", "tags_all.1": "env:test"},
+			remote:       []string{"app", "env:test", "owner"},
+			expectedTags: []string{"app", "owner"},
+		},
+		{
+			name:         "default tag removed from the provider",
+			state:        map[string]string{"tags.#": "1", "tags.0": "app", "tags_all.#": "2", "tags_all.0": "app", "tags_all.1": "env:old"},
+			remote:       []string{"app", "env:old"},
+			expectedTags: []string{"app"},
+		},
+		{
+			name:         "default tag also configured",
+			state:        map[string]string{"tags.#": "1", "tags.0": "env:test"},
+			remote:       []string{"env:test"},
+			expectedTags: []string{"env:test"},
+		},
+		{
+			name:         "imported",
+			state:        map[string]string{},
+			remote:       []string{"app", "env:test", "oracle:backup"},
+			expectedTags: []string{"app"},
+		},
+		{
+			name:         "key=value tags",
+			state:        map[string]string{"tags.#": "1", "tags.0": "app", "tags_map.%": "1", "tags_map.owner": "jdoe"},
+			remote:       []string{"app", "env:test", "owner=jdoe", "team=web"},
+			expectedTags: []string{"app"},
+			expectedMap:  map[string]interface{}{"owner": "jdoe", "team": "web"},
+		},
+		{
+			name:         "key=value tags in the list",
+			state:        map[string]string{"tags.#": "2", "tags.0": "app", "tags.1": "owner=jdoe"},
+			remote:       []string{"app", "owner=jdoe"},
+			expectedTags: []string{"app", "owner=jdoe"},
+		},
+		{
+			name:         "several values for a key",
+			state:        map[string]string{"tags_map.%": "1", "tags_map.owner": "jdoe"},
+			remote:       []string{"owner=admin", "owner=jdoe"},
+			expectedTags: []string{"owner=admin"},
+			expectedMap:  map[string]interface{}{"owner": "jdoe"},
+		},
+	}
+
+	for _, c := range cases {
+		d, err := schema.InternalMap(resourceSchema).Data(&terraform.InstanceState{ID: "test", Attributes: c.state}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := flattenTags(d, client, c.remote); err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		if tags := getTagsValue(d.Get("tags")); !reflect.DeepEqual(tags, c.expectedTags) {
+			t.Fatalf("%s: expected tags %v, got %v", c.name, c.expectedTags, tags)
+		}
+		if tagsMap := d.Get("tags_map").(map[string]interface{}); len(tagsMap) != len(c.expectedMap) || (len(tagsMap) > 0 && !reflect.DeepEqual(tagsMap, c.expectedMap)) {
+			t.Fatalf("%s: expected tags_map %v, got %v", c.name, c.expectedMap, tagsMap)
+		}
+		if tagsAll := getTagsValue(d.Get("tags_all")); !reflect.DeepEqual(tagsAll, c.remote) {
+			t.Fatalf("%s: expected tags_all %v, got %v", c.name, c.remote, tagsAll)
+		}
+	}
+}
+
+func TestCustomizeDiffTagsForceNew(t *testing.T) {
+	resource := resourceOPCIPReservation()
+	state := &terraform.InstanceState{
+		ID: "test",
+		Attributes: map[string]string{
+			"id":          "test",
+			"parent_pool": "/oracle/public/ippool",
+			"permanent":   "true",
+			"tags.#":      "2",
+			"tags.0":      "app",
+			"tags.1":      "owner=jdoe",
+		},
+	}
+
+	cases := []struct {
+		config      map[string]interface{}
+		requiresNew bool
+	}{
+		// Moved to the tags_map
+		{map[string]interface{}{"tags": []interface{}{"app"}, "tags_map": map[string]interface{}{"owner": "jdoe"}}, false},
+		{map[string]interface{}{"tags": []interface{}{"app"}, "tags_map": map[string]interface{}{"owner": "admin"}}, true},
+		{map[string]interface{}{"tags": []interface{}{"app", "owner=jdoe", "team"}}, true},
+	}
+
+	for _, c := range cases {
+		c.config["parent_pool"] = "/oracle/public/ippool"
+		c.config["permanent"] = true
+		diff, err := resource.Diff(state, terraform.NewResourceConfigRaw(c.config), &Client{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff.RequiresNew() != c.requiresNew {
+			t.Fatalf("Expected %v to require a new resource: %t, got %#v", c.config, c.requiresNew, diff)
+		}
+	}
+}
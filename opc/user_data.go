@@ -0,0 +1,185 @@
+package opc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+const (
+	// instanceUserDataAttribute is the launch plan attribute which holds the
+	// user data of an instance, served to it by the metadata service
+	instanceUserDataAttribute = "userdata"
+	// instanceUserDataKey is the key of the userdata which holds the
+	// cloud-config or script that cloud-init runs when the instance boots
+	instanceUserDataKey = "user_data"
+)
+
+// expandInstanceAttributes parses the instance_attributes of an instance and
+// merges its user_data and metadata into the userdata attribute. It is an
+// error to set the same userdata key more than once.
+func expandInstanceAttributes(instanceAttributes, userData string, gzipUserData bool, metadata map[string]interface{}) (map[string]interface{}, error) {
+	var attrs map[string]interface{}
+	if instanceAttributes != "" {
+		if err := json.Unmarshal([]byte(instanceAttributes), &attrs); err != nil {
+			return nil, fmt.Errorf("Cannot parse attributes as json: %s", err)
+		}
+	}
+	if userData == "" && len(metadata) == 0 {
+		return attrs, nil
+	}
+
+	if attrs == nil {
+		attrs = make(map[string]interface{})
+	}
+	userdata := make(map[string]interface{})
+	if v, ok := attrs[instanceUserDataAttribute]; ok && v != nil {
+		existing, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("The %q of instance_attributes must be a JSON object to be merged with user_data and metadata", instanceUserDataAttribute)
+		}
+		for key, value := range existing {
+			userdata[key] = value
+		}
+	}
+
+	if userData != "" {
+		if _, ok := metadata[instanceUserDataKey]; ok {
+			return nil, fmt.Errorf("metadata key %q conflicts with user_data", instanceUserDataKey)
+		}
+		if _, ok := userdata[instanceUserDataKey]; ok {
+			return nil, fmt.Errorf("user_data conflicts with the %q key of the %q in instance_attributes", instanceUserDataKey, instanceUserDataAttribute)
+		}
+		encoded, err := encodeUserData(userData, gzipUserData)
+		if err != nil {
+			return nil, err
+		}
+		userdata[instanceUserDataKey] = encoded
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := userdata[key]; ok {
+			return nil, fmt.Errorf("metadata key %q conflicts with the same key of the %q in instance_attributes", key, instanceUserDataAttribute)
+		}
+		userdata[key] = metadata[key]
+	}
+
+	attrs[instanceUserDataAttribute] = userdata
+	return attrs, nil
+}
+
+// flattenInstanceUserData removes the user_data and metadata of an instance
+// from the userdata of its attributes, so that what's left can be read into
+// instance_attributes and merged with them again.
+func flattenInstanceUserData(attributes map[string]interface{}, userData string, metadata map[string]interface{}) map[string]interface{} {
+	userdata, ok := attributes[instanceUserDataAttribute].(map[string]interface{})
+	if !ok || (userData == "" && len(metadata) == 0) {
+		return attributes
+	}
+
+	remaining := make(map[string]interface{})
+	for key, value := range userdata {
+		if _, ok := metadata[key]; ok {
+			continue
+		}
+		if key == instanceUserDataKey && userData != "" {
+			continue
+		}
+		remaining[key] = value
+	}
+
+	result := make(map[string]interface{})
+	for key, value := range attributes {
+		result[key] = value
+	}
+	if len(remaining) > 0 {
+		result[instanceUserDataAttribute] = remaining
+	} else {
+		delete(result, instanceUserDataAttribute)
+	}
+	return result
+}
+
+// readInstanceUserData reads the user_data, gzip_user_data and metadata of an
+// instance back from the userdata of its attributes, given their values in its
+// state. When the instance has no instance_attributes, all of its userdata
+// came from its user_data and metadata, and is read into them. Otherwise only
+// the user_data and metadata keys it was created with are read, as the other
+// keys of its userdata belong to its instance_attributes.
+//
+// Compressed user_data is recognised when the instance is imported, and has
+// no user_data in its state yet.
+func readInstanceUserData(attributes map[string]interface{}, instanceAttributes, userData string, gzipUserData bool, metadata map[string]interface{}) (string, bool, map[string]interface{}) {
+	readAll := instanceAttributes == ""
+	readUserData := readAll || userData != ""
+	detectGzip := userData == ""
+
+	result := make(map[string]interface{})
+	userdata, _ := attributes[instanceUserDataAttribute].(map[string]interface{})
+	value, ok := userdata[instanceUserDataKey].(string)
+	switch {
+	case !ok || !readUserData:
+		userData, gzipUserData = "", false
+	case gzipUserData || detectGzip:
+		userData, gzipUserData = decodeUserData(value)
+	default:
+		userData = value
+	}
+
+	for key, value := range userdata {
+		s, ok := value.(string)
+		if !ok || key == instanceUserDataKey {
+			continue
+		}
+		if _, ok := metadata[key]; ok || readAll {
+			result[key] = s
+		}
+	}
+	return userData, gzipUserData, result
+}
+
+// decodeUserData reverses encodeUserData, and returns whether the user data
+// was compressed. User data which isn't base64 encoded gzip is returned as is.
+func decodeUserData(userData string) (string, bool) {
+	compressed, err := base64.StdEncoding.DecodeString(userData)
+	if err != nil {
+		return userData, false
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return userData, false
+	}
+	decoded, err := ioutil.ReadAll(r)
+	if err != nil {
+		return userData, false
+	}
+	return string(decoded), true
+}
+
+// encodeUserData compresses user data with gzip and encodes it with base64 if
+// asked to, which cloud-init decodes again. Large scripts fit within the size
+// limit of the launch plan attributes this way.
+func encodeUserData(userData string, gzipUserData bool) (string, error) {
+	if !gzipUserData {
+		return userData, nil
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(userData)); err != nil {
+		return "", fmt.Errorf("Error compressing user_data: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("Error compressing user_data: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
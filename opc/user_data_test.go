@@ -0,0 +1,158 @@
+package opc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandInstanceAttributes(t *testing.T) {
+	attrs, err := expandInstanceAttributes(
+		`{"userdata": {"role": "web"}, "enable_admin": true}`,
+		"#cloud-config\npackages: [httpd]\n",
+		false,
+		map[string]interface{}{"environment": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"enable_admin": true,
+		"userdata": map[string]interface{}{
+			"role":        "web",
+			"environment": "test",
+			"user_data":   "#cloud-config\npackages: [httpd]\n",
+		},
+	}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, attrs)
+	}
+
+	// Nothing is added without user_data or metadata
+	attrs, err = expandInstanceAttributes("", "", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs != nil {
+		t.Fatalf("Expected no attributes, got %#v", attrs)
+	}
+}
+
+func TestExpandInstanceAttributes_gzip(t *testing.T) {
+	script := "#!/bin/sh\n" + strings.Repeat("echo hello\n", 100)
+	attrs, err := expandInstanceAttributes("", script, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := attrs["userdata"].(map[string]interface{})["user_data"].(string)
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded) != script {
+		t.Fatalf("Expected the user_data to decode to %q, got %q", script, decoded)
+	}
+}
+
+func TestExpandInstanceAttributes_conflicts(t *testing.T) {
+	cases := []struct {
+		instanceAttributes string
+		userData           string
+		metadata           map[string]interface{}
+		expected           string
+	}{
+		{`{"userdata": {"user_data": "echo"}}`, "echo", nil, `user_data conflicts with the "user_data" key`},
+		{`{"userdata": {"role": "web"}}`, "", map[string]interface{}{"role": "db"}, `metadata key "role" conflicts`},
+		{"", "echo", map[string]interface{}{"user_data": "echo"}, `metadata key "user_data" conflicts with user_data`},
+		{`{"userdata": "echo"}`, "echo", nil, "must be a JSON object"},
+	}
+
+	for _, c := range cases {
+		_, err := expandInstanceAttributes(c.instanceAttributes, c.userData, false, c.metadata)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Fatalf("Expected an error containing %q, got %v", c.expected, err)
+		}
+	}
+}
+
+func TestFlattenInstanceUserData(t *testing.T) {
+	metadata := map[string]interface{}{"environment": "test"}
+	attrs, err := expandInstanceAttributes(`{"userdata": {"role": "web"}, "enable_admin": true}`, "echo", false, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"enable_admin": true,
+		"userdata":     map[string]interface{}{"role": "web"},
+	}
+	if flattened := flattenInstanceUserData(attrs, "echo", metadata); !reflect.DeepEqual(flattened, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, flattened)
+	}
+
+	// The userdata is left out when it only holds the user_data and metadata
+	attrs, err = expandInstanceAttributes("", "echo", false, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flattened := flattenInstanceUserData(attrs, "echo", metadata); len(flattened) != 0 {
+		t.Fatalf("Expected no attributes, got %#v", flattened)
+	}
+}
+
+func TestReadInstanceUserData(t *testing.T) {
+	script := "#!/bin/sh\necho hello\n"
+	metadata := map[string]interface{}{"environment": "test"}
+
+	cases := []struct {
+		instanceAttributes string
+		userData           string
+		gzipUserData       bool
+		metadata           map[string]interface{}
+		stateUserData      string
+		stateGzipUserData  bool
+		stateMetadata      map[string]interface{}
+	}{
+		// Read as created
+		{"", script, false, metadata, script, false, metadata},
+		{"", script, true, metadata, script, true, metadata},
+		// Imported, with the user_data decompressed
+		{"", script, true, metadata, "", false, nil},
+		{"", script, false, nil, "", false, nil},
+		// The userdata of the instance_attributes is left alone
+		{`{"userdata": {"role": "web"}}`, script, false, metadata, script, false, metadata},
+		{`{"userdata": {"role": "web", "user_data": "echo"}}`, "", false, metadata, "", false, metadata},
+	}
+
+	for _, c := range cases {
+		attrs, err := expandInstanceAttributes(c.instanceAttributes, c.userData, c.gzipUserData, c.metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		userData, gzipUserData, readMetadata := readInstanceUserData(attrs, c.instanceAttributes, c.stateUserData, c.stateGzipUserData, c.stateMetadata)
+		if userData != c.userData || gzipUserData != c.gzipUserData {
+			t.Fatalf("Expected user_data %q with gzip_user_data %t, got %q with %t", c.userData, c.gzipUserData, userData, gzipUserData)
+		}
+		expected := c.metadata
+		if expected == nil {
+			expected = map[string]interface{}{}
+		}
+		if !reflect.DeepEqual(readMetadata, expected) {
+			t.Fatalf("Expected metadata %#v, got %#v", expected, readMetadata)
+		}
+	}
+}
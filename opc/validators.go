@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"regexp"
+	"time"
 
 	"github.com/hashicorp/go-oracle-terraform/compute"
 )
@@ -132,3 +133,18 @@ func validateOriginServerPoolURI(v interface{}, k string) (ws []string, errors [
 	}
 	return
 }
+
+// Check the value is a positive duration, e.g. 30s or 5m
+func validateDuration(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%s is not a valid duration for %s: %s", value, k, err))
+		return
+	}
+	if duration <= 0 {
+		errors = append(errors, fmt.Errorf("%s must be a positive duration, got %s", k, value))
+	}
+	return
+}
@@ -279,3 +279,31 @@ func TestValidateOriginServerPoolURI(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDuration(t *testing.T) {
+	validDurations := []string{
+		"30s",
+		"5m",
+		"1h30m",
+	}
+
+	for _, v := range validDurations {
+		_, errors := validateDuration(v, "max_backoff")
+		if len(errors) != 0 {
+			t.Fatalf("%q should be a valid duration: %q", v, errors)
+		}
+	}
+
+	invalidDurations := []string{
+		"",
+		"30",
+		"0s",
+		"-5m",
+	}
+	for _, v := range invalidDurations {
+		_, errors := validateDuration(v, "max_backoff")
+		if len(errors) == 0 {
+			t.Fatalf("%q should not be a valid duration", v)
+		}
+	}
+}
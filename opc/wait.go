@@ -0,0 +1,101 @@
+package opc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+const (
+	defaultWaitMinPollInterval = 1 * time.Second
+	defaultWaitMaxPollInterval = 30 * time.Second
+
+	// waitStateDeleted is the state Refresh reports for a resource which no
+	// longer exists, when waiting for it to be deleted
+	waitStateDeleted = "deleted"
+)
+
+// waitConf describes a wait for a resource to reach one of the Target states,
+// polling its state with Refresh while it is in one of the Pending states.
+type waitConf struct {
+	// Description names what is being waited for in logs and errors, e.g.
+	// "storage volume data to be resized"
+	Description string
+	Pending     []string
+	Target      []string
+	Refresh     resource.StateRefreshFunc
+	Timeout     time.Duration
+
+	// The interval between polls starts at MinPollInterval, and doubles up to
+	// MaxPollInterval for as long as the state stays the same
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
+}
+
+// waitForState polls the state of a resource until it reaches a target state,
+// and returns the last result of Refresh. It fails when the state is neither
+// pending nor a target, when the timeout is reached, or as soon as ctx is
+// cancelled, e.g. when Terraform is stopped.
+func waitForState(ctx context.Context, conf waitConf) (interface{}, error) {
+	minInterval, maxInterval := conf.MinPollInterval, conf.MaxPollInterval
+	if minInterval == 0 {
+		minInterval = defaultWaitMinPollInterval
+	}
+	if maxInterval == 0 {
+		maxInterval = defaultWaitMaxPollInterval
+	}
+	if maxInterval < minInterval {
+		maxInterval = minInterval
+	}
+
+	if conf.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, conf.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	interval := minInterval
+	lastState := ""
+	for polls := 0; ; polls++ {
+		result, state, err := conf.Refresh()
+		if err != nil {
+			return result, err
+		}
+
+		if contains(conf.Target, state) {
+			log.Printf("[DEBUG] Finished waiting for %s: %s after %s", conf.Description, state, time.Since(start).Round(time.Second))
+			return result, nil
+		}
+		if !contains(conf.Pending, state) {
+			return result, fmt.Errorf("Unexpected state %q while waiting for %s, wanted %v", state, conf.Description, conf.Target)
+		}
+
+		// Poll quickly again once the state changes, as the next transition
+		// is often close behind
+		if polls > 0 {
+			if state != lastState {
+				interval = minInterval
+			} else if interval *= 2; interval > maxInterval {
+				interval = maxInterval
+			}
+		}
+		lastState = state
+
+		log.Printf("[DEBUG] Waiting for %s: %s after %s, polling again in %s", conf.Description, state, time.Since(start).Round(time.Second), interval)
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			if ctx.Err() == context.DeadlineExceeded {
+				return result, fmt.Errorf("Timeout after %s waiting for %s, last state: %s", conf.Timeout, conf.Description, lastState)
+			}
+			return result, fmt.Errorf("Cancelled waiting for %s, last state: %s", conf.Description, lastState)
+		}
+	}
+}
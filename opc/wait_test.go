@@ -0,0 +1,136 @@
+package opc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testWaitRefresh reports each of the states in turn, and then the last one.
+func testWaitRefresh(states ...string) (func() (interface{}, string, error), func() int) {
+	polls := 0
+	return func() (interface{}, string, error) {
+			state := states[len(states)-1]
+			if polls < len(states) {
+				state = states[polls]
+			}
+			polls++
+			return state, state, nil
+		}, func() int {
+			return polls
+		}
+}
+
+func TestWaitForState(t *testing.T) {
+	refresh, polls := testWaitRefresh("pending", "pending", "updating", "available")
+	result, err := waitForState(context.Background(), waitConf{
+		Description:     "test",
+		Pending:         []string{"pending", "updating"},
+		Target:          []string{"available"},
+		Refresh:         refresh,
+		MinPollInterval: time.Millisecond,
+		MaxPollInterval: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "available" || polls() != 4 {
+		t.Fatalf("Expected available after 4 polls, got %v after %d", result, polls())
+	}
+
+	refresh, _ = testWaitRefresh("pending", "error")
+	_, err = waitForState(context.Background(), waitConf{
+		Description:     "test",
+		Pending:         []string{"pending"},
+		Target:          []string{"available"},
+		Refresh:         refresh,
+		MinPollInterval: time.Millisecond,
+	})
+	if err == nil || !strings.Contains(err.Error(), `Unexpected state "error"`) {
+		t.Fatalf("Expected an unexpected state error, got %v", err)
+	}
+
+	_, err = waitForState(context.Background(), waitConf{
+		Description: "test",
+		Pending:     []string{"pending"},
+		Target:      []string{"available"},
+		Refresh: func() (interface{}, string, error) {
+			return nil, "", fmt.Errorf("refresh failed")
+		},
+	})
+	if err == nil || err.Error() != "refresh failed" {
+		t.Fatalf("Expected the refresh error, got %v", err)
+	}
+}
+
+func TestWaitForState_backoff(t *testing.T) {
+	var times []time.Time
+	refresh, _ := testWaitRefresh("pending", "pending", "pending", "pending", "updating", "updating", "available")
+	_, err := waitForState(context.Background(), waitConf{
+		Description: "test",
+		Pending:     []string{"pending", "updating"},
+		Target:      []string{"available"},
+		Refresh: func() (interface{}, string, error) {
+			times = append(times, time.Now())
+			return refresh()
+		},
+		MinPollInterval: 20 * time.Millisecond,
+		MaxPollInterval: 80 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The interval doubles while the state stays the same, up to the maximum,
+	// and starts again from the minimum when it changes
+	expected := []time.Duration{20, 40, 80, 80, 20, 40}
+	for i, want := range expected {
+		got := times[i+1].Sub(times[i])
+		if got < want*time.Millisecond || got > (want+50)*time.Millisecond {
+			t.Fatalf("Expected poll %d to be %dms after the last, got %s", i+2, want, got)
+		}
+	}
+}
+
+func TestWaitForState_timeout(t *testing.T) {
+	refresh, _ := testWaitRefresh("pending")
+	start := time.Now()
+	_, err := waitForState(context.Background(), waitConf{
+		Description:     "test volume to be resized",
+		Pending:         []string{"pending"},
+		Target:          []string{"available"},
+		Refresh:         refresh,
+		Timeout:         100 * time.Millisecond,
+		MinPollInterval: 10 * time.Millisecond,
+	})
+	if err == nil || err.Error() != "Timeout after 100ms waiting for test volume to be resized, last state: pending" {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the wait to time out after 100ms, took %s", elapsed)
+	}
+}
+
+func TestWaitForState_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	refresh, _ := testWaitRefresh("pending")
+	start := time.Now()
+	_, err := waitForState(ctx, waitConf{
+		Description:     "test",
+		Pending:         []string{"pending"},
+		Target:          []string{"available"},
+		Refresh:         refresh,
+		Timeout:         time.Hour,
+		MinPollInterval: time.Minute,
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "Cancelled waiting for test") {
+		t.Fatalf("Expected the wait to be cancelled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the wait to stop as soon as it was cancelled, took %s", elapsed)
+	}
+}